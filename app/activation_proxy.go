@@ -0,0 +1,276 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// proxyDialTimeout bounds connecting to the backend once the service is ready
+const proxyDialTimeout = 10 * time.Second
+
+// ActivationProxy keeps a service stopped until a client connects. ShutDB listens on the
+// service's public address, starts the service on the first connection and forwards the
+// connection to the alternate address the service itself is configured to listen on.
+type ActivationProxy struct {
+	Enabled        bool   `json:"enabled"`
+	ListenAddress  string `json:"listen_address"`  // Address clients connect to, e.g. "127.0.0.1:5432"
+	BackendAddress string `json:"backend_address"` // Address the service listens on, e.g. "127.0.0.1:15432"
+}
+
+// validateActivationProxies checks that every enabled proxy has valid addresses and that no
+// two proxies listen on the same address
+func validateActivationProxies(proxies map[string]ActivationProxy) error {
+	listeners := make(map[string]string)
+	for name, proxy := range proxies {
+		if !proxy.Enabled {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(proxy.ListenAddress); err != nil {
+			return fmt.Errorf("activation proxy for service %q: invalid listen address %q", name, proxy.ListenAddress)
+		}
+		if _, _, err := net.SplitHostPort(proxy.BackendAddress); err != nil {
+			return fmt.Errorf("activation proxy for service %q: invalid backend address %q", name, proxy.BackendAddress)
+		}
+		if proxy.ListenAddress == proxy.BackendAddress {
+			return fmt.Errorf("activation proxy for service %q cannot forward to its own address", name)
+		}
+		if other, exists := listeners[proxy.ListenAddress]; exists {
+			return fmt.Errorf("activation proxies for services %q and %q listen on the same address %s", other, name, proxy.ListenAddress)
+		}
+		listeners[proxy.ListenAddress] = name
+	}
+	return nil
+}
+
+// activation is a start of a proxied service that connections wait for
+type activation struct {
+	done chan struct{}
+	err  error
+}
+
+// activationProxyServer accepts connections on a proxied service's public address
+type activationProxyServer struct {
+	manager  *ServiceManager
+	name     string
+	proxy    ActivationProxy
+	listener net.Listener
+	mu       sync.Mutex
+	pending  *activation // The start in progress, shared by all connections that arrive meanwhile
+	serving  sync.WaitGroup
+}
+
+// activationProxies runs the activation proxies of a manager's services
+type activationProxies struct {
+	manager *ServiceManager
+	mu      sync.Mutex
+	servers map[string]*activationProxyServer
+}
+
+// newActivationProxies creates the proxy set of a manager
+func newActivationProxies(sm *ServiceManager) *activationProxies {
+	return &activationProxies{manager: sm, servers: make(map[string]*activationProxyServer)}
+}
+
+// Start listens on the public address of every enabled proxy until the context is cancelled.
+// A proxy whose address cannot be bound, e.g. because the service itself still listens on it,
+// is skipped with a warning.
+func (p *activationProxies) Start(ctx context.Context) {
+	if p.manager.configManager == nil {
+		return
+	}
+
+	proxies := p.manager.configManager.GetActivationProxies()
+	names := make([]string, 0, len(proxies))
+	for name := range proxies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, name := range names {
+		if _, running := p.servers[name]; running {
+			continue
+		}
+
+		listener, err := net.Listen("tcp", proxies[name].ListenAddress)
+		if err != nil {
+			log.Printf("Warning: activation proxy for %s could not listen: %v", name, err)
+			continue
+		}
+		server := &activationProxyServer{manager: p.manager, name: name, proxy: proxies[name], listener: listener}
+		p.servers[name] = server
+
+		server.serving.Add(1)
+		go server.serve(ctx)
+	}
+
+	go func() {
+		<-ctx.Done()
+		p.Close()
+	}()
+}
+
+// Close stops accepting connections and waits for the forwarded connections to end
+func (p *activationProxies) Close() {
+	p.mu.Lock()
+	servers := p.servers
+	p.servers = make(map[string]*activationProxyServer)
+	p.mu.Unlock()
+
+	for _, server := range servers {
+		server.listener.Close()
+	}
+	for _, server := range servers {
+		server.serving.Wait()
+	}
+}
+
+// address returns the address a service's proxy listens on, or "" if it is not running
+func (p *activationProxies) address(name string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if server, exists := p.servers[name]; exists {
+		return server.listener.Addr().String()
+	}
+	return ""
+}
+
+// serve accepts connections until the listener is closed
+func (s *activationProxyServer) serve(ctx context.Context) {
+	defer s.serving.Done()
+
+	for {
+		client, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Printf("Activation proxy for %s stopped accepting connections: %v", s.name, err)
+			}
+			return
+		}
+
+		s.serving.Add(1)
+		go s.forward(ctx, client)
+	}
+}
+
+// forward starts the service if needed and splices a client connection through to it
+func (s *activationProxyServer) forward(ctx context.Context, client net.Conn) {
+	defer s.serving.Done()
+	defer client.Close()
+
+	if err := s.activate(ctx); err != nil {
+		log.Printf("Activation proxy failed to start %s: %v", s.name, err)
+		return
+	}
+
+	dialer := net.Dialer{Timeout: proxyDialTimeout}
+	backend, err := dialer.DialContext(ctx, "tcp", s.proxy.BackendAddress)
+	if err != nil {
+		log.Printf("Activation proxy failed to connect to %s at %s: %v", s.name, s.proxy.BackendAddress, err)
+		return
+	}
+	defer backend.Close()
+
+	// Shutting down ends forwarded connections instead of waiting for clients to hang up
+	stop := context.AfterFunc(ctx, func() {
+		client.Close()
+		backend.Close()
+	})
+	defer stop()
+
+	splice(client, backend)
+}
+
+// activate returns once the service is running and accepts connections on its backend
+// address. Connections arriving while the service starts wait for the same start.
+func (s *activationProxyServer) activate(ctx context.Context) error {
+	if status, err := s.manager.adapter.GetServiceStatus(s.name); err == nil && status == StatusRunning {
+		return nil
+	}
+
+	s.mu.Lock()
+	pending := s.pending
+	if pending == nil {
+		pending = &activation{done: make(chan struct{})}
+		s.pending = pending
+		go s.start(ctx, pending)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return contextError(ctx, s.name)
+	case <-pending.done:
+		return pending.err
+	}
+}
+
+// start starts the service, or waits for a start made elsewhere, and then waits until the
+// service accepts connections on its backend address
+func (s *activationProxyServer) start(ctx context.Context, pending *activation) {
+	defer func() {
+		s.mu.Lock()
+		s.pending = nil
+		s.mu.Unlock()
+		close(pending.done)
+	}()
+
+	sm := s.manager
+	status, err := sm.adapter.GetServiceStatus(s.name)
+	if err != nil {
+		pending.err = err
+		return
+	}
+	switch status {
+	case StatusRunning:
+	case StatusStarting:
+		pending.err = sm.waitForStatus(ctx, s.name, StatusRunning)
+	default:
+		pending.err = sm.control(InitiatorProxy).StartService(s.name)
+	}
+	if pending.err != nil {
+		return
+	}
+
+	serviceType, _ := sm.serviceType(s.name)
+	timeout := defaultReadinessTimeout
+	if readiness, enabled := sm.configManager.GetReadinessConfig(serviceType); enabled {
+		timeout = readiness.timeout()
+	}
+	pending.err = waitUntilReady(ctx, s.name, serviceType, s.proxy.BackendAddress, timeout)
+}
+
+// splice copies data in both directions until both sides have finished sending
+func splice(client, backend net.Conn) {
+	var copying sync.WaitGroup
+	copying.Add(2)
+	go func() {
+		defer copying.Done()
+		io.Copy(backend, client)
+		closeWrite(backend)
+	}()
+	go func() {
+		defer copying.Done()
+		io.Copy(client, backend)
+		closeWrite(client)
+	}()
+	copying.Wait()
+}
+
+// closeWrite tells the peer that no more data follows, keeping the other direction open
+func closeWrite(conn net.Conn) {
+	if tcp, ok := conn.(interface{ CloseWrite() error }); ok {
+		tcp.CloseWrite()
+		return
+	}
+	conn.Close()
+}
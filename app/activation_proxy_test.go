@@ -0,0 +1,181 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startEchoBackend listens on a free local port and echoes whatever clients send, standing in
+// for the database behind an activation proxy
+func startEchoBackend(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start the backend: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// newProxyAdapter returns an adapter with a stopped SQL Server
+func newProxyAdapter() *fakeServiceAdapter {
+	return newFakeServiceAdapter(OSService{Name: "MSSQLSERVER", Status: StatusStopped})
+}
+
+// createProxyManager puts the SQL Server of adapter behind an activation proxy forwarding to
+// backend and returns the address the proxy listens on. The adapter must be set up before,
+// as connections are handled concurrently from then on.
+func createProxyManager(t *testing.T, adapter *fakeServiceAdapter, backend string) (*ServiceManager, string) {
+	sm := createTestServiceManager(t, adapter)
+	proxy := ActivationProxy{Enabled: true, ListenAddress: "127.0.0.1:0", BackendAddress: backend}
+	if err := sm.configManager.SetActivationProxy("MSSQLSERVER", proxy); err != nil {
+		t.Fatalf("SetActivationProxy() failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sm.proxies.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		sm.proxies.Close()
+	})
+
+	address := sm.proxies.address("MSSQLSERVER")
+	if address == "" {
+		t.Fatal("Expected the activation proxy to listen")
+	}
+	return sm, address
+}
+
+// roundTrip sends a message through the proxy and returns the reply
+func roundTrip(address, message string) (string, error) {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	if _, err := conn.Write([]byte(message)); err != nil {
+		return "", err
+	}
+	conn.(*net.TCPConn).CloseWrite()
+	reply, err := io.ReadAll(conn)
+	return string(reply), err
+}
+
+func TestActivationProxyStartsServiceOnConnect(t *testing.T) {
+	adapter := newProxyAdapter()
+	sm, address := createProxyManager(t, adapter, startEchoBackend(t))
+
+	reply, err := roundTrip(address, "SELECT 1")
+	if err != nil || reply != "SELECT 1" {
+		t.Fatalf("Expected the connection to be forwarded, got %q (%v)", reply, err)
+	}
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusRunning {
+		t.Errorf("Expected the service to be started, got %s", status)
+	}
+
+	// A running service is connected to directly
+	if reply, err := roundTrip(address, "SELECT 2"); err != nil || reply != "SELECT 2" {
+		t.Errorf("Expected the second connection to be forwarded, got %q (%v)", reply, err)
+	}
+	if calls := adapter.callLog(); strings.Join(calls, ",") != "start MSSQLSERVER" {
+		t.Errorf("Expected a single start, got %v", calls)
+	}
+
+	history, err := sm.GetHistory(AuditFilter{Initiator: InitiatorProxy})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Operation != "start" {
+		t.Errorf("Expected the start to be audited, got %+v", history)
+	}
+}
+
+func TestActivationProxySharesStart(t *testing.T) {
+	adapter := newProxyAdapter()
+	release := make(chan struct{})
+	adapter.pending["start MSSQLSERVER"] = release
+	_, address := createProxyManager(t, adapter, startEchoBackend(t))
+
+	var clients sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		clients.Add(1)
+		go func(message string) {
+			defer clients.Done()
+			if reply, err := roundTrip(address, message); err != nil || reply != message {
+				errs <- fmt.Errorf("expected %q, got %q (%v)", message, reply, err)
+			}
+		}(fmt.Sprintf("client %d", i))
+	}
+
+	close(release)
+	clients.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if calls := adapter.callLog(); strings.Join(calls, ",") != "start MSSQLSERVER" {
+		t.Errorf("Expected concurrent connections to share one start, got %v", calls)
+	}
+}
+
+func TestActivationProxyStartFailure(t *testing.T) {
+	adapter := newProxyAdapter()
+	adapter.failOn["start MSSQLSERVER"] = &ServiceError{Code: ErrSystemError, Message: "Out of memory"}
+	_, address := createProxyManager(t, adapter, startEchoBackend(t))
+
+	// The client is disconnected without reaching the backend
+	if reply, _ := roundTrip(address, "SELECT 1"); reply != "" {
+		t.Errorf("Expected no reply, got %q", reply)
+	}
+}
+
+func TestActivationProxyValidation(t *testing.T) {
+	configManager, _ := createTestConfigManager(t)
+
+	tests := []struct {
+		name    string
+		proxies map[string]ActivationProxy
+	}{
+		{"invalid listen address", map[string]ActivationProxy{
+			"postgresql-x64-16": {Enabled: true, ListenAddress: "5432", BackendAddress: "127.0.0.1:15432"},
+		}},
+		{"forwards to itself", map[string]ActivationProxy{
+			"postgresql-x64-16": {Enabled: true, ListenAddress: "127.0.0.1:5432", BackendAddress: "127.0.0.1:5432"},
+		}},
+		{"shared listen address", map[string]ActivationProxy{
+			"postgresql-x64-16": {Enabled: true, ListenAddress: "127.0.0.1:5432", BackendAddress: "127.0.0.1:15432"},
+			"postgresql-x64-17": {Enabled: true, ListenAddress: "127.0.0.1:5432", BackendAddress: "127.0.0.1:15433"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := configManager.GetConfig()
+			config.ActivationProxies = tt.proxies
+			if err := configManager.SaveConfig(config); err == nil {
+				t.Error("Expected the proxies to be rejected")
+			}
+		})
+	}
+}
@@ -0,0 +1,412 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultAPIPort is the localhost port of the HTTP API unless configured otherwise
+	DefaultAPIPort = 47821
+
+	// apiEventBuffer is how many status events a slow SSE client may fall behind before events are dropped
+	apiEventBuffer = 32
+	// apiKeepAliveInterval keeps idle event streams from being closed by proxies and clients
+	apiKeepAliveInterval = 30 * time.Second
+)
+
+// apiStatusCodes maps service error codes to HTTP status codes
+var apiStatusCodes = map[ErrorCode]int{
+	ErrPermissionDenied:  http.StatusForbidden,
+	ErrServiceNotFound:   http.StatusNotFound,
+	ErrOperationTimeout:  http.StatusGatewayTimeout,
+	ErrInvalidState:      http.StatusConflict,
+	ErrSystemError:       http.StatusInternalServerError,
+	ErrNotReady:          http.StatusServiceUnavailable,
+	ErrOperationCanceled: http.StatusConflict,
+}
+
+// apiError is the JSON body of failed API requests
+type apiError struct {
+	Error   string     `json:"error"`
+	Code    *ErrorCode `json:"code,omitempty"`
+	Service string     `json:"service,omitempty"`
+}
+
+// APIServer exposes ServiceManager operations over a localhost-only HTTP/JSON API.
+// Requests are authenticated with the bearer token stored next to config.json.
+type APIServer struct {
+	serviceManager *ServiceManager
+	configManager  *ConfigManager
+	server         *http.Server
+	cancel         context.CancelFunc
+	address        string
+	mu             sync.Mutex
+}
+
+// NewAPIServer creates an API server for the given managers
+func NewAPIServer(serviceManager *ServiceManager, configManager *ConfigManager) *APIServer {
+	return &APIServer{
+		serviceManager: serviceManager,
+		configManager:  configManager,
+	}
+}
+
+// OnStartup starts the API server if it is enabled in the configuration
+func (a *APIServer) OnStartup(ctx context.Context) error {
+	if !a.configManager.GetAPIEnabled() {
+		return nil
+	}
+	return a.start()
+}
+
+// OnShutdown stops the API server
+func (a *APIServer) OnShutdown(ctx context.Context) {
+	a.stop()
+}
+
+// IsRunning returns whether the API server is currently listening
+func (a *APIServer) IsRunning() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.server != nil
+}
+
+// GetAddress returns the base URL of the running API server, or an empty string
+func (a *APIServer) GetAddress() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.server == nil {
+		return ""
+	}
+	return "http://" + a.address
+}
+
+// SetEnabled enables or disables the API, persisting the setting and starting or stopping the server
+func (a *APIServer) SetEnabled(enabled bool) error {
+	if err := a.configManager.SetAPIEnabled(enabled); err != nil {
+		return err
+	}
+
+	if enabled {
+		return a.start()
+	}
+	a.stop()
+	return nil
+}
+
+// start listens on the configured localhost port
+func (a *APIServer) start() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.server != nil {
+		return nil
+	}
+
+	// Make sure clients can authenticate before accepting connections
+	if _, err := a.configManager.GetAPIToken(); err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(a.configManager.GetAPIPort())))
+	if err != nil {
+		return fmt.Errorf("failed to start API server: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.address = listener.Addr().String()
+	a.server = &http.Server{
+		Handler:           a.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Event streams are long-lived, so they end when the server is stopped
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	server := a.server
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("API server stopped: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// stop shuts the server down, ending open event streams
+func (a *APIServer) stop() {
+	a.mu.Lock()
+	server, cancel := a.server, a.cancel
+	a.server, a.cancel = nil, nil
+	a.mu.Unlock()
+
+	if server == nil {
+		return
+	}
+
+	cancel()
+	ctx, done := context.WithTimeout(context.Background(), 5*time.Second)
+	defer done()
+	server.Shutdown(ctx)
+}
+
+// handler returns the HTTP handler serving the API
+func (a *APIServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /services", a.handleListServices)
+	mux.HandleFunc("GET /services/{name}", a.handleGetService)
+	mux.HandleFunc("POST /services/{name}/{operation}", a.handleServiceOperation)
+	mux.HandleFunc("POST /services/{name}/force-stop", a.handleForceStop)
+	mux.HandleFunc("POST /services/{name}/startup-type", a.handleSetStartupType)
+	mux.HandleFunc("GET /groups", a.handleListGroups)
+	mux.HandleFunc("POST /groups/{name}/{operation}", a.handleGroupOperation)
+	mux.HandleFunc("GET /operations", a.handleListOperations)
+	mux.HandleFunc("POST /operations/{id}/cancel", a.handleCancelOperation)
+	mux.HandleFunc("GET /events", a.handleEvents)
+	mux.HandleFunc("GET /metrics", a.handleMetrics)
+	return a.authenticate(mux)
+}
+
+// authenticate rejects requests without the configured bearer token
+func (a *APIServer) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := a.configManager.GetAPIToken()
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		provided, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="shutdb"`)
+			writeAPIError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleListServices serves GET /services
+func (a *APIServer) handleListServices(w http.ResponseWriter, r *http.Request) {
+	services, err := a.serviceManager.GetServices()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, services)
+}
+
+// handleGetService serves GET /services/{name}
+func (a *APIServer) handleGetService(w http.ResponseWriter, r *http.Request) {
+	service, err := a.serviceManager.GetService(r.PathValue("name"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, service)
+}
+
+// handleServiceOperation serves POST /services/{name}/{start,stop,restart,pause,continue,enable,disable}
+func (a *APIServer) handleServiceOperation(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	// Operations are canceled when the client disconnects
+	control := a.serviceManager.control(InitiatorAPI).withContext(r.Context())
+	operations := map[string]func(string) error{
+		"start":    control.StartService,
+		"stop":     control.StopService,
+		"restart":  control.RestartService,
+		"pause":    control.PauseService,
+		"continue": control.ContinueService,
+		"enable":   control.EnableService,
+		"disable":  control.DisableService,
+	}
+	operation, exists := operations[r.PathValue("operation")]
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("unknown operation %q", r.PathValue("operation")))
+		return
+	}
+
+	if err := operation(name); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	// Respond with the service's state after the operation
+	service, err := a.serviceManager.GetService(name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, service)
+}
+
+// handleForceStop serves POST /services/{name}/force-stop?confirm=true
+func (a *APIServer) handleForceStop(w http.ResponseWriter, r *http.Request) {
+	confirmed := r.URL.Query().Get("confirm") == "true"
+	control := a.serviceManager.control(InitiatorAPI).withContext(r.Context())
+
+	result, err := control.ForceStopService(r.PathValue("name"), confirmed)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleSetStartupType serves POST /services/{name}/startup-type?type={automatic,automatic_delayed,manual,disabled}
+func (a *APIServer) handleSetStartupType(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	control := a.serviceManager.control(InitiatorAPI).withContext(r.Context())
+
+	if err := control.SetStartupType(name, StartupType(r.URL.Query().Get("type"))); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	service, err := a.serviceManager.GetService(name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, service)
+}
+
+// handleListGroups serves GET /groups
+func (a *APIServer) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.serviceManager.GetServiceGroups())
+}
+
+// handleGroupOperation serves POST /groups/{name}/{start,stop,restart}
+func (a *APIServer) handleGroupOperation(w http.ResponseWriter, r *http.Request) {
+	// Member operations are canceled when the client disconnects
+	control := a.serviceManager.control(InitiatorAPI).withContext(r.Context())
+	operations := map[string]func(string) (*GroupOperationResult, error){
+		"start":   control.StartGroup,
+		"stop":    control.StopGroup,
+		"restart": control.RestartGroup,
+	}
+	operation, exists := operations[r.PathValue("operation")]
+	if !exists {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("unknown operation %q", r.PathValue("operation")))
+		return
+	}
+
+	result, err := operation(r.PathValue("name"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleListOperations serves GET /operations
+func (a *APIServer) handleListOperations(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.serviceManager.GetOperations())
+}
+
+// handleCancelOperation serves POST /operations/{id}/cancel
+func (a *APIServer) handleCancelOperation(w http.ResponseWriter, r *http.Request) {
+	if err := a.serviceManager.CancelOperation(r.PathValue("id")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// handleEvents serves GET /events, a server-sent event stream of service status changes
+func (a *APIServer) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeAPIError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	// Register before responding so no event after the response headers is missed
+	events := make(chan ServiceStatusEvent, apiEventBuffer)
+	remove := a.serviceManager.addStatusListener(func(event ServiceStatusEvent) {
+		select {
+		case events <- event:
+		default:
+			// Drop events for clients that stopped reading
+		}
+	})
+	defer remove()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(apiKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case event := <-events:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ServiceStatusEventName, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// handleMetrics serves GET /metrics in the Prometheus text exposition format when metrics are enabled
+func (a *APIServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if !a.configManager.GetMetricsEnabled() {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("metrics are disabled"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	a.serviceManager.writeMetrics(w)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeAPIError writes an error response that is not tied to a service
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, apiError{Error: err.Error()})
+}
+
+// writeServiceError writes an error response with the HTTP status matching its ErrorCode
+func writeServiceError(w http.ResponseWriter, err error) {
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) {
+		writeAPIError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	status, exists := apiStatusCodes[serviceErr.Code]
+	if !exists {
+		status = http.StatusInternalServerError
+	}
+
+	code := serviceErr.Code
+	writeJSON(w, status, apiError{Error: serviceErr.Message, Code: &code, Service: serviceErr.Service})
+}
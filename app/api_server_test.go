@@ -0,0 +1,275 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func createTestAPIServer(t *testing.T) (*APIServer, *fakeServiceAdapter, *httptest.Server, string) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "postgresql-x64-16", Status: StatusRunning},
+		OSService{Name: "Redis", Status: StatusStopped},
+	)
+	sm := createTestServiceManager(t, adapter)
+	apiServer := NewAPIServer(sm, sm.configManager)
+
+	token, err := sm.configManager.GetAPIToken()
+	if err != nil {
+		t.Fatalf("GetAPIToken() failed: %v", err)
+	}
+
+	server := httptest.NewServer(apiServer.handler())
+	t.Cleanup(server.Close)
+	return apiServer, adapter, server, token
+}
+
+// apiRequest sends an authenticated request and decodes the JSON response into out
+func apiRequest(t *testing.T, server *httptest.Server, token, method, path string, out interface{}) int {
+	req, err := http.NewRequest(method, server.URL+path, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode response of %s %s: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAPIServerAuthentication(t *testing.T) {
+	_, _, server, token := createTestAPIServer(t)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "not-the-token", http.StatusUnauthorized},
+		{"valid token", token, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := apiRequest(t, server, tt.token, http.MethodGet, "/services", nil); status != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, status)
+			}
+		})
+	}
+}
+
+func TestAPIServerServices(t *testing.T) {
+	_, adapter, server, token := createTestAPIServer(t)
+
+	var services []Service
+	if status := apiRequest(t, server, token, http.MethodGet, "/services", &services); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 services, got %+v", services)
+	}
+
+	var service Service
+	if status := apiRequest(t, server, token, http.MethodPost, "/services/Redis/start", &service); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if service.Name != "Redis" || service.Status != StatusRunning {
+		t.Errorf("Expected Redis to be running, got %+v", service)
+	}
+	if calls := adapter.callLog(); len(calls) != 1 || calls[0] != "start Redis" {
+		t.Errorf("Expected a single start call, got %v", calls)
+	}
+
+	if status := apiRequest(t, server, token, http.MethodGet, "/services/Redis", &service); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if service.Type != TypeRedis {
+		t.Errorf("Expected type redis, got %s", service.Type)
+	}
+
+	if status := apiRequest(t, server, token, http.MethodPost, "/services/Redis/startup-type?type=automatic_delayed", &service); status != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+	if service.StartupType != StartupAutomaticDelayed {
+		t.Errorf("Expected Redis to start automatically with a delay, got %s", service.StartupType)
+	}
+}
+
+func TestAPIServerErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		setup    func(apiServer *APIServer)
+		expected int
+		code     *ErrorCode
+	}{
+		{"missing service", http.MethodGet, "/services/missing", nil, http.StatusNotFound, codePtr(ErrServiceNotFound)},
+		{"already running", http.MethodPost, "/services/postgresql-x64-16/start", nil, http.StatusConflict, codePtr(ErrInvalidState)},
+		{"unknown operation", http.MethodPost, "/services/Redis/explode", nil, http.StatusNotFound, nil},
+		{"unknown startup type", http.MethodPost, "/services/Redis/startup-type?type=boot", nil, http.StatusConflict, codePtr(ErrInvalidState)},
+		{"unconfirmed force stop", http.MethodPost, "/services/postgresql-x64-16/force-stop", nil, http.StatusConflict, codePtr(ErrInvalidState)},
+		{"missing group", http.MethodPost, "/groups/missing/start", nil, http.StatusNotFound, codePtr(ErrServiceNotFound)},
+		{"not elevated", http.MethodPost, "/services/Redis/start", func(apiServer *APIServer) {
+			apiServer.serviceManager.privilegeManager = &PrivilegeManager{isElevated: false, checked: true}
+		}, http.StatusForbidden, codePtr(ErrPermissionDenied)},
+		{"service control disabled", http.MethodGet, "/services", func(apiServer *APIServer) {
+			apiServer.configManager.SetServiceState(false)
+		}, http.StatusConflict, codePtr(ErrInvalidState)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiServer, _, server, token := createTestAPIServer(t)
+			if tt.setup != nil {
+				tt.setup(apiServer)
+			}
+
+			var body apiError
+			if status := apiRequest(t, server, token, tt.method, tt.path, &body); status != tt.expected {
+				t.Errorf("Expected status %d, got %d (%+v)", tt.expected, status, body)
+			}
+			if body.Error == "" {
+				t.Error("Expected an error message")
+			}
+			if (tt.code == nil) != (body.Code == nil) || (tt.code != nil && *tt.code != *body.Code) {
+				t.Errorf("Expected code %v, got %v", tt.code, body.Code)
+			}
+		})
+	}
+}
+
+func codePtr(code ErrorCode) *ErrorCode {
+	return &code
+}
+
+func TestAPIServerEvents(t *testing.T) {
+	apiServer, _, server, token := createTestAPIServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", contentType)
+	}
+
+	// The listener is registered before the response headers are sent
+	apiServer.serviceManager.handleStatusChange(ServiceStatusEvent{Name: "Redis", OldStatus: StatusStopped, NewStatus: StatusRunning})
+
+	reader := bufio.NewReader(resp.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	if lines[0] != "event: "+ServiceStatusEventName {
+		t.Errorf("Unexpected event line %q", lines[0])
+	}
+
+	var event ServiceStatusEvent
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &event); err != nil {
+		t.Fatalf("Failed to decode event %q: %v", lines[1], err)
+	}
+	if event.Name != "Redis" || event.NewStatus != StatusRunning {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
+
+func TestAPIServerEnable(t *testing.T) {
+	apiServer, _, _, token := createTestAPIServer(t)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to reserve a port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	if err := apiServer.configManager.SetAPIPort(port); err != nil {
+		t.Fatalf("SetAPIPort() failed: %v", err)
+	}
+	if err := apiServer.SetEnabled(true); err != nil {
+		t.Fatalf("SetEnabled(true) failed: %v", err)
+	}
+	t.Cleanup(func() { apiServer.SetEnabled(false) })
+
+	if !apiServer.configManager.GetAPIEnabled() {
+		t.Error("Enabling the API should be persisted")
+	}
+	if address := apiServer.GetAddress(); address != "http://127.0.0.1:"+strconv.Itoa(port) {
+		t.Errorf("Unexpected address %q", address)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, apiServer.GetAddress()+"/services", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request to running API failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	if err := apiServer.SetEnabled(false); err != nil {
+		t.Fatalf("SetEnabled(false) failed: %v", err)
+	}
+	if apiServer.IsRunning() {
+		t.Error("API server should be stopped")
+	}
+}
+
+func TestConfigManagerAPIToken(t *testing.T) {
+	cm, _ := createTestConfigManager(t)
+
+	token, err := cm.GetAPIToken()
+	if err != nil {
+		t.Fatalf("GetAPIToken() failed: %v", err)
+	}
+	if len(token) != 64 {
+		t.Errorf("Expected a 64 character token, got %q", token)
+	}
+
+	again, _ := cm.GetAPIToken()
+	if again != token {
+		t.Error("The token should be stable across calls")
+	}
+
+	regenerated, err := cm.RegenerateAPIToken()
+	if err != nil {
+		t.Fatalf("RegenerateAPIToken() failed: %v", err)
+	}
+	if regenerated == token {
+		t.Error("Regenerating should replace the token")
+	}
+}
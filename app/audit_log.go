@@ -0,0 +1,198 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// auditLogFileName is the audit log in the ShutDB config directory
+	auditLogFileName = "audit.log"
+	// auditLogMaxSize is the size at which the audit log is rotated
+	auditLogMaxSize = 5 * 1024 * 1024
+	// auditLogMaxBackups is how many rotated logs are kept as audit.log.1 (newest) to audit.log.N
+	auditLogMaxBackups = 3
+)
+
+// AuditEntry records a single control operation
+type AuditEntry struct {
+	Timestamp       time.Time     `json:"Timestamp"`
+	Operation       string        `json:"Operation"`
+	Service         string        `json:"Service,omitempty"`
+	Initiator       Initiator     `json:"Initiator"`
+	PriorStatus     ServiceStatus `json:"PriorStatus,omitempty"`
+	ResultingStatus ServiceStatus `json:"ResultingStatus,omitempty"`
+	DurationMs      int64         `json:"DurationMs"`
+	Success         bool          `json:"Success"`
+	ErrorCode       *ErrorCode    `json:"ErrorCode,omitempty"`
+	Error           string        `json:"Error,omitempty"`
+	OperationID     string        `json:"OperationID,omitempty"`
+}
+
+// AuditFilter selects audit entries; zero fields match everything
+type AuditFilter struct {
+	Service   string    `json:"Service"`
+	Operation string    `json:"Operation"`
+	Initiator Initiator `json:"Initiator"`
+	Since     time.Time `json:"Since"`
+	Until     time.Time `json:"Until"`
+	Limit     int       `json:"Limit"` // Maximum number of entries, most recent first
+}
+
+// matches reports whether an entry passes the filter
+func (f AuditFilter) matches(entry AuditEntry) bool {
+	if f.Service != "" && entry.Service != f.Service {
+		return false
+	}
+	if f.Operation != "" && entry.Operation != f.Operation {
+		return false
+	}
+	if f.Initiator != "" && entry.Initiator != f.Initiator {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Timestamp.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// AuditLog appends control operations to a rotating JSON-lines file
+type AuditLog struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	mu         sync.Mutex
+}
+
+// NewAuditLog creates an audit log in the given directory
+func NewAuditLog(dir string) *AuditLog {
+	return &AuditLog{
+		path:       filepath.Join(dir, auditLogFileName),
+		maxSize:    auditLogMaxSize,
+		maxBackups: auditLogMaxBackups,
+	}
+}
+
+// Path returns the path of the current audit log file
+func (l *AuditLog) Path() string {
+	return l.path
+}
+
+// Append writes an entry to the log, rotating it first if it would grow past its maximum size
+func (l *AuditLog) Append(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if info, err := os.Stat(l.path); err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts audit.log to audit.log.1, audit.log.1 to audit.log.2 and so on, dropping the oldest
+func (l *AuditLog) rotate() error {
+	os.Remove(l.backupPath(l.maxBackups))
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		os.Rename(l.backupPath(i), l.backupPath(i+1))
+	}
+	if err := os.Rename(l.path, l.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	return nil
+}
+
+// backupPath returns the path of the n-th rotated log
+func (l *AuditLog) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// GetHistory returns the entries matching the filter, most recent first
+func (l *AuditLog) GetHistory(filter AuditFilter) ([]AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Read the oldest backup first so entries end up in chronological order
+	paths := make([]string, 0, l.maxBackups+1)
+	for i := l.maxBackups; i >= 1; i-- {
+		paths = append(paths, l.backupPath(i))
+	}
+	paths = append(paths, l.path)
+
+	var entries []AuditEntry
+	for _, path := range paths {
+		fileEntries, err := readAuditFile(path, filter)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	// Most recent first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	if entries == nil {
+		entries = []AuditEntry{}
+	}
+	return entries, nil
+}
+
+// readAuditFile reads the matching entries of one log file, skipping lines that cannot be parsed
+func readAuditFile(path string, filter AuditFilter) ([]AuditEntry, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash can leave a partial last line behind
+			continue
+		}
+		if filter.matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, nil
+}
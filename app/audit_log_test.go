@@ -0,0 +1,204 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditLogAppendAndFilter(t *testing.T) {
+	auditLog := NewAuditLog(t.TempDir())
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	entries := []AuditEntry{
+		{Timestamp: base, Operation: "start", Service: "Redis", Initiator: InitiatorGUI, Success: true},
+		{Timestamp: base.Add(time.Minute), Operation: "stop", Service: "Redis", Initiator: InitiatorCLI, Success: true},
+		{Timestamp: base.Add(2 * time.Minute), Operation: "start", Service: "MySQL80", Initiator: InitiatorAPI, Success: false},
+		{Timestamp: base.Add(3 * time.Minute), Operation: "start", Service: "Redis", Initiator: InitiatorAPI, Success: true},
+	}
+	for _, entry := range entries {
+		if err := auditLog.Append(entry); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		filter   AuditFilter
+		expected []time.Time // Timestamps in the expected order
+	}{
+		{"all, most recent first", AuditFilter{}, []time.Time{base.Add(3 * time.Minute), base.Add(2 * time.Minute), base.Add(time.Minute), base}},
+		{"by service", AuditFilter{Service: "Redis"}, []time.Time{base.Add(3 * time.Minute), base.Add(time.Minute), base}},
+		{"by operation", AuditFilter{Operation: "stop"}, []time.Time{base.Add(time.Minute)}},
+		{"by initiator", AuditFilter{Initiator: InitiatorAPI}, []time.Time{base.Add(3 * time.Minute), base.Add(2 * time.Minute)}},
+		{"by time range", AuditFilter{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, []time.Time{base.Add(2 * time.Minute), base.Add(time.Minute)}},
+		{"limit", AuditFilter{Service: "Redis", Limit: 1}, []time.Time{base.Add(3 * time.Minute)}},
+		{"no match", AuditFilter{Service: "MongoDB"}, []time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history, err := auditLog.GetHistory(tt.filter)
+			if err != nil {
+				t.Fatalf("GetHistory() failed: %v", err)
+			}
+			if history == nil {
+				t.Fatal("Expected an empty slice, not nil")
+			}
+			if len(history) != len(tt.expected) {
+				t.Fatalf("Expected %d entries, got %d: %+v", len(tt.expected), len(history), history)
+			}
+			for i, entry := range history {
+				if !entry.Timestamp.Equal(tt.expected[i]) {
+					t.Errorf("Entry %d: expected timestamp %v, got %v", i, tt.expected[i], entry.Timestamp)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditLogRotation(t *testing.T) {
+	auditLog := NewAuditLog(t.TempDir())
+	auditLog.maxSize = 400
+	auditLog.maxBackups = 2
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 30; i++ {
+		entry := AuditEntry{Timestamp: base.Add(time.Duration(i) * time.Second), Operation: "start", Service: "Redis", Initiator: InitiatorCLI}
+		if err := auditLog.Append(entry); err != nil {
+			t.Fatalf("Append() failed: %v", err)
+		}
+	}
+
+	for _, path := range []string{auditLog.Path(), auditLog.backupPath(1), auditLog.backupPath(2)} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", path, err)
+		}
+		if info.Size() > auditLog.maxSize {
+			t.Errorf("%s is %d bytes, larger than the maximum of %d", path, info.Size(), auditLog.maxSize)
+		}
+	}
+	if _, err := os.Stat(auditLog.backupPath(3)); !os.IsNotExist(err) {
+		t.Errorf("Expected no more than 2 backups, found %s", auditLog.backupPath(3))
+	}
+
+	// Older entries were dropped with the oldest backup, the rest are still in order
+	history, err := auditLog.GetHistory(AuditFilter{})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) == 0 || len(history) >= 30 {
+		t.Fatalf("Expected some but not all entries to be kept, got %d", len(history))
+	}
+	if !history[0].Timestamp.Equal(base.Add(29 * time.Second)) {
+		t.Errorf("Expected the most recent entry first, got %v", history[0].Timestamp)
+	}
+	for i := 1; i < len(history); i++ {
+		if !history[i].Timestamp.Before(history[i-1].Timestamp) {
+			t.Fatalf("Entries are out of order at %d", i)
+		}
+	}
+}
+
+func TestAuditLogSkipsCorruptLines(t *testing.T) {
+	auditLog := NewAuditLog(t.TempDir())
+	if err := auditLog.Append(AuditEntry{Operation: "start", Service: "Redis", Initiator: InitiatorGUI}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+
+	file, err := os.OpenFile(auditLog.Path(), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	file.WriteString(`{"Operation":"stop","Serv`)
+	file.Close()
+
+	history, err := auditLog.GetHistory(AuditFilter{})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Operation != "start" {
+		t.Errorf("Expected only the intact entry, got %+v", history)
+	}
+}
+
+func TestServiceManagerRecordsOperations(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "Redis", Status: StatusStopped},
+		OSService{Name: "MySQL80", Status: StatusRunning},
+	)
+	sm := createTestServiceManager(t, adapter)
+
+	if err := sm.WaitForOperation(sm.StartService("Redis")); err != nil {
+		t.Fatalf("StartService() failed: %v", err)
+	}
+	if err := sm.control(InitiatorCLI).StartService("MySQL80"); err == nil {
+		t.Fatal("Expected starting a running service to fail")
+	}
+
+	history, err := sm.GetHistory(AuditFilter{})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 entries, got %+v", history)
+	}
+
+	failed, started := history[0], history[1]
+	if started.Operation != "start" || started.Service != "Redis" || started.Initiator != InitiatorGUI || !started.Success {
+		t.Errorf("Unexpected entry for the GUI start: %+v", started)
+	}
+	if started.PriorStatus != StatusStopped || started.ResultingStatus != StatusRunning {
+		t.Errorf("Expected stopped -> running, got %s -> %s", started.PriorStatus, started.ResultingStatus)
+	}
+	if started.Timestamp.IsZero() {
+		t.Error("Expected the entry to be timestamped")
+	}
+
+	if failed.Initiator != InitiatorCLI || failed.Success {
+		t.Errorf("Unexpected entry for the CLI start: %+v", failed)
+	}
+	if failed.ErrorCode == nil || *failed.ErrorCode != ErrInvalidState || !strings.Contains(failed.Error, "already running") {
+		t.Errorf("Expected an invalid state error to be recorded, got %+v", failed)
+	}
+}
+
+func TestServiceManagerRecordsServiceControlState(t *testing.T) {
+	sm := createTestServiceManager(t, newFakeServiceAdapter())
+
+	if err := sm.control(InitiatorTray).SetServiceControlState(false); err != nil {
+		t.Fatalf("SetServiceControlState() failed: %v", err)
+	}
+
+	history, err := sm.GetHistory(AuditFilter{Initiator: InitiatorTray})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Operation != "disable_control" || !history[0].Success {
+		t.Errorf("Expected a disable_control entry, got %+v", history)
+	}
+}
+
+func TestFrontEndsRecordTheirInitiator(t *testing.T) {
+	cli, _, _, _ := createTestCLI(t)
+	if code := cli.Run([]string{"start", "Redis"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+
+	apiServer, _, server, token := createTestAPIServer(t)
+	if status := apiRequest(t, server, token, "POST", "/services/Redis/start", nil); status != 200 {
+		t.Fatalf("Expected status 200, got %d", status)
+	}
+
+	for initiator, sm := range map[Initiator]*ServiceManager{InitiatorCLI: cli.manager, InitiatorAPI: apiServer.serviceManager} {
+		history, err := sm.GetHistory(AuditFilter{})
+		if err != nil {
+			t.Fatalf("GetHistory() failed: %v", err)
+		}
+		if len(history) != 1 || history[0].Initiator != initiator || history[0].Service != "Redis" {
+			t.Errorf("Expected one %s entry for Redis, got %+v", initiator, history)
+		}
+	}
+}
@@ -41,6 +41,24 @@ func (sc *ServiceCache) Set(name string, service *Service) {
 	sc.lastUpdate = time.Now()
 }
 
+// Update modifies a cached service in place without resetting the TTL
+// Returns true if the service was cached
+func (sc *ServiceCache) Update(name string, update func(service *Service)) bool {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	service, exists := sc.services[name]
+	if !exists {
+		return false
+	}
+
+	// Replace rather than mutate so services previously returned by Get stay unchanged
+	updated := *service
+	update(&updated)
+	sc.services[name] = &updated
+	return true
+}
+
 // SetAll replaces all cached services and updates the lastUpdate timestamp
 func (sc *ServiceCache) SetAll(services []Service) {
 	sc.mu.Lock()
@@ -0,0 +1,320 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// CLI exit codes. Service errors map to a distinct code per ErrorCode so scripts can react to them.
+const (
+	ExitOK               = 0
+	ExitFailure          = 1
+	ExitUsage            = 2
+	ExitPermissionDenied = 3
+	ExitServiceNotFound  = 4
+	ExitOperationTimeout = 5
+	ExitInvalidState     = 6
+	ExitSystemError      = 7
+	ExitNotReady         = 8
+	ExitCanceled         = 9
+)
+
+// exitCodes maps service error codes to CLI exit codes
+var exitCodes = map[ErrorCode]int{
+	ErrPermissionDenied:  ExitPermissionDenied,
+	ErrServiceNotFound:   ExitServiceNotFound,
+	ErrOperationTimeout:  ExitOperationTimeout,
+	ErrInvalidState:      ExitInvalidState,
+	ErrSystemError:       ExitSystemError,
+	ErrNotReady:          ExitNotReady,
+	ErrOperationCanceled: ExitCanceled,
+}
+
+const cliUsage = `Usage: shutdb <command> [arguments]
+
+Commands:
+  list [--type TYPE] [--category CATEGORY] [--json]   List detected database services
+  status <name>                                       Show the status of a service
+  start <name...>                                     Start one or more services
+  stop <name...>                                      Stop one or more services
+  restart <name...>                                   Restart one or more services
+  force-stop --yes <name>                             Stop a service, killing its processes if it hangs
+  pause <name...>                                     Pause one or more services that support it
+  continue <name...>                                  Continue one or more paused services
+  enable <name>                                       Enable a disabled service, restoring its startup type
+  disable <name>                                      Disable a service
+  startup <name> <type>                               Set the startup type: automatic, automatic_delayed, manual or disabled
+  ports <name>                                        Show listening ports and processes holding the service's port
+
+Exit codes:
+  0 success, 1 failure, 2 usage error, 3 permission denied, 4 service not found,
+  5 operation timeout, 6 invalid state, 7 system error, 8 service not ready,
+  9 operation canceled
+`
+
+// CLI runs ShutDB commands from the command line without the GUI
+type CLI struct {
+	manager *ServiceManager
+	control *ServiceControl
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// NewCLI creates a command-line front end for a service manager
+func NewCLI(manager *ServiceManager, stdout, stderr io.Writer) *CLI {
+	return &CLI{
+		manager: manager,
+		control: manager.control(InitiatorCLI),
+		stdout:  stdout,
+		stderr:  stderr,
+	}
+}
+
+// ExitCodeForError returns the CLI exit code for an error
+func ExitCodeForError(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		if code, exists := exitCodes[serviceErr.Code]; exists {
+			return code
+		}
+	}
+	return ExitFailure
+}
+
+// Run executes a command and returns the process exit code
+func (c *CLI) Run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, cliUsage)
+		return ExitUsage
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "list":
+		return c.list(args)
+	case "status":
+		return c.withName(command, args, c.status)
+	case "start":
+		return c.forEachName(command, args, "started", c.control.StartService)
+	case "stop":
+		return c.forEachName(command, args, "stopped", c.withDependents("stopping", c.control.StopService))
+	case "restart":
+		return c.forEachName(command, args, "restarted", c.withDependents("restarting", c.control.RestartService))
+	case "force-stop":
+		return c.forceStop(args)
+	case "pause":
+		return c.forEachName(command, args, "paused", c.control.PauseService)
+	case "continue":
+		return c.forEachName(command, args, "continued", c.control.ContinueService)
+	case "enable":
+		return c.withName(command, args, func(name string) int {
+			return c.report(name, "enabled", c.control.EnableService(name))
+		})
+	case "disable":
+		return c.withName(command, args, func(name string) int {
+			return c.report(name, "disabled", c.control.DisableService(name))
+		})
+	case "startup":
+		return c.startup(args)
+	case "ports":
+		return c.withName(command, args, c.ports)
+	case "help", "-h", "--help":
+		fmt.Fprint(c.stdout, cliUsage)
+		return ExitOK
+	default:
+		fmt.Fprintf(c.stderr, "shutdb: unknown command %q\n\n%s", command, cliUsage)
+		return ExitUsage
+	}
+}
+
+// list prints the detected services, optionally filtered by type or category
+func (c *CLI) list(args []string) int {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	serviceType := flags.String("type", "", "only list services of this type, e.g. postgresql")
+	category := flags.String("category", "", "only list services in this category, e.g. sql_databases")
+	asJSON := flags.Bool("json", false, "print services as JSON")
+
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(c.stderr, "shutdb list: unexpected argument %q\n", flags.Arg(0))
+		return ExitUsage
+	}
+
+	services, err := c.manager.GetServices()
+	if err != nil {
+		return c.fail(err)
+	}
+
+	filtered := make([]Service, 0, len(services))
+	for _, service := range services {
+		if *serviceType != "" && string(service.Type) != *serviceType {
+			continue
+		}
+		if *category != "" && string(service.Category) != *category {
+			continue
+		}
+		filtered = append(filtered, service)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(filtered); err != nil {
+			return c.fail(err)
+		}
+		return ExitOK
+	}
+
+	writer := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tSTATUS\tTYPE\tCATEGORY\tSTARTUP")
+	for _, service := range filtered {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", service.Name, service.Status, service.Type, service.Category, service.StartupType)
+	}
+	writer.Flush()
+
+	return ExitOK
+}
+
+// status prints the current status of a single service
+func (c *CLI) status(name string) int {
+	status, err := c.manager.GetServiceStatus(name)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	fmt.Fprintf(c.stdout, "%s: %s\n", name, status)
+	return ExitOK
+}
+
+// startup sets the startup type of a service
+func (c *CLI) startup(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprintln(c.stderr, "shutdb startup: expected a service name and a startup type")
+		return ExitUsage
+	}
+
+	name, startupType := args[0], StartupType(args[1])
+	return c.report(name, fmt.Sprintf("startup type set to %s", startupType), c.control.SetStartupType(name, startupType))
+}
+
+// forceStop stops a service and kills its processes if it is still stopping after its
+// operation timeout. Killing a database can lose data, so --yes is required.
+func (c *CLI) forceStop(args []string) int {
+	flags := flag.NewFlagSet("force-stop", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	confirmed := flags.Bool("yes", false, "confirm that the service's processes may be killed")
+
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+	if !*confirmed {
+		fmt.Fprintln(c.stderr, "shutdb force-stop: killing a service's processes can lose data, confirm with --yes")
+		return ExitUsage
+	}
+
+	return c.withName("force-stop", flags.Args(), func(name string) int {
+		result, err := c.control.ForceStopService(name, true)
+		if err != nil {
+			return c.fail(err)
+		}
+		if result.Forced {
+			fmt.Fprintf(c.stdout, "%s: did not stop within %ds, killed processes %v\n", name, result.GracePeriodSec, result.Terminated)
+		}
+		fmt.Fprintf(c.stdout, "%s: stopped\n", name)
+		return ExitOK
+	})
+}
+
+// ports prints the endpoints a service listens on and fails if another process holds its port
+func (c *CLI) ports(name string) int {
+	service, err := c.manager.GetService(name)
+	if err != nil {
+		return c.fail(err)
+	}
+	conflicts, err := c.manager.CheckPortConflicts(name)
+	if err != nil {
+		return c.fail(err)
+	}
+
+	if len(service.Ports) == 0 {
+		fmt.Fprintf(c.stdout, "%s: not listening\n", name)
+	} else {
+		writer := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "PROTOCOL\tADDRESS\tPID")
+		for _, endpoint := range service.Ports {
+			fmt.Fprintf(writer, "%s\t%s\t%d\n", endpoint.Protocol, net.JoinHostPort(endpoint.Address, strconv.Itoa(endpoint.Port)), endpoint.PID)
+		}
+		writer.Flush()
+	}
+
+	if len(conflicts) > 0 {
+		fmt.Fprintf(c.stderr, "shutdb: %s: %s\n", name, describePortConflicts(conflicts))
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// withName runs a command that takes exactly one service name
+func (c *CLI) withName(command string, args []string, run func(name string) int) int {
+	if len(args) != 1 {
+		fmt.Fprintf(c.stderr, "shutdb %s: expected exactly one service name\n", command)
+		return ExitUsage
+	}
+	return run(args[0])
+}
+
+// forEachName runs an operation for every named service, continuing after failures.
+// The exit code is that of the first failure.
+func (c *CLI) forEachName(command string, args []string, done string, op func(name string) error) int {
+	if len(args) == 0 {
+		fmt.Fprintf(c.stderr, "shutdb %s: expected at least one service name\n", command)
+		return ExitUsage
+	}
+
+	exitCode := ExitOK
+	for _, name := range args {
+		if code := c.report(name, done, op(name)); code != ExitOK && exitCode == ExitOK {
+			exitCode = code
+		}
+	}
+	return exitCode
+}
+
+// withDependents announces the running dependents that an operation stops before performing it
+func (c *CLI) withDependents(verb string, op func(name string) error) func(name string) error {
+	return func(name string) error {
+		if dependents, err := c.manager.GetDependentsToStop(name); err == nil && len(dependents) > 0 {
+			fmt.Fprintf(c.stdout, "%s: also %s dependent services %s\n", name, verb, strings.Join(dependents, ", "))
+		}
+		return op(name)
+	}
+}
+
+// report prints the outcome of an operation on a service
+func (c *CLI) report(name, done string, err error) int {
+	if err != nil {
+		return c.fail(err)
+	}
+	fmt.Fprintf(c.stdout, "%s: %s\n", name, done)
+	return ExitOK
+}
+
+// fail prints an error and returns its exit code
+func (c *CLI) fail(err error) int {
+	fmt.Fprintf(c.stderr, "shutdb: %v\n", err)
+	return ExitCodeForError(err)
+}
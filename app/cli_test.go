@@ -0,0 +1,214 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func createTestCLI(t *testing.T) (*CLI, *fakeServiceAdapter, *bytes.Buffer, *bytes.Buffer) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "postgresql-x64-16", DisplayName: "PostgreSQL Server 16", Status: StatusRunning},
+		OSService{Name: "MySQL80", DisplayName: "MySQL80", Status: StatusStopped},
+		OSService{Name: "Redis", DisplayName: "Redis", Status: StatusStopped},
+		OSService{Name: "Spooler", DisplayName: "Print Spooler", Status: StatusRunning},
+	)
+	sm := createTestServiceManager(t, adapter)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	return NewCLI(sm, stdout, stderr), adapter, stdout, stderr
+}
+
+func TestCLIList(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"all services", []string{"list"}, []string{"postgresql-x64-16", "MySQL80", "Redis"}},
+		{"by type", []string{"list", "--type", "redis"}, []string{"Redis"}},
+		{"by category", []string{"list", "--category", "sql_databases"}, []string{"postgresql-x64-16", "MySQL80"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, _, stdout, _ := createTestCLI(t)
+
+			if code := cli.Run(append(tt.args, "--json")); code != ExitOK {
+				t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+			}
+
+			var services []Service
+			if err := json.Unmarshal(stdout.Bytes(), &services); err != nil {
+				t.Fatalf("Failed to parse JSON output: %v\n%s", err, stdout.String())
+			}
+
+			var names []string
+			for _, service := range services {
+				names = append(names, service.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestCLIListTable(t *testing.T) {
+	cli, _, stdout, _ := createTestCLI(t)
+
+	if code := cli.Run([]string{"list"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d", ExitOK, code)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "NAME") {
+		t.Fatalf("Expected a header and 3 services, got:\n%s", stdout.String())
+	}
+	if fields := strings.Fields(lines[1]); fields[0] != "postgresql-x64-16" || fields[1] != "running" || fields[2] != "postgresql" {
+		t.Errorf("Unexpected row %q", lines[1])
+	}
+}
+
+func TestCLIStartMultiple(t *testing.T) {
+	cli, adapter, stdout, stderr := createTestCLI(t)
+
+	// The first failure determines the exit code, but every service is attempted
+	code := cli.Run([]string{"start", "MySQL80", "postgresql-x64-16", "Redis"})
+	if code != ExitInvalidState {
+		t.Errorf("Expected exit code %d, got %d", ExitInvalidState, code)
+	}
+
+	if !strings.Contains(stdout.String(), "MySQL80: started") || !strings.Contains(stdout.String(), "Redis: started") {
+		t.Errorf("Expected both stopped services to be started, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "already running") {
+		t.Errorf("Expected an error for the running service, got:\n%s", stderr.String())
+	}
+
+	expectedCalls := []string{"start MySQL80", "start Redis"}
+	if calls := adapter.callLog(); strings.Join(calls, ",") != strings.Join(expectedCalls, ",") {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+}
+
+func TestCLIPauseContinue(t *testing.T) {
+	cli, adapter, stdout, stderr := createTestCLI(t)
+	adapter.services["postgresql-x64-16"].CanPause = true
+
+	if code := cli.Run([]string{"pause", "postgresql-x64-16", "MySQL80"}); code != ExitInvalidState {
+		t.Errorf("Expected exit code %d, got %d", ExitInvalidState, code)
+	}
+	if !strings.Contains(stdout.String(), "postgresql-x64-16: paused") {
+		t.Errorf("Expected the running service to be paused, got:\n%s", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "MySQL80") {
+		t.Errorf("Expected an error for the stopped service, got:\n%s", stderr.String())
+	}
+
+	if code := cli.Run([]string{"continue", "postgresql-x64-16"}); code != ExitOK {
+		t.Errorf("Expected exit code %d, got %d: %s", ExitOK, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "postgresql-x64-16: continued") {
+		t.Errorf("Expected the paused service to be continued, got:\n%s", stdout.String())
+	}
+}
+
+func TestCLIExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		setup    func(cli *CLI, adapter *fakeServiceAdapter)
+		expected int
+	}{
+		{"no command", nil, nil, ExitUsage},
+		{"unknown command", []string{"frobnicate"}, nil, ExitUsage},
+		{"status without name", []string{"status"}, nil, ExitUsage},
+		{"stop without name", []string{"stop"}, nil, ExitUsage},
+		{"list with argument", []string{"list", "extra"}, nil, ExitUsage},
+		{"status", []string{"status", "Redis"}, nil, ExitOK},
+		{"status of missing service", []string{"status", "missing"}, nil, ExitServiceNotFound},
+		{"stop stopped service", []string{"stop", "Redis"}, nil, ExitInvalidState},
+		{"disable", []string{"disable", "Redis"}, nil, ExitOK},
+		{"startup", []string{"startup", "Redis", "automatic_delayed"}, nil, ExitOK},
+		{"startup without type", []string{"startup", "Redis"}, nil, ExitUsage},
+		{"startup with unknown type", []string{"startup", "Redis", "boot"}, nil, ExitInvalidState},
+		{"restart timeout", []string{"restart", "Redis"}, func(cli *CLI, adapter *fakeServiceAdapter) {
+			adapter.failOn["restart Redis"] = &ServiceError{Code: ErrOperationTimeout, Message: "Timed out", Service: "Redis"}
+		}, ExitOperationTimeout},
+		{"not elevated", []string{"enable", "Redis"}, func(cli *CLI, adapter *fakeServiceAdapter) {
+			cli.manager.privilegeManager = &PrivilegeManager{isElevated: false, checked: true}
+		}, ExitPermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cli, adapter, _, stderr := createTestCLI(t)
+			if tt.setup != nil {
+				tt.setup(cli, adapter)
+			}
+
+			if code := cli.Run(tt.args); code != tt.expected {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", tt.expected, code, stderr.String())
+			}
+		})
+	}
+}
+
+func TestCLIPorts(t *testing.T) {
+	cli, adapter, stdout, stderr := createTestCLI(t)
+	adapter.services["postgresql-x64-16"].PID = 100
+	cli.manager.detector.(*WindowsServiceDetector).ports = newFakePortScanner([]ListeningEndpoint{
+		{Protocol: ProtocolTCP, Address: "::", Port: 5432, PID: 200},
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 6379, PID: 300},
+	}, postgresProcessTree)
+	cli.manager.ports = cli.manager.detector.(*WindowsServiceDetector).ports
+
+	if code := cli.Run([]string{"ports", "postgresql-x64-16"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "tcp       [::]:5432  200") {
+		t.Errorf("Expected the listener in the output, got:\n%s", stdout.String())
+	}
+
+	if code := cli.Run([]string{"ports", "Redis"}); code != ExitFailure {
+		t.Fatalf("Expected exit code %d, got %d", ExitFailure, code)
+	}
+	if !strings.Contains(stderr.String(), "port 6379 is already in use by memurai.exe (PID 300)") {
+		t.Errorf("Expected the conflict on stderr, got %q", stderr.String())
+	}
+}
+
+func TestCLIStopAnnouncesDependents(t *testing.T) {
+	adapter := createTestDependencyAdapter()
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cli := NewCLI(createTestServiceManager(t, adapter), stdout, stderr)
+
+	if code := cli.Run([]string{"stop", "MSSQLSERVER"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr.String())
+	}
+
+	expected := "MSSQLSERVER: also stopping dependent services JobRunner, SQLSERVERAGENT\nMSSQLSERVER: stopped\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, stdout.String())
+	}
+}
+
+func TestCLIForceStop(t *testing.T) {
+	sm, _, _ := createForceStopManager(t)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cli := NewCLI(sm, stdout, stderr)
+
+	if code := cli.Run([]string{"force-stop", "postgresql-x64-16"}); code != ExitUsage {
+		t.Fatalf("Expected exit code %d without --yes, got %d", ExitUsage, code)
+	}
+
+	if code := cli.Run([]string{"force-stop", "--yes", "postgresql-x64-16"}); code != ExitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", ExitOK, code, stderr.String())
+	}
+	expected := "postgresql-x64-16: did not stop within 1s, killed processes [100 200 201]\npostgresql-x64-16: stopped\n"
+	if stdout.String() != expected {
+		t.Errorf("Expected output %q, got %q", expected, stdout.String())
+	}
+}
@@ -0,0 +1,208 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+const (
+	// BackendNative identifies the operating system's own service manager
+	BackendNative = "native"
+	// BackendDocker identifies containers managed by the Docker Engine
+	BackendDocker = "docker"
+
+	// backendSeparator separates the backend prefix from the service name
+	backendSeparator = ":"
+)
+
+// ServiceBackend is a named OSServiceAdapter registered with a CompositeServiceAdapter
+type ServiceBackend struct {
+	Name    string
+	Adapter OSServiceAdapter
+}
+
+// CompositeServiceAdapter merges several service backends into a single OSServiceAdapter.
+// Services from the primary backend keep their plain names so existing names stay valid;
+// services from every other backend are namespaced as "<backend>:<name>".
+type CompositeServiceAdapter struct {
+	primary  ServiceBackend
+	backends []ServiceBackend
+	byName   map[string]OSServiceAdapter
+}
+
+// NewCompositeServiceAdapter creates a composite adapter with a primary backend and optional secondary backends
+func NewCompositeServiceAdapter(primary ServiceBackend, secondary ...ServiceBackend) *CompositeServiceAdapter {
+	c := &CompositeServiceAdapter{
+		primary:  primary,
+		backends: append([]ServiceBackend{primary}, secondary...),
+		byName:   make(map[string]OSServiceAdapter, len(secondary)+1),
+	}
+
+	for _, backend := range c.backends {
+		c.byName[backend.Name] = backend.Adapter
+	}
+
+	return c
+}
+
+// Backends returns the names of all registered backends, primary first
+func (c *CompositeServiceAdapter) Backends() []string {
+	names := make([]string, 0, len(c.backends))
+	for _, backend := range c.backends {
+		names = append(names, backend.Name)
+	}
+	return names
+}
+
+// QualifiedName returns the namespaced name of a service owned by the given backend
+func (c *CompositeServiceAdapter) QualifiedName(backend, name string) string {
+	if backend == c.primary.Name {
+		return name
+	}
+	return backend + backendSeparator + name
+}
+
+// resolve splits a qualified name into the owning backend and its local service name
+func (c *CompositeServiceAdapter) resolve(qualifiedName string) (ServiceBackend, string) {
+	if prefix, name, found := strings.Cut(qualifiedName, backendSeparator); found && prefix != c.primary.Name {
+		if adapter, exists := c.byName[prefix]; exists {
+			return ServiceBackend{Name: prefix, Adapter: adapter}, name
+		}
+	}
+	return c.primary, qualifiedName
+}
+
+// BackendOf returns the name of the backend that owns a service
+func (c *CompositeServiceAdapter) BackendOf(qualifiedName string) string {
+	backend, _ := c.resolve(qualifiedName)
+	return backend.Name
+}
+
+// qualifyError rewrites the service name in backend errors to the qualified name
+func (c *CompositeServiceAdapter) qualifyError(err error, qualifiedName string) error {
+	if serviceErr, ok := err.(*ServiceError); ok && serviceErr.Service != "" && serviceErr.Service != qualifiedName {
+		qualified := *serviceErr
+		qualified.Service = qualifiedName
+		return &qualified
+	}
+	return err
+}
+
+// ListServices queries every backend concurrently and merges the results.
+// Errors from the primary backend are returned; secondary backends such as
+// Docker are optional, so their errors only drop their services from the list.
+func (c *CompositeServiceAdapter) ListServices() ([]OSService, error) {
+	results := make([][]OSService, len(c.backends))
+	errs := make([]error, len(c.backends))
+
+	var wg sync.WaitGroup
+	for i, backend := range c.backends {
+		wg.Add(1)
+		go func(i int, backend ServiceBackend) {
+			defer wg.Done()
+			results[i], errs[i] = backend.Adapter.ListServices()
+		}(i, backend)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	total := 0
+	for i := range results {
+		total += len(results[i])
+	}
+
+	services := make([]OSService, 0, total)
+	for i, backend := range c.backends {
+		if errs[i] != nil {
+			continue
+		}
+		for _, service := range results[i] {
+			service.Name = c.QualifiedName(backend.Name, service.Name)
+			service.Backend = backend.Name
+			services = append(services, service)
+		}
+	}
+
+	return services, nil
+}
+
+// GetServiceStatus routes the status query to the owning backend
+func (c *CompositeServiceAdapter) GetServiceStatus(name string) (ServiceStatus, error) {
+	backend, local := c.resolve(name)
+	status, err := backend.Adapter.GetServiceStatus(local)
+	return status, c.qualifyError(err, name)
+}
+
+// GetStartupType routes the startup type query to the owning backend
+func (c *CompositeServiceAdapter) GetStartupType(name string) (StartupType, error) {
+	backend, local := c.resolve(name)
+	startupType, err := backend.Adapter.GetStartupType(local)
+	return startupType, c.qualifyError(err, name)
+}
+
+// StartService routes the start operation to the owning backend
+func (c *CompositeServiceAdapter) StartService(ctx context.Context, name string) error {
+	backend, local := c.resolve(name)
+	return c.qualifyError(backend.Adapter.StartService(ctx, local), name)
+}
+
+// StopService routes the stop operation to the owning backend
+func (c *CompositeServiceAdapter) StopService(ctx context.Context, name string) error {
+	backend, local := c.resolve(name)
+	return c.qualifyError(backend.Adapter.StopService(ctx, local), name)
+}
+
+// RestartService routes the restart operation to the owning backend
+func (c *CompositeServiceAdapter) RestartService(ctx context.Context, name string) error {
+	backend, local := c.resolve(name)
+	return c.qualifyError(backend.Adapter.RestartService(ctx, local), name)
+}
+
+// PauseService routes the pause operation to the owning backend
+func (c *CompositeServiceAdapter) PauseService(ctx context.Context, name string) error {
+	backend, local := c.resolve(name)
+	return c.qualifyError(backend.Adapter.PauseService(ctx, local), name)
+}
+
+// ContinueService routes the continue operation to the owning backend
+func (c *CompositeServiceAdapter) ContinueService(ctx context.Context, name string) error {
+	backend, local := c.resolve(name)
+	return c.qualifyError(backend.Adapter.ContinueService(ctx, local), name)
+}
+
+// SetStartupType routes the startup type change to the owning backend
+func (c *CompositeServiceAdapter) SetStartupType(name string, startupType StartupType) error {
+	backend, local := c.resolve(name)
+	return c.qualifyError(backend.Adapter.SetStartupType(local, startupType), name)
+}
+
+// GetDependencies routes the dependency query to the owning backend.
+// Dependencies never cross backends, so the names are qualified with the same backend.
+func (c *CompositeServiceAdapter) GetDependencies(name string) ([]string, error) {
+	backend, local := c.resolve(name)
+	dependencies, err := backend.Adapter.GetDependencies(local)
+	return c.qualifyNames(backend, dependencies), c.qualifyError(err, name)
+}
+
+// GetDependents routes the dependents query to the owning backend
+func (c *CompositeServiceAdapter) GetDependents(name string) ([]string, error) {
+	backend, local := c.resolve(name)
+	dependents, err := backend.Adapter.GetDependents(local)
+	return c.qualifyNames(backend, dependents), c.qualifyError(err, name)
+}
+
+// qualifyNames qualifies the local service names of a backend
+func (c *CompositeServiceAdapter) qualifyNames(backend ServiceBackend, names []string) []string {
+	if names == nil {
+		return nil
+	}
+	qualified := make([]string, len(names))
+	for i, name := range names {
+		qualified[i] = c.QualifiedName(backend.Name, name)
+	}
+	return qualified
+}
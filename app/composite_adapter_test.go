@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func createTestCompositeAdapter() (*CompositeServiceAdapter, *fakeServiceAdapter, *fakeServiceAdapter) {
+	native := newFakeServiceAdapter(
+		OSService{Name: "Redis", DisplayName: "Redis", Status: StatusStopped},
+		OSService{Name: "postgresql-x64-16", DisplayName: "PostgreSQL 16", Status: StatusRunning},
+	)
+	containers := newFakeServiceAdapter(
+		OSService{Name: "Redis", DisplayName: "Redis (redis:7)", Status: StatusRunning, Image: "redis:7"},
+	)
+
+	composite := NewCompositeServiceAdapter(
+		ServiceBackend{Name: BackendNative, Adapter: native},
+		ServiceBackend{Name: BackendDocker, Adapter: containers},
+	)
+	return composite, native, containers
+}
+
+func TestCompositeAdapterListServices(t *testing.T) {
+	composite, _, _ := createTestCompositeAdapter()
+
+	services, err := composite.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"Redis":             BackendNative,
+		"postgresql-x64-16": BackendNative,
+		"docker:Redis":      BackendDocker,
+	}
+
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d services, got %d", len(expected), len(services))
+	}
+
+	for _, service := range services {
+		backend, exists := expected[service.Name]
+		if !exists {
+			t.Errorf("Unexpected service name %s", service.Name)
+			continue
+		}
+		if service.Backend != backend {
+			t.Errorf("Service %s: expected backend %s, got %s", service.Name, backend, service.Backend)
+		}
+	}
+}
+
+func TestCompositeAdapterRouting(t *testing.T) {
+	composite, native, containers := createTestCompositeAdapter()
+
+	if err := composite.StartService(context.Background(), "Redis"); err != nil {
+		t.Fatalf("StartService(Redis) failed: %v", err)
+	}
+	if err := composite.StopService(context.Background(), "docker:Redis"); err != nil {
+		t.Fatalf("StopService(docker:Redis) failed: %v", err)
+	}
+
+	if calls := native.callLog(); len(calls) != 1 || calls[0] != "start Redis" {
+		t.Errorf("Expected native backend to receive 'start Redis', got %v", calls)
+	}
+	if calls := containers.callLog(); len(calls) != 1 || calls[0] != "stop Redis" {
+		t.Errorf("Expected docker backend to receive 'stop Redis', got %v", calls)
+	}
+
+	if backend := composite.BackendOf("docker:Redis"); backend != BackendDocker {
+		t.Errorf("Expected docker:Redis to belong to %s, got %s", BackendDocker, backend)
+	}
+
+	// Unknown prefixes belong to the primary backend
+	if backend := composite.BackendOf("remote:Redis"); backend != BackendNative {
+		t.Errorf("Expected remote:Redis to belong to %s, got %s", BackendNative, backend)
+	}
+}
+
+func TestCompositeAdapterQualifiesErrors(t *testing.T) {
+	composite, _, _ := createTestCompositeAdapter()
+
+	err := composite.StartService(context.Background(), "docker:missing")
+	serviceErr, ok := err.(*ServiceError)
+	if !ok {
+		t.Fatalf("Expected *ServiceError, got %T (%v)", err, err)
+	}
+	if serviceErr.Code != ErrServiceNotFound {
+		t.Errorf("Expected ErrServiceNotFound, got %d", serviceErr.Code)
+	}
+	if serviceErr.Service != "docker:missing" {
+		t.Errorf("Expected error to name docker:missing, got %s", serviceErr.Service)
+	}
+}
+
+func TestCompositeAdapterBackendFailures(t *testing.T) {
+	composite, native, containers := createTestCompositeAdapter()
+
+	// A failing secondary backend only drops its own services
+	containers.listErr = errors.New("docker is not running")
+	services, err := composite.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() should tolerate secondary backend failures: %v", err)
+	}
+	if len(services) != 2 {
+		t.Errorf("Expected 2 native services, got %d", len(services))
+	}
+
+	// A failing primary backend fails the whole listing
+	native.listErr = &ServiceError{Code: ErrPermissionDenied, Message: "access denied"}
+	if _, err := composite.ListServices(); err == nil {
+		t.Error("ListServices() should fail when the primary backend fails")
+	}
+}
+
+func TestCompositeAdapterQualifiesDependencies(t *testing.T) {
+	composite, native, containers := createTestCompositeAdapter()
+	native.requires["Redis"] = []string{"postgresql-x64-16"}
+	containers.services["Sentinel"] = &OSService{Name: "Sentinel", Status: StatusRunning}
+	containers.order = append(containers.order, "Sentinel")
+	containers.requires["Sentinel"] = []string{"Redis"}
+
+	if dependencies, err := composite.GetDependencies("Redis"); err != nil || len(dependencies) != 1 || dependencies[0] != "postgresql-x64-16" {
+		t.Errorf("Expected native dependencies to stay unqualified, got %v, %v", dependencies, err)
+	}
+	if dependents, err := composite.GetDependents("docker:Redis"); err != nil || len(dependents) != 1 || dependents[0] != "docker:Sentinel" {
+		t.Errorf("Expected docker dependents to be qualified, got %v, %v", dependents, err)
+	}
+}
@@ -1,11 +1,14 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // AppConfig represents the persistent application configuration
@@ -15,6 +18,17 @@ type AppConfig struct {
 	MinimizeToTray   bool   `json:"minimize_to_tray"`
 	StartMinimized   bool   `json:"start_minimized"`
 	TrayNotifications bool  `json:"tray_notifications"`
+	ServiceGroups    []ServiceGroup `json:"service_groups,omitempty"`
+	Readiness        map[ServiceType]ReadinessConfig `json:"readiness,omitempty"`
+	OperationTimeouts OperationTimeoutConfig `json:"operation_timeouts"`
+	PreviousStartupTypes map[string]StartupType `json:"previous_startup_types,omitempty"` // Startup types of disabled services, restored when they are enabled
+	Watchdog         map[string]WatchdogPolicy `json:"watchdog,omitempty"` // Crash restart policies by service name
+	IdleStop         map[string]IdlePolicy `json:"idle_stop,omitempty"` // Idle auto-stop policies by service name
+	ActivationProxies map[string]ActivationProxy `json:"activation_proxies,omitempty"` // On-demand start proxies by service name
+	Schedules        []ScheduleRule `json:"schedules,omitempty"` // Timed start, stop, restart, enable and disable rules
+	APIEnabled       bool   `json:"api_enabled"`
+	APIPort          int    `json:"api_port"`
+	MetricsEnabled   bool   `json:"metrics_enabled"`
 }
 
 // DefaultConfig returns the default configuration values
@@ -25,6 +39,8 @@ func DefaultConfig() *AppConfig {
 		MinimizeToTray:    true,
 		StartMinimized:    false,
 		TrayNotifications: true,
+		APIEnabled:        false,
+		APIPort:           DefaultAPIPort,
 	}
 }
 
@@ -141,6 +157,41 @@ func (cm *ConfigManager) GetConfig() *AppConfig {
 	
 	// Return a copy to prevent external modification
 	configCopy := *cm.config
+	configCopy.ServiceGroups = copyServiceGroups(cm.config.ServiceGroups)
+	if cm.config.Readiness != nil {
+		configCopy.Readiness = make(map[ServiceType]ReadinessConfig, len(cm.config.Readiness))
+		for serviceType, readiness := range cm.config.Readiness {
+			configCopy.Readiness[serviceType] = readiness
+		}
+	}
+	configCopy.OperationTimeouts = cm.config.OperationTimeouts.copy()
+	if cm.config.PreviousStartupTypes != nil {
+		configCopy.PreviousStartupTypes = make(map[string]StartupType, len(cm.config.PreviousStartupTypes))
+		for name, startupType := range cm.config.PreviousStartupTypes {
+			configCopy.PreviousStartupTypes[name] = startupType
+		}
+	}
+	if cm.config.Watchdog != nil {
+		configCopy.Watchdog = make(map[string]WatchdogPolicy, len(cm.config.Watchdog))
+		for name, policy := range cm.config.Watchdog {
+			configCopy.Watchdog[name] = policy
+		}
+	}
+	if cm.config.IdleStop != nil {
+		configCopy.IdleStop = make(map[string]IdlePolicy, len(cm.config.IdleStop))
+		for name, policy := range cm.config.IdleStop {
+			// Exclude lists are shared otherwise
+			policy.ExcludeClients = append([]string(nil), policy.ExcludeClients...)
+			configCopy.IdleStop[name] = policy
+		}
+	}
+	if cm.config.ActivationProxies != nil {
+		configCopy.ActivationProxies = make(map[string]ActivationProxy, len(cm.config.ActivationProxies))
+		for name, proxy := range cm.config.ActivationProxies {
+			configCopy.ActivationProxies[name] = proxy
+		}
+	}
+	configCopy.Schedules = append([]ScheduleRule(nil), cm.config.Schedules...)
 	return &configCopy
 }
 
@@ -238,6 +289,344 @@ func (cm *ConfigManager) SetTrayNotifications(enabled bool) error {
 	return cm.SaveConfig(cm.config)
 }
 
+// GetServiceGroups returns the configured service groups
+func (cm *ConfigManager) GetServiceGroups() []ServiceGroup {
+	if cm.config == nil {
+		return []ServiceGroup{}
+	}
+	return copyServiceGroups(cm.config.ServiceGroups)
+}
+
+// GetServiceGroup returns the service group with the given name
+func (cm *ConfigManager) GetServiceGroup(name string) (ServiceGroup, bool) {
+	for _, group := range cm.GetServiceGroups() {
+		if group.Name == name {
+			return group, true
+		}
+	}
+	return ServiceGroup{}, false
+}
+
+// SetServiceGroup adds a service group, or replaces the group with the same name, and persists it
+func (cm *ConfigManager) SetServiceGroup(group ServiceGroup) error {
+	config := cm.GetConfig()
+
+	replaced := false
+	for i := range config.ServiceGroups {
+		if config.ServiceGroups[i].Name == group.Name {
+			config.ServiceGroups[i] = group
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		config.ServiceGroups = append(config.ServiceGroups, group)
+	}
+
+	return cm.SaveConfig(config)
+}
+
+// DeleteServiceGroup removes a service group and persists the change
+func (cm *ConfigManager) DeleteServiceGroup(name string) error {
+	config := cm.GetConfig()
+
+	groups := config.ServiceGroups[:0]
+	for _, group := range config.ServiceGroups {
+		if group.Name != name {
+			groups = append(groups, group)
+		}
+	}
+	if len(groups) == len(config.ServiceGroups) {
+		return fmt.Errorf("service group %q does not exist", name)
+	}
+
+	config.ServiceGroups = groups
+	return cm.SaveConfig(config)
+}
+
+// GetAPIEnabled returns whether the local HTTP API is enabled
+func (cm *ConfigManager) GetAPIEnabled() bool {
+	if cm.config == nil {
+		return false
+	}
+	return cm.config.APIEnabled
+}
+
+// SetAPIEnabled updates the local HTTP API setting and persists it
+func (cm *ConfigManager) SetAPIEnabled(enabled bool) error {
+	if cm.config == nil {
+		cm.config = DefaultConfig()
+	}
+
+	cm.config.APIEnabled = enabled
+	return cm.SaveConfig(cm.config)
+}
+
+// GetAPIPort returns the localhost port the HTTP API listens on
+func (cm *ConfigManager) GetAPIPort() int {
+	if cm.config == nil || cm.config.APIPort == 0 {
+		return DefaultAPIPort
+	}
+	return cm.config.APIPort
+}
+
+// SetAPIPort updates the HTTP API port and persists it
+func (cm *ConfigManager) SetAPIPort(port int) error {
+	if cm.config == nil {
+		cm.config = DefaultConfig()
+	}
+
+	cm.config.APIPort = port
+	return cm.SaveConfig(cm.config)
+}
+
+// GetMetricsEnabled returns whether the API serves Prometheus metrics
+func (cm *ConfigManager) GetMetricsEnabled() bool {
+	if cm.config == nil {
+		return false
+	}
+	return cm.config.MetricsEnabled
+}
+
+// SetMetricsEnabled updates the metrics endpoint setting and persists it
+func (cm *ConfigManager) SetMetricsEnabled(enabled bool) error {
+	if cm.config == nil {
+		cm.config = DefaultConfig()
+	}
+
+	cm.config.MetricsEnabled = enabled
+	return cm.SaveConfig(cm.config)
+}
+
+// GetDetectionRulesPath returns the path of the user-defined detection rules file
+func (cm *ConfigManager) GetDetectionRulesPath() string {
+	return filepath.Join(filepath.Dir(cm.configPath), detectionRulesFileName)
+}
+
+// GetAPITokenPath returns the path of the file holding the HTTP API bearer token
+func (cm *ConfigManager) GetAPITokenPath() string {
+	return filepath.Join(filepath.Dir(cm.configPath), "api_token")
+}
+
+// GetAPIToken returns the HTTP API bearer token, generating one on first use
+func (cm *ConfigManager) GetAPIToken() (string, error) {
+	data, err := os.ReadFile(cm.GetAPITokenPath())
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read API token: %w", err)
+	}
+
+	return cm.RegenerateAPIToken()
+}
+
+// RegenerateAPIToken replaces the HTTP API bearer token, invalidating the previous one
+func (cm *ConfigManager) RegenerateAPIToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := hex.EncodeToString(secret)
+
+	// Only the current user may read the token
+	tokenPath := cm.GetAPITokenPath()
+	tempPath := tokenPath + ".tmp"
+	if err := os.WriteFile(tempPath, []byte(token+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write API token: %w", err)
+	}
+	if err := os.Rename(tempPath, tokenPath); err != nil {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("failed to save API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetReadinessConfig returns the readiness probe configured for a service type and whether it is enabled
+func (cm *ConfigManager) GetReadinessConfig(serviceType ServiceType) (ReadinessConfig, bool) {
+	if cm.config == nil {
+		return ReadinessConfig{}, false
+	}
+	readiness, exists := cm.config.Readiness[serviceType]
+	return readiness, exists && readiness.Enabled
+}
+
+// SetReadinessConfig updates the readiness probe for a service type and persists it
+func (cm *ConfigManager) SetReadinessConfig(serviceType ServiceType, readiness ReadinessConfig) error {
+	config := cm.GetConfig()
+	if config.Readiness == nil {
+		config.Readiness = make(map[ServiceType]ReadinessConfig)
+	}
+
+	config.Readiness[serviceType] = readiness
+	return cm.SaveConfig(config)
+}
+
+// GetOperationTimeout returns how long start, stop and restart operations wait for a service
+func (cm *ConfigManager) GetOperationTimeout(name string, serviceType ServiceType) time.Duration {
+	if cm.config == nil {
+		return defaultOperationTimeout
+	}
+	return cm.config.OperationTimeouts.timeout(name, serviceType)
+}
+
+// SetOperationTimeouts updates the operation timeouts and persists them
+func (cm *ConfigManager) SetOperationTimeouts(timeouts OperationTimeoutConfig) error {
+	config := cm.GetConfig()
+	config.OperationTimeouts = timeouts.copy()
+	return cm.SaveConfig(config)
+}
+
+// GetPreviousStartupType returns the startup type a disabled service had before it was disabled
+func (cm *ConfigManager) GetPreviousStartupType(name string) (StartupType, bool) {
+	if cm.config == nil {
+		return "", false
+	}
+	startupType, exists := cm.config.PreviousStartupTypes[name]
+	return startupType, exists
+}
+
+// SetPreviousStartupType remembers the startup type of a service that is being disabled and persists it
+func (cm *ConfigManager) SetPreviousStartupType(name string, startupType StartupType) error {
+	config := cm.GetConfig()
+	if config.PreviousStartupTypes == nil {
+		config.PreviousStartupTypes = make(map[string]StartupType)
+	}
+
+	config.PreviousStartupTypes[name] = startupType
+	return cm.SaveConfig(config)
+}
+
+// DeletePreviousStartupType forgets the remembered startup type of a service
+func (cm *ConfigManager) DeletePreviousStartupType(name string) error {
+	if _, exists := cm.GetPreviousStartupType(name); !exists {
+		return nil
+	}
+
+	config := cm.GetConfig()
+	delete(config.PreviousStartupTypes, name)
+	return cm.SaveConfig(config)
+}
+
+// GetWatchdogPolicy returns the crash restart policy of a service and whether it is kept alive
+func (cm *ConfigManager) GetWatchdogPolicy(name string) (WatchdogPolicy, bool) {
+	if cm.config == nil {
+		return WatchdogPolicy{}, false
+	}
+	policy, exists := cm.config.Watchdog[name]
+	return policy, exists && policy.KeepAlive
+}
+
+// SetWatchdogPolicy updates the crash restart policy of a service and persists it
+func (cm *ConfigManager) SetWatchdogPolicy(name string, policy WatchdogPolicy) error {
+	config := cm.GetConfig()
+	if config.Watchdog == nil {
+		config.Watchdog = make(map[string]WatchdogPolicy)
+	}
+
+	config.Watchdog[name] = policy
+	return cm.SaveConfig(config)
+}
+
+// GetSchedules returns the configured schedule rules
+func (cm *ConfigManager) GetSchedules() []ScheduleRule {
+	if cm.config == nil {
+		return []ScheduleRule{}
+	}
+	return append([]ScheduleRule{}, cm.config.Schedules...)
+}
+
+// GetSchedule returns the schedule rule with the given name
+func (cm *ConfigManager) GetSchedule(name string) (ScheduleRule, bool) {
+	for _, rule := range cm.GetSchedules() {
+		if rule.Name == name {
+			return rule, true
+		}
+	}
+	return ScheduleRule{}, false
+}
+
+// SetSchedule adds a schedule rule, or replaces the rule with the same name, and persists it
+func (cm *ConfigManager) SetSchedule(rule ScheduleRule) error {
+	config := cm.GetConfig()
+
+	replaced := false
+	for i := range config.Schedules {
+		if config.Schedules[i].Name == rule.Name {
+			config.Schedules[i] = rule
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		config.Schedules = append(config.Schedules, rule)
+	}
+
+	return cm.SaveConfig(config)
+}
+
+// GetIdlePolicies returns the idle auto-stop policies of the services that have it turned on
+func (cm *ConfigManager) GetIdlePolicies() map[string]IdlePolicy {
+	policies := make(map[string]IdlePolicy)
+	if cm.config == nil {
+		return policies
+	}
+	for name, policy := range cm.config.IdleStop {
+		if policy.AutoStop {
+			policies[name] = policy
+		}
+	}
+	return policies
+}
+
+// SetIdlePolicy updates the idle auto-stop policy of a service and persists it
+func (cm *ConfigManager) SetIdlePolicy(name string, policy IdlePolicy) error {
+	config := cm.GetConfig()
+	if config.IdleStop == nil {
+		config.IdleStop = make(map[string]IdlePolicy)
+	}
+
+	config.IdleStop[name] = policy
+	return cm.SaveConfig(config)
+}
+
+// GetActivationProxies returns the enabled activation proxies by service name
+func (cm *ConfigManager) GetActivationProxies() map[string]ActivationProxy {
+	proxies := make(map[string]ActivationProxy)
+	if cm.config == nil {
+		return proxies
+	}
+	for name, proxy := range cm.config.ActivationProxies {
+		if proxy.Enabled {
+			proxies[name] = proxy
+		}
+	}
+	return proxies
+}
+
+// GetActivationProxy returns the activation proxy of a service and whether it is enabled
+func (cm *ConfigManager) GetActivationProxy(name string) (ActivationProxy, bool) {
+	if cm.config == nil {
+		return ActivationProxy{}, false
+	}
+	proxy, exists := cm.config.ActivationProxies[name]
+	return proxy, exists && proxy.Enabled
+}
+
+// SetActivationProxy updates the activation proxy of a service and persists it.
+// Proxies are started with the application, so changes take effect after a restart.
+func (cm *ConfigManager) SetActivationProxy(name string, proxy ActivationProxy) error {
+	config := cm.GetConfig()
+	if config.ActivationProxies == nil {
+		config.ActivationProxies = make(map[string]ActivationProxy)
+	}
+
+	config.ActivationProxies[name] = proxy
+	return cm.SaveConfig(config)
+}
+
 // ValidateHotkey validates a hotkey combination string
 func (cm *ConfigManager) ValidateHotkey(combination string) error {
 	if combination == "" {
@@ -321,6 +710,53 @@ func (cm *ConfigManager) validateConfig(config *AppConfig) error {
 		return fmt.Errorf("invalid global hotkey: %w", err)
 	}
 
+	// Validate API port, zero selects the default
+	if config.APIPort < 0 || config.APIPort > 65535 {
+		return fmt.Errorf("invalid API port: %d", config.APIPort)
+	}
+
+	// Validate service groups
+	if err := validateServiceGroups(config.ServiceGroups); err != nil {
+		return err
+	}
+
+	// Validate readiness probes
+	if err := validateReadinessConfigs(config.Readiness); err != nil {
+		return err
+	}
+
+	// Validate operation timeouts
+	if err := validateOperationTimeouts(config.OperationTimeouts); err != nil {
+		return err
+	}
+
+	// Validate watchdog policies
+	if err := validateWatchdogPolicies(config.Watchdog); err != nil {
+		return err
+	}
+
+	// Validate idle auto-stop policies
+	if err := validateIdlePolicies(config.IdleStop); err != nil {
+		return err
+	}
+
+	// Validate activation proxies
+	if err := validateActivationProxies(config.ActivationProxies); err != nil {
+		return err
+	}
+
+	// Validate schedule rules
+	if err := validateScheduleRules(config.Schedules); err != nil {
+		return err
+	}
+
+	// Validate remembered startup types, which must be restorable
+	for name, startupType := range config.PreviousStartupTypes {
+		if !startupType.settable() || startupType == StartupDisabled {
+			return fmt.Errorf("invalid previous startup type %q for service %q", startupType, name)
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// Friday 16 October 2026
+	friday := time.Date(2026, 10, 16, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		after      time.Time
+		expected   time.Time
+	}{
+		{"0 19 * * mon-fri", friday, time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)},
+		{"0 19 * * mon-fri", friday.Add(time.Hour), time.Date(2026, 10, 19, 19, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", friday, time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", friday.Add(time.Minute), time.Date(2026, 10, 16, 18, 45, 0, 0, time.UTC)},
+		{"30 3 * * *", friday, time.Date(2026, 10, 17, 3, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan *", friday, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", friday, time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)},
+		// Restricting both days matches either: the 20th or any Sunday
+		{"0 0 20 * sun", friday, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"5/20 10 * * *", friday, time.Date(2026, 10, 17, 10, 5, 0, 0, time.UTC)},
+		// A run exactly at the given time is not the next one
+		{"30 18 * * *", friday, time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC)},
+		{"0 0 30 2 *", friday, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			schedule, err := parseCronSchedule(tt.expression)
+			if err != nil {
+				t.Fatalf("parseCronSchedule() failed: %v", err)
+			}
+			if next := schedule.next(tt.after); !next.Equal(tt.expected) {
+				t.Errorf("next(%s) = %s, expected %s", tt.after, next, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCronScheduleErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"0 19 * *",
+		"60 * * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"0 19 * * fri-mon",
+		"*/0 * * * *",
+		"0 19 * * weekdays",
+	} {
+		if _, err := parseCronSchedule(expression); err == nil {
+			t.Errorf("Expected %q to be rejected", expression)
+		}
+	}
+}
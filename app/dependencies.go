@@ -0,0 +1,238 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DependencyNode is a service in the dependency graph
+type DependencyNode struct {
+	Name        string        `json:"Name"`
+	DisplayName string        `json:"DisplayName,omitempty"`
+	Status      ServiceStatus `json:"Status"`
+	Detected    bool          `json:"Detected"` // False for services that are not database services, e.g. RpcSs
+}
+
+// DependencyEdge states that one service requires another
+type DependencyEdge struct {
+	From string `json:"From"` // The dependent service
+	To   string `json:"To"`   // The service it requires
+}
+
+// DependencyGraph holds the detected services, the services they depend on and the services
+// that depend on them
+type DependencyGraph struct {
+	Nodes []DependencyNode `json:"Nodes"`
+	Edges []DependencyEdge `json:"Edges"`
+}
+
+// GetDependencyGraph returns the dependencies between the detected services and their neighbours.
+// Services whose dependencies cannot be read are included without edges.
+func (sm *ServiceManager) GetDependencyGraph() (*DependencyGraph, error) {
+	services, err := sm.GetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*DependencyNode, len(services))
+	expanded := make(map[string]bool)
+	var queue []string
+	for _, service := range services {
+		nodes[service.Name] = &DependencyNode{Name: service.Name, DisplayName: service.DisplayName, Status: service.Status, Detected: true}
+		queue = append(queue, service.Name)
+	}
+
+	addNode := func(name string) {
+		if _, exists := nodes[name]; exists {
+			return
+		}
+		node := &DependencyNode{Name: name, Status: StatusStopped}
+		if status, err := sm.adapter.GetServiceStatus(name); err == nil {
+			node.Status = status
+		}
+		nodes[name] = node
+	}
+
+	graph := &DependencyGraph{Nodes: []DependencyNode{}, Edges: []DependencyEdge{}}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if expanded[name] {
+			continue
+		}
+		expanded[name] = true
+
+		// Edges come from the dependency lists only, since dependents may be reported transitively
+		if dependencies, err := sm.adapter.GetDependencies(name); err == nil {
+			for _, dependency := range dependencies {
+				addNode(dependency)
+				graph.Edges = append(graph.Edges, DependencyEdge{From: name, To: dependency})
+			}
+		}
+
+		// Dependents of detected services are expanded so that their own edges are known
+		if nodes[name].Detected {
+			if dependents, err := sm.adapter.GetDependents(name); err == nil {
+				for _, dependent := range dependents {
+					addNode(dependent)
+					queue = append(queue, dependent)
+				}
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, *node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Name < graph.Nodes[j].Name })
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].From != graph.Edges[j].From {
+			return graph.Edges[i].From < graph.Edges[j].From
+		}
+		return graph.Edges[i].To < graph.Edges[j].To
+	})
+	return graph, nil
+}
+
+// GetDependentsToStop returns the running services that depend on a service, directly or indirectly,
+// in the order they are stopped before it. Stop and restart stop these services first.
+func (sm *ServiceManager) GetDependentsToStop(name string) ([]string, error) {
+	order, err := sm.dependentStopOrder(name)
+	if err != nil {
+		return nil, err
+	}
+
+	running := []string{}
+	for _, dependent := range order {
+		if status, err := sm.adapter.GetServiceStatus(dependent); err == nil && (status.hasProcess() || status == StatusStarting) {
+			running = append(running, dependent)
+		}
+	}
+	return running, nil
+}
+
+// dependentStopOrder returns every service that depends on a service, each one after the services
+// that depend on it, so that stopping them in order never stops a service another one still requires
+func (sm *ServiceManager) dependentStopOrder(name string) ([]string, error) {
+	var order []string
+	visited := map[string]bool{name: true}
+
+	var visit func(service string) error
+	visit = func(service string) error {
+		dependents, err := sm.adapter.GetDependents(service)
+		if err != nil {
+			return err
+		}
+		for _, dependent := range dependents {
+			if visited[dependent] {
+				continue
+			}
+			visited[dependent] = true
+			if err := visit(dependent); err != nil {
+				return err
+			}
+			order = append(order, dependent)
+		}
+		return nil
+	}
+
+	if err := visit(name); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// stopDependents stops the running dependents of a service and returns the ones it stopped,
+// in stop order. Each stop is recorded as an operation of its own and ends when ctx does.
+func (c *ServiceControl) stopDependents(ctx context.Context, name string) ([]string, error) {
+	dependents, err := c.manager.GetDependentsToStop(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := make([]string, 0, len(dependents))
+	for _, dependent := range dependents {
+		err := c.run(ctx, "stop", dependent, c.manager.stopService)
+
+		// A dependent that stopped meanwhile, e.g. together with another one, needs no stop
+		var serviceErr *ServiceError
+		if err != nil && !(errors.As(err, &serviceErr) && serviceErr.Code == ErrInvalidState) {
+			return stopped, &ServiceError{
+				Code:    ErrSystemError,
+				Message: fmt.Sprintf("Failed to stop dependent service %s: %v", dependent, err),
+				Service: name,
+			}
+		}
+		stopped = append(stopped, dependent)
+	}
+	return stopped, nil
+}
+
+// stopWithDependents stops the running dependents of a service and then the service itself.
+// If the service cannot be stopped, the dependents are started again.
+func (c *ServiceControl) stopWithDependents(ctx context.Context, name string) error {
+	if err := c.manager.validateStop(name); err != nil {
+		return err
+	}
+
+	stopped, err := c.stopDependents(ctx, name)
+	if err == nil {
+		err = c.manager.stopService(ctx, name)
+	}
+	if err != nil {
+		c.startDependents(stopped)
+		return err
+	}
+	return nil
+}
+
+// restartWithDependents stops the running dependents of a service, restarts it and starts
+// the dependents again once it is ready
+func (c *ServiceControl) restartWithDependents(ctx context.Context, name string) error {
+	if err := c.manager.RequireElevationForOperation(); err != nil {
+		return err
+	}
+
+	stopped, err := c.stopDependents(ctx, name)
+	if err != nil {
+		c.startDependents(stopped)
+		return err
+	}
+
+	if err := c.manager.restartService(ctx, name); err != nil {
+		if len(stopped) > 0 {
+			return withErrorDetail(err, name, "dependent services left stopped: "+strings.Join(stopped, ", "))
+		}
+		return err
+	}
+
+	if err := c.startDependents(stopped); err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Service restarted, but %v", err),
+			Service: name,
+		}
+	}
+	return nil
+}
+
+// startDependents starts services stopped by stopDependents again, dependencies first,
+// and returns an error naming the services that could not be started. They are started even
+// if the operation that stopped them was canceled, so that canceling leaves nothing stopped.
+func (c *ServiceControl) startDependents(stopped []string) error {
+	var failed []string
+	for i := len(stopped) - 1; i >= 0; i-- {
+		err := c.run(c.ctx, "start", stopped[i], c.manager.startService)
+		var serviceErr *ServiceError
+		if err != nil && !(errors.As(err, &serviceErr) && serviceErr.Code == ErrInvalidState) {
+			failed = append(failed, stopped[i])
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to restart dependent services: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
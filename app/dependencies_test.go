@@ -0,0 +1,161 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// createTestDependencyAdapter returns SQL Server with its Agent, a job runner that requires the
+// Agent and a stopped Reporting Services instance, all depending on the engine
+func createTestDependencyAdapter() *fakeServiceAdapter {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "RpcSs", Status: StatusRunning},
+		OSService{Name: "MSSQLSERVER", Status: StatusRunning},
+		OSService{Name: "SQLSERVERAGENT", Status: StatusRunning},
+		OSService{Name: "JobRunner", Status: StatusRunning},
+		OSService{Name: "ReportServer", Status: StatusStopped},
+	)
+	adapter.requires["MSSQLSERVER"] = []string{"RpcSs"}
+	adapter.requires["SQLSERVERAGENT"] = []string{"MSSQLSERVER"}
+	adapter.requires["JobRunner"] = []string{"SQLSERVERAGENT"}
+	adapter.requires["ReportServer"] = []string{"MSSQLSERVER"}
+	return adapter
+}
+
+func TestGetDependentsToStop(t *testing.T) {
+	sm := createTestServiceManager(t, createTestDependencyAdapter())
+
+	dependents, err := sm.GetDependentsToStop("MSSQLSERVER")
+	if err != nil {
+		t.Fatalf("GetDependentsToStop() failed: %v", err)
+	}
+	// The job runner stops before the Agent it requires; the stopped ReportServer is left alone
+	if expected := []string{"JobRunner", "SQLSERVERAGENT"}; !reflect.DeepEqual(dependents, expected) {
+		t.Errorf("Expected %v, got %v", expected, dependents)
+	}
+
+	if dependents, err := sm.GetDependentsToStop("JobRunner"); err != nil || len(dependents) != 0 {
+		t.Errorf("Expected no dependents, got %v, %v", dependents, err)
+	}
+	if _, err := sm.GetDependentsToStop("missing"); err == nil {
+		t.Error("Expected an error for an unknown service")
+	}
+}
+
+func TestStopServiceStopsDependentsFirst(t *testing.T) {
+	adapter := createTestDependencyAdapter()
+	sm := createTestServiceManager(t, adapter)
+
+	if err := sm.WaitForOperation(sm.StopService("MSSQLSERVER")); err != nil {
+		t.Fatalf("StopService() failed: %v", err)
+	}
+
+	expected := []string{"stop JobRunner", "stop SQLSERVERAGENT", "stop MSSQLSERVER"}
+	if calls := adapter.callLog(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+
+	// Every stop is audited on its own
+	entries, err := sm.GetHistory(AuditFilter{})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	var audited []string
+	for _, entry := range entries {
+		audited = append(audited, entry.Operation+" "+entry.Service)
+	}
+	if expected := []string{"stop MSSQLSERVER", "stop SQLSERVERAGENT", "stop JobRunner"}; !reflect.DeepEqual(audited, expected) {
+		t.Errorf("Expected audit entries %v, got %v", expected, audited)
+	}
+}
+
+func TestStopServiceRestartsDependentsOnFailure(t *testing.T) {
+	adapter := createTestDependencyAdapter()
+	adapter.failOn["stop MSSQLSERVER"] = &ServiceError{Code: ErrOperationTimeout, Message: "Timed out", Service: "MSSQLSERVER"}
+	sm := createTestServiceManager(t, adapter)
+
+	if err := sm.WaitForOperation(sm.StopService("MSSQLSERVER")); err == nil {
+		t.Fatal("Expected the stop to fail")
+	}
+
+	expected := []string{"stop JobRunner", "stop SQLSERVERAGENT", "stop MSSQLSERVER", "start SQLSERVERAGENT", "start JobRunner"}
+	if calls := adapter.callLog(); !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %v, got %v", expected, calls)
+	}
+}
+
+func TestRestartServiceRestartsDependents(t *testing.T) {
+	tests := []struct {
+		name          string
+		failRestart   bool
+		expectedCalls []string
+		expectedError string
+	}{
+		{
+			name:          "dependents are started again",
+			expectedCalls: []string{"stop JobRunner", "stop SQLSERVERAGENT", "restart MSSQLSERVER", "start SQLSERVERAGENT", "start JobRunner"},
+		},
+		{
+			name:          "dependents stay stopped if the restart fails",
+			failRestart:   true,
+			expectedCalls: []string{"stop JobRunner", "stop SQLSERVERAGENT", "restart MSSQLSERVER"},
+			expectedError: "dependent services left stopped: JobRunner, SQLSERVERAGENT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := createTestDependencyAdapter()
+			if tt.failRestart {
+				adapter.failOn["restart MSSQLSERVER"] = &ServiceError{Code: ErrOperationTimeout, Message: "Timed out", Service: "MSSQLSERVER"}
+			}
+			sm := createTestServiceManager(t, adapter)
+
+			err := sm.WaitForOperation(sm.RestartService("MSSQLSERVER"))
+			if tt.expectedError == "" && err != nil {
+				t.Fatalf("RestartService() failed: %v", err)
+			}
+			if tt.expectedError != "" {
+				serviceErr, ok := err.(*ServiceError)
+				if !ok || serviceErr.Code != ErrOperationTimeout || !strings.Contains(serviceErr.Message, tt.expectedError) {
+					t.Fatalf("Expected a timeout mentioning %q, got %v", tt.expectedError, err)
+				}
+			}
+
+			if calls := adapter.callLog(); !reflect.DeepEqual(calls, tt.expectedCalls) {
+				t.Errorf("Expected calls %v, got %v", tt.expectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestGetDependencyGraph(t *testing.T) {
+	sm := createTestServiceManager(t, createTestDependencyAdapter())
+
+	graph, err := sm.GetDependencyGraph()
+	if err != nil {
+		t.Fatalf("GetDependencyGraph() failed: %v", err)
+	}
+
+	var nodes []string
+	for _, node := range graph.Nodes {
+		nodes = append(nodes, node.Name)
+		if detected := node.Name == "MSSQLSERVER" || node.Name == "SQLSERVERAGENT" || node.Name == "ReportServer"; node.Detected != detected {
+			t.Errorf("Node %s: expected Detected=%v", node.Name, detected)
+		}
+	}
+	if expected := []string{"JobRunner", "MSSQLSERVER", "ReportServer", "RpcSs", "SQLSERVERAGENT"}; !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("Expected nodes %v, got %v", expected, nodes)
+	}
+
+	expectedEdges := []DependencyEdge{
+		{From: "JobRunner", To: "SQLSERVERAGENT"},
+		{From: "MSSQLSERVER", To: "RpcSs"},
+		{From: "ReportServer", To: "MSSQLSERVER"},
+		{From: "SQLSERVERAGENT", To: "MSSQLSERVER"},
+	}
+	if !reflect.DeepEqual(graph.Edges, expectedEdges) {
+		t.Errorf("Expected edges %v, got %v", expectedEdges, graph.Edges)
+	}
+}
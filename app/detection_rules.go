@@ -0,0 +1,289 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// detectionRulesFileName is the rule file in the ShutDB config directory
+const detectionRulesFileName = "detection_rules.json"
+
+// Fields a detection rule can be matched against
+const (
+	MatchAny         = "any"
+	MatchName        = "name"
+	MatchDisplayName = "display_name"
+)
+
+// customServiceTypePattern restricts custom type names to identifiers that are safe in configs and URLs
+var customServiceTypePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// builtinCategories are the categories known to GetCategoryInfo
+var builtinCategories = map[ServiceCategory]bool{
+	CategorySQL:       true,
+	CategoryNoSQL:     true,
+	CategoryCache:     true,
+	CategorySearch:    true,
+	CategoryMessaging: true,
+}
+
+// DetectionRule classifies services that the built-in patterns do not know, e.g. in-house
+// services or products such as ClickHouse or Kafka. A service matches a rule when any include
+// pattern matches and no exclude pattern does. Substrings and regular expressions are
+// matched case-insensitively.
+type DetectionRule struct {
+	Name         string          `json:"name"`
+	Include      []string        `json:"include,omitempty"`
+	IncludeRegex []string        `json:"include_regex,omitempty"`
+	Exclude      []string        `json:"exclude,omitempty"`
+	ExcludeRegex []string        `json:"exclude_regex,omitempty"`
+	Match        string          `json:"match,omitempty"` // "name", "display_name" or "any" (default)
+	Type         ServiceType     `json:"type"`
+	Category     ServiceCategory `json:"category,omitempty"` // Required for custom types
+}
+
+// detectionRulesFile is the layout of detection_rules.json
+type detectionRulesFile struct {
+	Rules []DetectionRule `json:"rules"`
+}
+
+// compiledDetectionRule is a validated rule with lowercased substrings and compiled regexes
+type compiledDetectionRule struct {
+	rule         DetectionRule
+	include      []string
+	includeRegex []*regexp.Regexp
+	exclude      []string
+	excludeRegex []*regexp.Regexp
+}
+
+// LoadDetectionRules reads and validates a rule file; a missing file means no rules
+func LoadDetectionRules(path string) ([]DetectionRule, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	// Reject unknown fields so that a misspelt key does not silently disable a rule
+	var file detectionRulesFile
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid detection rules in %s: %w", path, err)
+	}
+
+	if err := ValidateDetectionRules(file.Rules); err != nil {
+		return nil, fmt.Errorf("invalid detection rules in %s: %w", path, err)
+	}
+	return file.Rules, nil
+}
+
+// ValidateDetectionRules checks every rule and reports the first problem together with the rule it was found in
+func ValidateDetectionRules(rules []DetectionRule) error {
+	_, err := compileDetectionRules(rules)
+	return err
+}
+
+// compileDetectionRules validates rules and prepares them for matching
+func compileDetectionRules(rules []DetectionRule) ([]compiledDetectionRule, error) {
+	compiled := make([]compiledDetectionRule, 0, len(rules))
+	names := make(map[string]bool, len(rules))
+	customCategories := make(map[ServiceType]ServiceCategory)
+
+	for i, rule := range rules {
+		ruleErr := func(format string, args ...interface{}) error {
+			label := fmt.Sprintf("rule %d", i+1)
+			if rule.Name != "" {
+				label = fmt.Sprintf("rule %d (%q)", i+1, rule.Name)
+			}
+			return fmt.Errorf("%s: %s", label, fmt.Sprintf(format, args...))
+		}
+
+		if rule.Name == "" {
+			return nil, ruleErr("name is required")
+		}
+		if names[rule.Name] {
+			return nil, ruleErr("duplicate rule name")
+		}
+		names[rule.Name] = true
+
+		switch rule.Match {
+		case "", MatchAny, MatchName, MatchDisplayName:
+		default:
+			return nil, ruleErr("match must be %q, %q or %q, got %q", MatchName, MatchDisplayName, MatchAny, rule.Match)
+		}
+
+		if len(rule.Include) == 0 && len(rule.IncludeRegex) == 0 {
+			return nil, ruleErr("at least one include pattern is required")
+		}
+
+		if rule.Type == "" {
+			return nil, ruleErr("type is required")
+		}
+		if !customServiceTypePattern.MatchString(string(rule.Type)) {
+			return nil, ruleErr("type %q must contain only lowercase letters, digits, '-' and '_'", rule.Type)
+		}
+		if rule.Category != "" && !builtinCategories[rule.Category] {
+			return nil, ruleErr("unknown category %q", rule.Category)
+		}
+
+		if builtinCategory, builtin := builtinServiceCategories[rule.Type]; builtin {
+			if rule.Category != "" && rule.Category != builtinCategory {
+				return nil, ruleErr("type %q belongs to category %q, not %q", rule.Type, builtinCategory, rule.Category)
+			}
+		} else {
+			if rule.Category == "" {
+				return nil, ruleErr("category is required for custom type %q", rule.Type)
+			}
+			if previous, exists := customCategories[rule.Type]; exists && previous != rule.Category {
+				return nil, ruleErr("custom type %q is already assigned to category %q", rule.Type, previous)
+			}
+			customCategories[rule.Type] = rule.Category
+		}
+
+		c := compiledDetectionRule{rule: rule}
+		var err error
+		if c.include, err = lowerPatterns(rule.Include); err != nil {
+			return nil, ruleErr("include: %v", err)
+		}
+		if c.exclude, err = lowerPatterns(rule.Exclude); err != nil {
+			return nil, ruleErr("exclude: %v", err)
+		}
+		if c.includeRegex, err = compilePatterns(rule.IncludeRegex); err != nil {
+			return nil, ruleErr("include_regex: %v", err)
+		}
+		if c.excludeRegex, err = compilePatterns(rule.ExcludeRegex); err != nil {
+			return nil, ruleErr("exclude_regex: %v", err)
+		}
+		compiled = append(compiled, c)
+	}
+
+	return compiled, nil
+}
+
+// lowerPatterns lowercases substring patterns, rejecting empty ones that would match everything
+func lowerPatterns(patterns []string) ([]string, error) {
+	lowered := make([]string, len(patterns))
+	for i, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			return nil, fmt.Errorf("pattern %d is empty", i+1)
+		}
+		lowered[i] = strings.ToLower(pattern)
+	}
+	return lowered, nil
+}
+
+// compilePatterns compiles case-insensitive regular expressions
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("pattern %d is empty", i+1)
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", pattern, err)
+		}
+		compiled[i] = re
+	}
+	return compiled, nil
+}
+
+// match explains how the rule selects a service, or reports false if it does not
+func (c *compiledDetectionRule) match(name, displayName string) (*MatchExplanation, bool) {
+	fields := []struct{ field, value string }{{MatchName, name}, {MatchDisplayName, displayName}}
+	switch c.rule.Match {
+	case MatchName:
+		fields = fields[:1]
+	case MatchDisplayName:
+		fields = fields[1:]
+	}
+
+	var best *MatchExplanation
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if matchesAnyPattern(f.value, c.exclude, c.excludeRegex) {
+			return nil, false
+		}
+		if best != nil {
+			continue
+		}
+
+		// Explain the strongest include pattern of the first field that matches
+		for _, pattern := range c.include {
+			if kind, _, ok := matchPattern(f.value, pattern); ok && (best == nil || matchKindRanks[kind] > matchKindRanks[best.Kind]) {
+				best = &MatchExplanation{Field: f.field, Value: f.value, Pattern: pattern, Kind: kind}
+			}
+		}
+		if best == nil {
+			for _, re := range c.includeRegex {
+				if re.MatchString(f.value) {
+					best = &MatchExplanation{Field: f.field, Value: f.value, Pattern: strings.TrimPrefix(re.String(), "(?i)"), Kind: MatchKindRegex}
+					break
+				}
+			}
+		}
+	}
+	if best == nil {
+		return nil, false
+	}
+
+	best.Type = c.rule.Type
+	best.Source = MatchSourceRule
+	best.Rule = c.rule.Name
+	best.describe()
+	return best, true
+}
+
+// matchesAnyPattern reports whether a value contains any substring or matches any regex
+func matchesAnyPattern(value string, substrings []string, regexes []*regexp.Regexp) bool {
+	lower := strings.ToLower(value)
+	for _, substring := range substrings {
+		if strings.Contains(lower, substring) {
+			return true
+		}
+	}
+	for _, re := range regexes {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// customServiceTypes maps the custom types of the active detection rules to their categories
+var customServiceTypes = struct {
+	sync.RWMutex
+	categories map[ServiceType]ServiceCategory
+}{categories: map[ServiceType]ServiceCategory{}}
+
+// registerCustomServiceTypes makes the custom types of the given rules known to GetServiceCategory
+func registerCustomServiceTypes(rules []compiledDetectionRule) {
+	categories := make(map[ServiceType]ServiceCategory)
+	for _, c := range rules {
+		if _, builtin := builtinServiceCategories[c.rule.Type]; !builtin {
+			categories[c.rule.Type] = c.rule.Category
+		}
+	}
+
+	customServiceTypes.Lock()
+	customServiceTypes.categories = categories
+	customServiceTypes.Unlock()
+}
+
+// customServiceCategory returns the category of a custom type registered by a detection rule
+func customServiceCategory(serviceType ServiceType) (ServiceCategory, bool) {
+	customServiceTypes.RLock()
+	defer customServiceTypes.RUnlock()
+	category, exists := customServiceTypes.categories[serviceType]
+	return category, exists
+}
@@ -0,0 +1,224 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createTestRuleDetector returns a detector with the given rules whose custom types are
+// unregistered again when the test ends
+func createTestRuleDetector(t *testing.T, adapter OSServiceAdapter, rules []DetectionRule) *WindowsServiceDetector {
+	detector := NewWindowsServiceDetector(adapter)
+	if err := detector.SetDetectionRules(rules); err != nil {
+		t.Fatalf("SetDetectionRules() failed: %v", err)
+	}
+	t.Cleanup(func() { registerCustomServiceTypes(nil) })
+	return detector
+}
+
+func TestDetectionRulesDetectCustomServices(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "acme-pg-replica", DisplayName: "ACME Replica", Status: StatusRunning},
+		OSService{Name: "ClickHouseServer", DisplayName: "ClickHouse Server", Status: StatusRunning},
+		OSService{Name: "clickhouse-keeper", DisplayName: "ClickHouse Keeper", Status: StatusRunning},
+		OSService{Name: "kafka", DisplayName: "Apache Kafka Broker", Status: StatusStopped},
+		OSService{Name: "svc-042", DisplayName: "MinIO Object Storage", Status: StatusRunning},
+		OSService{Name: "postgresql-x64-16", DisplayName: "PostgreSQL Server 16", Status: StatusRunning},
+		OSService{Name: "Spooler", DisplayName: "Print Spooler", Status: StatusRunning},
+	)
+	detector := createTestRuleDetector(t, adapter, []DetectionRule{
+		{Name: "acme", Include: []string{"acme-pg-"}, Type: TypePostgreSQL},
+		{Name: "clickhouse", IncludeRegex: []string{`^clickhouse`}, Exclude: []string{"keeper"}, Type: "clickhouse", Category: CategorySearch},
+		{Name: "kafka", Include: []string{"kafka"}, Match: MatchDisplayName, Type: "kafka", Category: CategoryMessaging},
+		{Name: "minio", Include: []string{"minio"}, Type: "minio", Category: CategoryNoSQL},
+	})
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+
+	expected := map[string]struct {
+		serviceType ServiceType
+		category    ServiceCategory
+	}{
+		"acme-pg-replica":   {TypePostgreSQL, CategorySQL},
+		"ClickHouseServer":  {"clickhouse", CategorySearch},
+		"kafka":             {"kafka", CategoryMessaging},
+		"svc-042":           {"minio", CategoryNoSQL},
+		"postgresql-x64-16": {TypePostgreSQL, CategorySQL},
+	}
+
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d detected services, got %d: %+v", len(expected), len(services), services)
+	}
+	for _, service := range services {
+		want, exists := expected[service.Name]
+		if !exists {
+			t.Errorf("Unexpected service %s", service.Name)
+			continue
+		}
+		if service.Type != want.serviceType || service.Category != want.category {
+			t.Errorf("%s: expected %s/%s, got %s/%s", service.Name, want.serviceType, want.category, service.Type, service.Category)
+		}
+	}
+}
+
+func TestDetectionRulesTakePrecedenceOverKnownPatterns(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "redis-postgres-sync", Status: StatusRunning})
+	detector := createTestRuleDetector(t, adapter, []DetectionRule{
+		{Name: "sync", Include: []string{"-sync"}, Match: MatchName, Type: "sync-worker", Category: CategoryMessaging},
+	})
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+	if len(services) != 1 || services[0].Type != "sync-worker" {
+		t.Errorf("Expected the rule to classify the service, got %+v", services)
+	}
+}
+
+func TestGetServiceCategoryCustomTypes(t *testing.T) {
+	createTestRuleDetector(t, newFakeServiceAdapter(), []DetectionRule{
+		{Name: "kafka", Include: []string{"kafka"}, Type: "kafka", Category: CategoryMessaging},
+	})
+
+	if category := GetServiceCategory("kafka"); category != CategoryMessaging {
+		t.Errorf("Expected %s, got %s", CategoryMessaging, category)
+	}
+	if info := GetCategoryInfo(GetServiceCategory("kafka")); info["name"] != "Message Brokers" {
+		t.Errorf("Expected the message broker category info, got %v", info)
+	}
+	if category := GetServiceCategory(TypeRedis); category != CategoryCache {
+		t.Errorf("Built-in types must keep their category, got %s", category)
+	}
+
+	// Replacing the rules unregisters custom types that are no longer defined
+	registerCustomServiceTypes(nil)
+	if category := GetServiceCategory("kafka"); category != CategorySQL {
+		t.Errorf("Expected the default category for an unknown type, got %s", category)
+	}
+}
+
+func TestValidateDetectionRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []DetectionRule
+		expected string // Substring of the error, empty if valid
+	}{
+		{"valid", []DetectionRule{{Name: "a", Include: []string{"acme"}, Type: TypeRedis}}, ""},
+		{"valid custom", []DetectionRule{{Name: "a", IncludeRegex: []string{"^ch"}, Type: "clickhouse", Category: CategorySearch}}, ""},
+		{"missing name", []DetectionRule{{Include: []string{"acme"}, Type: TypeRedis}}, "rule 1: name is required"},
+		{"duplicate name", []DetectionRule{
+			{Name: "a", Include: []string{"x"}, Type: TypeRedis},
+			{Name: "a", Include: []string{"y"}, Type: TypeRedis},
+		}, `rule 2 ("a"): duplicate rule name`},
+		{"no include", []DetectionRule{{Name: "a", Exclude: []string{"x"}, Type: TypeRedis}}, `rule 1 ("a"): at least one include pattern`},
+		{"empty include", []DetectionRule{{Name: "a", Include: []string{" "}, Type: TypeRedis}}, `include: pattern 1 is empty`},
+		{"bad regex", []DetectionRule{{Name: "a", IncludeRegex: []string{"(acme"}, Type: TypeRedis}}, `include_regex: invalid regular expression "(acme"`},
+		{"bad exclude regex", []DetectionRule{{Name: "a", Include: []string{"x"}, ExcludeRegex: []string{"["}, Type: TypeRedis}}, `exclude_regex`},
+		{"bad match", []DetectionRule{{Name: "a", Include: []string{"x"}, Match: "image", Type: TypeRedis}}, `match must be`},
+		{"missing type", []DetectionRule{{Name: "a", Include: []string{"x"}}}, "type is required"},
+		{"bad type", []DetectionRule{{Name: "a", Include: []string{"x"}, Type: "Click House", Category: CategorySearch}}, `type "Click House" must contain only`},
+		{"custom without category", []DetectionRule{{Name: "a", Include: []string{"x"}, Type: "kafka"}}, `category is required for custom type "kafka"`},
+		{"unknown category", []DetectionRule{{Name: "a", Include: []string{"x"}, Type: "kafka", Category: "streaming"}}, `unknown category "streaming"`},
+		{"built-in type in other category", []DetectionRule{{Name: "a", Include: []string{"x"}, Type: TypeRedis, Category: CategorySQL}}, `type "redis" belongs to category "cache_memory"`},
+		{"conflicting custom categories", []DetectionRule{
+			{Name: "a", Include: []string{"x"}, Type: "kafka", Category: CategoryMessaging},
+			{Name: "b", Include: []string{"y"}, Type: "kafka", Category: CategoryNoSQL},
+		}, `rule 2 ("b"): custom type "kafka" is already assigned`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDetectionRules(tt.rules)
+			if tt.expected == "" {
+				if err != nil {
+					t.Errorf("Expected rules to be valid, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("Expected error containing %q, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadDetectionRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string // Empty for a missing file
+		rules    int
+		expected string // Substring of the error, empty if valid
+	}{
+		{"missing file", "", 0, ""},
+		{"valid", `{"rules": [{"name": "kafka", "include": ["kafka"], "type": "kafka", "category": "message_brokers"}]}`, 1, ""},
+		{"malformed JSON", `{"rules": [`, 0, "invalid detection rules"},
+		{"unknown field", `{"rules": [{"name": "kafka", "inclde": ["kafka"], "type": "kafka"}]}`, 0, `unknown field "inclde"`},
+		{"invalid rule", `{"rules": [{"name": "kafka", "include": ["kafka"], "type": "kafka"}]}`, 0, `rule 1 ("kafka"): category is required`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), detectionRulesFileName)
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("Failed to write rules: %v", err)
+				}
+			}
+
+			rules, err := LoadDetectionRules(path)
+			if tt.expected != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expected) || !strings.Contains(err.Error(), path) {
+					t.Errorf("Expected error containing %q and the file path, got %v", tt.expected, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDetectionRules() failed: %v", err)
+			}
+			if len(rules) != tt.rules {
+				t.Errorf("Expected %d rules, got %d", tt.rules, len(rules))
+			}
+		})
+	}
+}
+
+func TestServiceManagerReloadDetectionRules(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "kafka", Status: StatusRunning})
+	sm := createTestServiceManager(t, adapter)
+	t.Cleanup(func() { registerCustomServiceTypes(nil) })
+
+	if services, err := sm.GetServices(); err != nil || len(services) != 0 {
+		t.Fatalf("Expected no services without rules, got %+v (%v)", services, err)
+	}
+
+	path := sm.configManager.GetDetectionRulesPath()
+	valid := `{"rules": [{"name": "kafka", "include": ["kafka"], "type": "kafka", "category": "message_brokers"}]}`
+	if err := os.WriteFile(path, []byte(valid), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	if err := sm.ReloadDetectionRules(); err != nil {
+		t.Fatalf("ReloadDetectionRules() failed: %v", err)
+	}
+
+	services, err := sm.GetServices()
+	if err != nil || len(services) != 1 || services[0].Type != "kafka" || services[0].Category != CategoryMessaging {
+		t.Fatalf("Expected kafka to be detected after reloading, got %+v (%v)", services, err)
+	}
+
+	// An invalid file is reported and the previous rules stay in effect
+	if err := os.WriteFile(path, []byte(`{"rules": [{"name": "kafka"}]}`), 0644); err != nil {
+		t.Fatalf("Failed to write rules: %v", err)
+	}
+	if err := sm.ReloadDetectionRules(); err == nil {
+		t.Fatal("Expected an invalid rule file to be rejected")
+	}
+	if services, err := sm.GetServices(); err != nil || len(services) != 1 {
+		t.Errorf("Expected the previous rules to stay in effect, got %+v (%v)", services, err)
+	}
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"strings"
+	"sync"
 )
 
 // ServiceDetector defines the interface for detecting database services
@@ -11,54 +12,95 @@ type ServiceDetector interface {
 
 // WindowsServiceDetector implements ServiceDetector for Windows
 type WindowsServiceDetector struct {
-	adapter       OSServiceAdapter
-	knownPatterns map[ServiceType][]string
+	adapter            OSServiceAdapter
+	knownPatterns      []servicePattern
+	executablePatterns []servicePattern // Known patterns plus database executables with unrelated names
+	versionOf          func(binaryPath string) string
+	ports              *portScanner
+	rules              []compiledDetectionRule // User-defined rules, checked before the known patterns
+	rulesMu            sync.RWMutex
 }
 
 // NewWindowsServiceDetector creates a new Windows service detector
 func NewWindowsServiceDetector(adapter OSServiceAdapter) *WindowsServiceDetector {
-	return &WindowsServiceDetector{
-		adapter: adapter,
-		knownPatterns: map[ServiceType][]string{
+	d := &WindowsServiceDetector{
+		adapter:   adapter,
+		versionOf: newBinaryVersionCache().Version,
+		ports:     newPortScanner(),
+		knownPatterns: []servicePattern{
 			// Relational Databases
-			TypePostgreSQL: {"postgresql", "postgres"},
-			TypeMySQL:      {"mysql"},
-			TypeMariaDB:    {"mariadb"},
-			TypeMSSQL: {
-				"mssql", "sqlserver", "mssqlserver",
-				"sqlagent",       // SQL Server Agent
-				"sqlbrowser",     // SQL Server Browser
-				"sqlwriter",      // SQL Server VSS Writer
-				"sqlceip",        // SQL Server CEIP
-				"sqltelemetry",   // SQL Server Telemetry
-				"msdtsserver",    // SQL Server Integration Services
-				"msftesql",       // SQL Server FullText Search
-				"reportserver",   // SQL Server Reporting Services
-				"sqlserveragent", // Alternative Agent name
-			},
-			TypeOracle:   {"oracle", "oracleservice"},
-			TypeDB2:      {"db2"},
-			TypeFirebird: {"firebird", "firebirdserver"},
-			TypeSQLite:   {"sqlite"},
+			{"postgresql", TypePostgreSQL, priorityProduct},
+			{"postgres", TypePostgreSQL, priorityAlias},
+			{"mysql", TypeMySQL, priorityProduct},
+			{"mariadb", TypeMariaDB, priorityProduct},
+			{"mssqlserver", TypeMSSQL, priorityProduct},
+			{"mssql", TypeMSSQL, priorityAlias},
+			{"sqlserver", TypeMSSQL, priorityAlias},
+			{"sqlagent", TypeMSSQL, priorityComponent},       // SQL Server Agent
+			{"sqlserveragent", TypeMSSQL, priorityComponent}, // Alternative Agent name
+			{"sqlbrowser", TypeMSSQL, priorityComponent},     // SQL Server Browser
+			{"sqlwriter", TypeMSSQL, priorityComponent},      // SQL Server VSS Writer
+			{"sqlceip", TypeMSSQL, priorityComponent},        // SQL Server CEIP
+			{"sqltelemetry", TypeMSSQL, priorityComponent},   // SQL Server Telemetry
+			{"msdtsserver", TypeMSSQL, priorityComponent},    // SQL Server Integration Services
+			{"msftesql", TypeMSSQL, priorityComponent},       // SQL Server FullText Search
+			{"reportserver", TypeMSSQL, priorityComponent},   // SQL Server Reporting Services
+			{"oracleservice", TypeOracle, priorityProduct},
+			{"oracle", TypeOracle, priorityAlias},
+			{"db2", TypeDB2, priorityProduct},
+			{"firebirdserver", TypeFirebird, priorityProduct},
+			{"firebird", TypeFirebird, priorityProduct},
+			{"sqlite", TypeSQLite, priorityProduct},
 
 			// NoSQL Databases
-			TypeMongoDB:   {"mongodb", "mongo"},
-			TypeCassandra: {"cassandra"},
-			TypeCouchDB:   {"couchdb"},
-			TypeNeo4j:     {"neo4j"},
+			{"mongodb", TypeMongoDB, priorityProduct},
+			{"mongo", TypeMongoDB, priorityAlias},
+			{"cassandra", TypeCassandra, priorityProduct},
+			{"couchdb", TypeCouchDB, priorityProduct},
+			{"neo4j", TypeNeo4j, priorityProduct},
 
 			// In-Memory & Cache
-			TypeRedis:     {"redis"},
-			TypeMemcached: {"memcached"},
+			{"redis", TypeRedis, priorityProduct},
+			{"memcached", TypeMemcached, priorityProduct},
 
 			// Search & Analytics
-			TypeElasticsearch: {"elasticsearch", "elastic"},
-			TypeInfluxDB:      {"influxdb", "influx"},
+			{"elasticsearch", TypeElasticsearch, priorityProduct},
+			{"elastic", TypeElasticsearch, priorityAlias},
+			{"influxdb", TypeInfluxDB, priorityProduct},
+			{"influx", TypeInfluxDB, priorityAlias},
 
 			// Message Brokers (often used with databases)
-			TypeRabbitMQ: {"rabbitmq"},
+			{"rabbitmq", TypeRabbitMQ, priorityProduct},
 		},
 	}
+
+	d.executablePatterns = append([]servicePattern{
+		{"pg_ctl", TypePostgreSQL, priorityProduct},       // PostgreSQL's Windows service wrapper
+		{"sqlservr", TypeMSSQL, priorityProduct},          // SQL Server Database Engine
+		{"memurai", TypeRedis, priorityProduct},           // Redis-compatible server for Windows
+		{"tnslsnr", TypeOracle, priorityComponent},        // Oracle listener
+		{"fbserver", TypeFirebird, priorityProduct},       // Firebird SuperServer
+		{"fb_inet_server", TypeFirebird, priorityProduct}, // Firebird Classic
+		{"fbguard", TypeFirebird, priorityComponent},      // Firebird Guardian
+	}, d.knownPatterns...)
+
+	return d
+}
+
+// SetDetectionRules replaces the user-defined detection rules.
+// The rules are validated first; on error the previous rules stay in effect.
+func (d *WindowsServiceDetector) SetDetectionRules(rules []DetectionRule) error {
+	compiled, err := compileDetectionRules(rules)
+	if err != nil {
+		return err
+	}
+
+	d.rulesMu.Lock()
+	d.rules = compiled
+	d.rulesMu.Unlock()
+
+	registerCustomServiceTypes(compiled)
+	return nil
 }
 
 // DetectServices filters OS services by known patterns and returns detected database services
@@ -75,7 +117,7 @@ func (d *WindowsServiceDetector) DetectServices() ([]Service, error) {
 	// Filter services by known patterns with memory-efficient processing
 	for i := range osServices {
 		osService := &osServices[i] // Use pointer to avoid copying
-		serviceType, matched := d.matchServiceType(osService.Name)
+		match, matched := d.MatchService(osService)
 		if matched {
 			// Get startup type only for matched services to reduce API calls
 			startupType, err := d.adapter.GetStartupType(osService.Name)
@@ -86,14 +128,32 @@ func (d *WindowsServiceDetector) DetectServices() ([]Service, error) {
 			detectedServices = append(detectedServices, Service{
 				Name:        osService.Name,
 				DisplayName: osService.DisplayName,
-				Status:      mapWindowsStateToStatus(osService.State),
-				Type:        serviceType,
+				Status:      osService.Status,
+				Type:        match.Type,
 				StartupType: startupType,
-				Category:    GetServiceCategory(serviceType),
+				Category:    GetServiceCategory(match.Type),
+				Backend:     osService.Backend,
+				Version:     d.versionOf(osService.BinaryPath),
+				Match:       match,
 			})
+			service := &detectedServices[len(detectedServices)-1]
+			service.Instance = parseServiceInstance(service, osService)
+			service.ExpectedPort = expectedServicePort(service, osService)
+			service.PublishedPort = publishedServicePort(service, osService)
+			if osService.Status.hasProcess() {
+				service.PID = osService.PID
+			}
+			service.CanPause = osService.CanPause
+			service.StartupTypes = osService.StartupTypes
 		}
 	}
 
+	// Link services that belong to the same instance
+	linkRelatedServices(detectedServices)
+
+	// Attribute listening sockets to running services; ports are informational, so errors are ignored
+	d.discoverPorts(detectedServices)
+
 	// Shrink slice to actual size to free unused capacity
 	if cap(detectedServices) > len(detectedServices)*2 {
 		shrunk := make([]Service, len(detectedServices))
@@ -104,17 +164,69 @@ func (d *WindowsServiceDetector) DetectServices() ([]Service, error) {
 	return detectedServices, nil
 }
 
-// matchServiceType checks if a service name matches any known patterns
-func (d *WindowsServiceDetector) matchServiceType(serviceName string) (ServiceType, bool) {
-	lowerName := strings.ToLower(serviceName)
+// discoverPorts fills in the listening endpoints of every running service with a known process
+func (d *WindowsServiceDetector) discoverPorts(services []Service) {
+	running := false
+	for i := range services {
+		running = running || services[i].PID != 0
+	}
+	if !running || d.ports == nil {
+		return
+	}
 
-	for serviceType, patterns := range d.knownPatterns {
-		for _, pattern := range patterns {
-			if strings.Contains(lowerName, pattern) {
-				return serviceType, true
-			}
+	snapshot, err := d.ports.snapshot()
+	if err != nil {
+		return
+	}
+	for i := range services {
+		services[i].Ports = snapshot.endpointsOf(services[i].PID)
+	}
+}
+
+// MatchService classifies a service and explains the match. User-defined rules are checked
+// first, then the known patterns against the name, then the executable the service runs
+// (installers often register generic names such as "Service1"), then a container's image.
+func (d *WindowsServiceDetector) MatchService(service *OSService) (*MatchExplanation, bool) {
+	if match, matched := d.matchDetectionRules(service.Name, service.DisplayName); matched {
+		return match, true
+	}
+	if match, matched := matchServicePatterns(d.knownPatterns, MatchName, normalizeServiceName(service)); matched {
+		return match, true
+	}
+	if service.BinaryPath != "" {
+		if match, matched := matchServicePatterns(d.executablePatterns, matchFieldExecutable, executableName(service.BinaryPath)); matched {
+			return match, true
 		}
 	}
+	if service.Image != "" {
+		// Containers often have arbitrary names, so fall back to the image
+		return matchServicePatterns(d.knownPatterns, matchFieldImage, imageRepository(service.Image))
+	}
+	return nil, false
+}
+
+// matchDetectionRules explains the first user-defined rule that selects a service
+func (d *WindowsServiceDetector) matchDetectionRules(name, displayName string) (*MatchExplanation, bool) {
+	d.rulesMu.RLock()
+	defer d.rulesMu.RUnlock()
 
-	return "", false
+	for i := range d.rules {
+		if match, matched := d.rules[i].match(name, displayName); matched {
+			return match, true
+		}
+	}
+
+	return nil, false
+}
+
+// imageRepository strips the tag and digest from a container image reference,
+// e.g. "docker.io/library/postgres:16" becomes "docker.io/library/postgres"
+func imageRepository(image string) string {
+	if at := strings.Index(image, "@"); at >= 0 {
+		image = image[:at]
+	}
+	if colon := strings.LastIndex(image, ":"); colon > strings.LastIndex(image, "/") {
+		image = image[:colon]
+	}
+	return image
 }
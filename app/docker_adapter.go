@@ -0,0 +1,443 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// dockerRequestTimeout bounds Engine API requests made without a deadline of their own
+const dockerRequestTimeout = 30 * time.Second
+
+// dockerContainerSummary is the subset of GET /containers/json used by the adapter
+type dockerContainerSummary struct {
+	ID    string       `json:"Id"`
+	Names []string     `json:"Names"`
+	Image string       `json:"Image"`
+	State string       `json:"State"`
+	Ports []dockerPort `json:"Ports"`
+}
+
+// dockerPort is a port of a container and, if published, the host port it is reachable on
+type dockerPort struct {
+	PrivatePort int    `json:"PrivatePort"`
+	PublicPort  int    `json:"PublicPort,omitempty"`
+	Type        string `json:"Type"`
+}
+
+// dockerContainerInspect is the subset of GET /containers/{id}/json used by the adapter
+type dockerContainerInspect struct {
+	ID    string `json:"Id"`
+	Name  string `json:"Name"`
+	State struct {
+		Status string `json:"Status"`
+	} `json:"State"`
+	Config struct {
+		Image string `json:"Image"`
+	} `json:"Config"`
+	HostConfig struct {
+		RestartPolicy struct {
+			Name string `json:"Name"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+}
+
+// dockerErrorResponse is the error body returned by the Engine API
+type dockerErrorResponse struct {
+	Message string `json:"message"`
+}
+
+// DockerAdapter implements OSServiceAdapter for containers managed by the Docker Engine API
+type DockerAdapter struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewDockerAdapter creates a Docker adapter for the given engine host.
+// The host uses DOCKER_HOST syntax (unix://, npipe:// or tcp://); an empty
+// host falls back to DOCKER_HOST and then to the platform default.
+func NewDockerAdapter(host string) (*DockerAdapter, error) {
+	if host == "" {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if host == "" {
+		host = defaultDockerHost
+	}
+
+	hostURL, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid docker host %q: %w", host, err)
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:    2,
+		IdleConnTimeout: 30 * time.Second,
+	}
+	baseURL := "http://docker"
+
+	switch hostURL.Scheme {
+	case "unix":
+		socketPath := hostURL.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socketPath)
+		}
+	case "npipe":
+		pipePath := strings.ReplaceAll(hostURL.Path, "/", `\`)
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialNamedPipe(ctx, pipePath)
+		}
+	case "tcp", "http":
+		baseURL = "http://" + hostURL.Host
+	default:
+		return nil, fmt.Errorf("unsupported docker host scheme %q", hostURL.Scheme)
+	}
+
+	return &DockerAdapter{
+		// Requests are bounded by their context, so a stop may wait as long as configured
+		client:  &http.Client{Transport: transport},
+		baseURL: baseURL,
+	}, nil
+}
+
+// do sends a request to the Engine API, encoding body as JSON unless it is nil, and decodes
+// a JSON response into out
+func (d *DockerAdapter) do(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, dockerRequestTimeout)
+		defer cancel()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, d.baseURL+path, reqBody)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var apiErr dockerErrorResponse
+		errBody, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(errBody, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(errBody))
+		}
+		return resp.StatusCode, fmt.Errorf("%s", apiErr.Message)
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to decode engine response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// toServiceError converts an Engine API failure into a ServiceError
+func (d *DockerAdapter) toServiceError(statusCode int, err error, name, action string) error {
+	switch statusCode {
+	case 0:
+		// The Docker socket only admits root and members of the docker group
+		if errors.Is(err, os.ErrPermission) {
+			return &ServiceError{
+				Code:    ErrPermissionDenied,
+				Message: fmt.Sprintf("Permission denied while connecting to Docker Engine: %v", err),
+				Service: name,
+			}
+		}
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to connect to Docker Engine: %v", err),
+			Service: name,
+		}
+	case http.StatusNotFound:
+		return &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("Container not found: %s", name),
+			Service: name,
+		}
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return &ServiceError{
+			Code:    ErrPermissionDenied,
+			Message: fmt.Sprintf("Permission denied while trying to %s: %v", action, err),
+			Service: name,
+		}
+	default:
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to %s: %v", action, err),
+			Service: name,
+		}
+	}
+}
+
+// ListServices retrieves all containers, including stopped ones
+func (d *DockerAdapter) ListServices() ([]OSService, error) {
+	var containers []dockerContainerSummary
+	if statusCode, err := d.do(context.Background(), http.MethodGet, "/containers/json?all=1", nil, &containers); err != nil {
+		return nil, d.toServiceError(statusCode, err, "", "list containers")
+	}
+
+	services := make([]OSService, 0, len(containers))
+	for i := range containers {
+		container := &containers[i]
+
+		name := container.ID
+		if len(container.Names) > 0 {
+			name = strings.TrimPrefix(container.Names[0], "/")
+		}
+
+		services = append(services, OSService{
+			Name:           name,
+			DisplayName:    fmt.Sprintf("%s (%s)", name, container.Image),
+			Status:         mapDockerStateToStatus(container.State),
+			Image:          container.Image,
+			CanPause:       container.State == "running" || container.State == "paused",
+			StartupTypes:   dockerStartupTypes,
+			PublishedPorts: publishedPorts(container.Ports),
+		})
+	}
+
+	return services, nil
+}
+
+// publishedPorts maps the published TCP ports of a container to their host ports
+func publishedPorts(ports []dockerPort) map[int]int {
+	var published map[int]int
+	for _, port := range ports {
+		if port.Type != "tcp" || port.PublicPort == 0 {
+			continue
+		}
+		if published == nil {
+			published = make(map[int]int)
+		}
+		published[port.PrivatePort] = port.PublicPort
+	}
+	return published
+}
+
+// inspect retrieves the current state of a container
+func (d *DockerAdapter) inspect(name string) (*dockerContainerInspect, error) {
+	var container dockerContainerInspect
+	if statusCode, err := d.do(context.Background(), http.MethodGet, "/containers/"+url.PathEscape(name)+"/json", nil, &container); err != nil {
+		return nil, d.toServiceError(statusCode, err, name, "inspect container")
+	}
+	return &container, nil
+}
+
+// GetServiceStatus retrieves the current status of a container
+func (d *DockerAdapter) GetServiceStatus(name string) (ServiceStatus, error) {
+	container, err := d.inspect(name)
+	if err != nil {
+		return StatusStopped, err
+	}
+	return mapDockerStateToStatus(container.State.Status), nil
+}
+
+// mapDockerStateToStatus converts a container state to our ServiceStatus enum
+func mapDockerStateToStatus(state string) ServiceStatus {
+	switch state {
+	case "running":
+		return StatusRunning
+	case "restarting":
+		return StatusRestarting
+	case "removing":
+		return StatusStopping
+	case "paused":
+		return StatusPaused
+	case "created", "exited", "dead":
+		return StatusStopped
+	default:
+		return StatusStopped
+	}
+}
+
+// GetStartupType derives the startup type from the container's restart policy
+func (d *DockerAdapter) GetStartupType(name string) (StartupType, error) {
+	container, err := d.inspect(name)
+	if err != nil {
+		return StartupManual, err
+	}
+
+	switch container.HostConfig.RestartPolicy.Name {
+	case "always", "unless-stopped":
+		return StartupAutomatic, nil
+	default:
+		return StartupManual, nil
+	}
+}
+
+// containerAction posts a lifecycle action to the Engine API. Canceling ctx abandons the
+// request; the engine still completes an action it has already begun.
+func (d *DockerAdapter) containerAction(ctx context.Context, name, action, verb string) error {
+	statusCode, err := d.do(ctx, http.MethodPost, "/containers/"+url.PathEscape(name)+"/"+action, nil, nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return contextError(ctx, name)
+		}
+		return d.toServiceError(statusCode, err, name, verb)
+	}
+	return nil
+}
+
+// StartService starts a container
+func (d *DockerAdapter) StartService(ctx context.Context, name string) error {
+	container, err := d.inspect(name)
+	if err != nil {
+		return err
+	}
+
+	// Validate state - can't start if already running
+	if container.State.Status == "running" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already running",
+			Service: name,
+		}
+	}
+
+	return d.containerAction(ctx, name, "start", "start container")
+}
+
+// StopService stops a container, letting the engine kill it after its stop timeout
+func (d *DockerAdapter) StopService(ctx context.Context, name string) error {
+	container, err := d.inspect(name)
+	if err != nil {
+		return err
+	}
+
+	// Validate state - can't stop if already stopped
+	if mapDockerStateToStatus(container.State.Status) == StatusStopped {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already stopped",
+			Service: name,
+		}
+	}
+
+	return d.containerAction(ctx, name, "stop", "stop container")
+}
+
+// RestartService restarts a container, starting it if it is not running
+func (d *DockerAdapter) RestartService(ctx context.Context, name string) error {
+	return d.containerAction(ctx, name, "restart", "restart container")
+}
+
+// PauseService freezes the processes of a running container
+func (d *DockerAdapter) PauseService(ctx context.Context, name string) error {
+	container, err := d.inspect(name)
+	if err != nil {
+		return err
+	}
+	if container.State.Status != "running" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Container is %s, only running containers can be paused", container.State.Status),
+			Service: name,
+		}
+	}
+
+	return d.containerAction(ctx, name, "pause", "pause container")
+}
+
+// ContinueService unfreezes the processes of a paused container
+func (d *DockerAdapter) ContinueService(ctx context.Context, name string) error {
+	container, err := d.inspect(name)
+	if err != nil {
+		return err
+	}
+	if container.State.Status != "paused" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Container is not paused",
+			Service: name,
+		}
+	}
+
+	return d.containerAction(ctx, name, "unpause", "unpause container")
+}
+
+// dockerContainerUpdate is the body of POST /containers/{id}/update used to change the restart policy
+type dockerContainerUpdate struct {
+	RestartPolicy struct {
+		Name string `json:"Name"`
+	} `json:"RestartPolicy"`
+}
+
+// dockerStartupTypes are the startup types a container's restart policy can represent
+var dockerStartupTypes = []StartupType{StartupAutomatic, StartupManual}
+
+// SetStartupType changes the restart policy of a container. Automatic restarts the container
+// with the engine unless it was stopped explicitly, manual never restarts it. Containers cannot
+// be disabled or delayed.
+func (d *DockerAdapter) SetStartupType(name string, startupType StartupType) error {
+	var update dockerContainerUpdate
+	switch startupType {
+	case StartupAutomatic:
+		update.RestartPolicy.Name = "unless-stopped"
+	case StartupManual:
+		update.RestartPolicy.Name = "no"
+	default:
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Containers cannot use the %s startup type", startupType),
+			Service: name,
+		}
+	}
+
+	// Keep an "always" policy, which is automatic as well
+	current, err := d.GetStartupType(name)
+	if err != nil {
+		return err
+	}
+	if current == startupType {
+		return nil
+	}
+
+	if statusCode, err := d.do(context.Background(), http.MethodPost, "/containers/"+url.PathEscape(name)+"/update", &update, nil); err != nil {
+		return d.toServiceError(statusCode, err, name, "update restart policy")
+	}
+	return nil
+}
+
+// GetDependencies returns no dependencies; Docker does not enforce an order between containers
+func (d *DockerAdapter) GetDependencies(name string) ([]string, error) {
+	if _, err := d.inspect(name); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
+
+// GetDependents returns no dependents; stopping a container never stops another one
+func (d *DockerAdapter) GetDependents(name string) ([]string, error) {
+	if _, err := d.inspect(name); err != nil {
+		return nil, err
+	}
+	return []string{}, nil
+}
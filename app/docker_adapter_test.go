@@ -0,0 +1,444 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+// fakeDockerEngine is a minimal in-memory implementation of the Engine API
+type fakeDockerEngine struct {
+	mu         sync.Mutex
+	containers map[string]*dockerContainerInspect
+	ports      map[string][]dockerPort
+	order      []string
+	actions    []string
+}
+
+func newFakeDockerEngine() *fakeDockerEngine {
+	engine := &fakeDockerEngine{containers: make(map[string]*dockerContainerInspect)}
+	engine.add("billing-db", "postgres:16", "running", "unless-stopped")
+	engine.add("cache", "redis", "exited", "no")
+	engine.add("docs", "mongo:7", "paused", "")
+	engine.add("web", "nginx:latest", "running", "always")
+	engine.ports = map[string][]dockerPort{
+		"billing-db": {{PrivatePort: 5432, PublicPort: 15432, Type: "tcp"}},
+		"web":        {{PrivatePort: 80, PublicPort: 8080, Type: "tcp"}, {PrivatePort: 443, Type: "tcp"}, {PrivatePort: 53, PublicPort: 5353, Type: "udp"}},
+	}
+	return engine
+}
+
+func (e *fakeDockerEngine) add(name, image, state, restartPolicy string) {
+	container := &dockerContainerInspect{ID: name + "-id", Name: "/" + name}
+	container.State.Status = state
+	container.Config.Image = image
+	container.HostConfig.RestartPolicy.Name = restartPolicy
+	e.containers[name] = container
+	e.order = append(e.order, name)
+}
+
+func (e *fakeDockerEngine) writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(dockerErrorResponse{Message: message})
+}
+
+func (e *fakeDockerEngine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if r.Method == http.MethodGet && r.URL.Path == "/containers/json" {
+		if r.URL.Query().Get("all") != "1" {
+			e.writeError(w, http.StatusBadRequest, "expected all=1")
+			return
+		}
+		summaries := make([]dockerContainerSummary, 0, len(e.order))
+		for _, name := range e.order {
+			c := e.containers[name]
+			summaries = append(summaries, dockerContainerSummary{
+				ID:    c.ID,
+				Names: []string{c.Name},
+				Image: c.Config.Image,
+				State: c.State.Status,
+				Ports: e.ports[name],
+			})
+		}
+		json.NewEncoder(w).Encode(summaries)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/containers/"), "/")
+	if len(parts) != 2 {
+		e.writeError(w, http.StatusNotFound, "page not found")
+		return
+	}
+
+	container, exists := e.containers[parts[0]]
+	if !exists {
+		e.writeError(w, http.StatusNotFound, "No such container: "+parts[0])
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && parts[1] == "json":
+		json.NewEncoder(w).Encode(container)
+	case r.Method == http.MethodPost && parts[1] == "start":
+		if container.State.Status == "running" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		container.State.Status = "running"
+		e.actions = append(e.actions, "start "+parts[0])
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && parts[1] == "stop":
+		if container.State.Status != "running" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		container.State.Status = "exited"
+		e.actions = append(e.actions, "stop "+parts[0])
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && parts[1] == "restart":
+		container.State.Status = "running"
+		e.actions = append(e.actions, "restart "+parts[0])
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && parts[1] == "update":
+		var update dockerContainerUpdate
+		if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+			e.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		container.HostConfig.RestartPolicy.Name = update.RestartPolicy.Name
+		e.actions = append(e.actions, "update "+parts[0]+" restart="+update.RestartPolicy.Name)
+		json.NewEncoder(w).Encode(map[string][]string{"Warnings": {}})
+	case r.Method == http.MethodPost && (parts[1] == "pause" || parts[1] == "unpause"):
+		from, to := "running", "paused"
+		if parts[1] == "unpause" {
+			from, to = "paused", "running"
+		}
+		if container.State.Status != from {
+			e.writeError(w, http.StatusConflict, "Container "+parts[0]+" is not "+from)
+			return
+		}
+		container.State.Status = to
+		e.actions = append(e.actions, parts[1]+" "+parts[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		e.writeError(w, http.StatusNotFound, "page not found")
+	}
+}
+
+func createTestDockerAdapter(t *testing.T) (*DockerAdapter, *fakeDockerEngine) {
+	engine := newFakeDockerEngine()
+	server := httptest.NewServer(engine)
+	t.Cleanup(server.Close)
+
+	adapter, err := NewDockerAdapter("tcp://" + server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("NewDockerAdapter() failed: %v", err)
+	}
+	return adapter, engine
+}
+
+func TestDockerAdapterListServices(t *testing.T) {
+	adapter, _ := createTestDockerAdapter(t)
+
+	services, err := adapter.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() failed: %v", err)
+	}
+
+	expected := map[string]ServiceStatus{
+		"billing-db": StatusRunning,
+		"cache":      StatusStopped,
+		"docs":       StatusPaused,
+		"web":        StatusRunning,
+	}
+
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d containers, got %d", len(expected), len(services))
+	}
+
+	for _, service := range services {
+		status, exists := expected[service.Name]
+		if !exists {
+			t.Errorf("Unexpected container %s", service.Name)
+			continue
+		}
+		if service.Status != status {
+			t.Errorf("Container %s: expected status %s, got %s", service.Name, status, service.Status)
+		}
+		if service.Image == "" {
+			t.Errorf("Container %s should report its image", service.Name)
+		}
+	}
+
+	// Only published TCP ports are reported
+	published := make(map[string]map[int]int)
+	for _, service := range services {
+		published[service.Name] = service.PublishedPorts
+	}
+	if port := published["billing-db"][5432]; port != 15432 {
+		t.Errorf("Expected billing-db to publish 5432 on 15432, got %d", port)
+	}
+	if ports := published["web"]; len(ports) != 1 || ports[80] != 8080 {
+		t.Errorf("Expected web to publish only port 80, got %v", ports)
+	}
+	if ports := published["cache"]; ports != nil {
+		t.Errorf("Expected cache to publish no ports, got %v", ports)
+	}
+}
+
+func TestDockerAdapterDetectionByImage(t *testing.T) {
+	adapter, _ := createTestDockerAdapter(t)
+	detector := NewWindowsServiceDetector(adapter)
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+
+	expected := map[string]ServiceType{
+		"billing-db": TypePostgreSQL,
+		"cache":      TypeRedis,
+		"docs":       TypeMongoDB,
+	}
+
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d detected services, got %d: %+v", len(expected), len(services), services)
+	}
+
+	for _, service := range services {
+		if service.Type != expected[service.Name] {
+			t.Errorf("Container %s: expected type %s, got %s", service.Name, expected[service.Name], service.Type)
+		}
+	}
+}
+
+func TestDockerAdapterStartupType(t *testing.T) {
+	adapter, _ := createTestDockerAdapter(t)
+
+	tests := []struct {
+		name     string
+		expected StartupType
+	}{
+		{"billing-db", StartupAutomatic},
+		{"web", StartupAutomatic},
+		{"cache", StartupManual},
+		{"docs", StartupManual},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			startupType, err := adapter.GetStartupType(tt.name)
+			if err != nil {
+				t.Fatalf("GetStartupType() failed: %v", err)
+			}
+			if startupType != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, startupType)
+			}
+		})
+	}
+}
+
+func TestDockerAdapterSetStartupType(t *testing.T) {
+	adapter, engine := createTestDockerAdapter(t)
+
+	// web already restarts with the engine, so its "always" policy is kept
+	for _, name := range []string{"cache", "web"} {
+		if err := adapter.SetStartupType(name, StartupAutomatic); err != nil {
+			t.Fatalf("SetStartupType(%s, automatic) failed: %v", name, err)
+		}
+	}
+	if err := adapter.SetStartupType("billing-db", StartupManual); err != nil {
+		t.Fatalf("SetStartupType(billing-db, manual) failed: %v", err)
+	}
+
+	expectedActions := []string{"update cache restart=unless-stopped", "update billing-db restart=no"}
+	if strings.Join(engine.actions, ",") != strings.Join(expectedActions, ",") {
+		t.Errorf("Expected actions %v, got %v", expectedActions, engine.actions)
+	}
+	if startupType, _ := adapter.GetStartupType("billing-db"); startupType != StartupManual {
+		t.Errorf("Expected billing-db to be manual, got %s", startupType)
+	}
+
+	// Containers report the startup types their restart policy can represent
+	services, err := adapter.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() failed: %v", err)
+	}
+	for _, service := range services {
+		if len(service.StartupTypes) != 2 || service.StartupTypes[0] != StartupAutomatic || service.StartupTypes[1] != StartupManual {
+			t.Errorf("Container %s: expected automatic and manual startup types, got %v", service.Name, service.StartupTypes)
+		}
+	}
+}
+
+func TestDockerAdapterLifecycle(t *testing.T) {
+	adapter, engine := createTestDockerAdapter(t)
+
+	if err := adapter.StartService(context.Background(), "cache"); err != nil {
+		t.Fatalf("StartService() failed: %v", err)
+	}
+
+	status, err := adapter.GetServiceStatus("cache")
+	if err != nil {
+		t.Fatalf("GetServiceStatus() failed: %v", err)
+	}
+	if status != StatusRunning {
+		t.Errorf("Expected cache to be running, got %s", status)
+	}
+
+	if err := adapter.RestartService(context.Background(), "cache"); err != nil {
+		t.Fatalf("RestartService() failed: %v", err)
+	}
+
+	if err := adapter.StopService(context.Background(), "cache"); err != nil {
+		t.Fatalf("StopService() failed: %v", err)
+	}
+
+	expectedActions := []string{"start cache", "restart cache", "stop cache"}
+	if strings.Join(engine.actions, ",") != strings.Join(expectedActions, ",") {
+		t.Errorf("Expected actions %v, got %v", expectedActions, engine.actions)
+	}
+}
+
+func TestDockerAdapterPauseContinue(t *testing.T) {
+	adapter, engine := createTestDockerAdapter(t)
+
+	if err := adapter.PauseService(context.Background(), "billing-db"); err != nil {
+		t.Fatalf("PauseService() failed: %v", err)
+	}
+	if status, _ := adapter.GetServiceStatus("billing-db"); status != StatusPaused {
+		t.Errorf("Expected billing-db to be paused, got %s", status)
+	}
+
+	// Pausing twice or continuing a stopped container is rejected before reaching the engine
+	for _, tc := range []struct {
+		name string
+		op   func(context.Context, string) error
+	}{
+		{"billing-db", adapter.PauseService},
+		{"cache", adapter.ContinueService},
+	} {
+		err := tc.op(context.Background(), tc.name)
+		if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrInvalidState {
+			t.Errorf("Expected ErrInvalidState for %s, got %v", tc.name, err)
+		}
+	}
+
+	if err := adapter.ContinueService(context.Background(), "billing-db"); err != nil {
+		t.Fatalf("ContinueService() failed: %v", err)
+	}
+	if status, _ := adapter.GetServiceStatus("billing-db"); status != StatusRunning {
+		t.Errorf("Expected billing-db to be running, got %s", status)
+	}
+
+	expectedActions := []string{"pause billing-db", "unpause billing-db"}
+	if strings.Join(engine.actions, ",") != strings.Join(expectedActions, ",") {
+		t.Errorf("Expected actions %v, got %v", expectedActions, engine.actions)
+	}
+
+	services, err := adapter.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() failed: %v", err)
+	}
+	for _, service := range services {
+		if expected := service.Name != "cache"; service.CanPause != expected {
+			t.Errorf("Container %s: expected CanPause %v, got %v", service.Name, expected, service.CanPause)
+		}
+	}
+}
+
+func TestDockerAdapterErrors(t *testing.T) {
+	adapter, _ := createTestDockerAdapter(t)
+
+	tests := []struct {
+		name     string
+		op       func() error
+		expected ErrorCode
+	}{
+		{"start running container", func() error { return adapter.StartService(context.Background(), "billing-db") }, ErrInvalidState},
+		{"stop exited container", func() error { return adapter.StopService(context.Background(), "cache") }, ErrInvalidState},
+		{"start missing container", func() error { return adapter.StartService(context.Background(), "missing") }, ErrServiceNotFound},
+		{"restart missing container", func() error { return adapter.RestartService(context.Background(), "missing") }, ErrServiceNotFound},
+		{"disable container", func() error { return adapter.SetStartupType("cache", StartupDisabled) }, ErrInvalidState},
+		{"delay container", func() error { return adapter.SetStartupType("cache", StartupAutomaticDelayed) }, ErrInvalidState},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.op()
+			serviceErr, ok := err.(*ServiceError)
+			if !ok {
+				t.Fatalf("Expected *ServiceError, got %T (%v)", err, err)
+			}
+			if serviceErr.Code != tt.expected {
+				t.Errorf("Expected error code %d, got %d (%v)", tt.expected, serviceErr.Code, serviceErr)
+			}
+		})
+	}
+
+	// Users outside the docker group may not connect to the socket
+	denied := &net.OpError{Op: "dial", Net: "unix", Err: os.NewSyscallError("connect", syscall.EACCES)}
+	if err, ok := adapter.toServiceError(0, denied, "", "list containers").(*ServiceError); !ok || err.Code != ErrPermissionDenied {
+		t.Errorf("Expected ErrPermissionDenied for a denied socket, got %v", err)
+	}
+}
+
+func TestDockerAdapterUnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are tested on non-Windows platforms")
+	}
+
+	socketPath := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Failed to listen on unix socket: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(newFakeDockerEngine())
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	adapter, err := NewDockerAdapter("unix://" + socketPath)
+	if err != nil {
+		t.Fatalf("NewDockerAdapter() failed: %v", err)
+	}
+
+	services, err := adapter.ListServices()
+	if err != nil {
+		t.Fatalf("ListServices() over unix socket failed: %v", err)
+	}
+	if len(services) != 4 {
+		t.Errorf("Expected 4 containers, got %d", len(services))
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := map[string]string{
+		"postgres:16":               "postgres",
+		"redis":                     "redis",
+		"docker.io/library/mongo:7": "docker.io/library/mongo",
+		"localhost:5000/mysql":      "localhost:5000/mysql",
+		"localhost:5000/mysql:8.4":  "localhost:5000/mysql",
+		"mcr.microsoft.com/mssql/server:2022-latest": "mcr.microsoft.com/mssql/server",
+		"redis@sha256:0123abcd":                      "redis",
+	}
+
+	for image, expected := range tests {
+		if repository := imageRepository(image); repository != expected {
+			t.Errorf("imageRepository(%q) = %q, expected %q", image, repository, expected)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// defaultDockerHost is the Engine API socket used when DOCKER_HOST is not set
+const defaultDockerHost = "unix:///var/run/docker.sock"
+
+// dialNamedPipe is only available on Windows
+func dialNamedPipe(ctx context.Context, path string) (net.Conn, error) {
+	return nil, fmt.Errorf("named pipes are not supported on this platform: %s", path)
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// defaultDockerHost is the Engine API pipe used when DOCKER_HOST is not set
+const defaultDockerHost = "npipe:////./pipe/docker_engine"
+
+// namedPipeAddr implements net.Addr for a named pipe path
+type namedPipeAddr string
+
+func (a namedPipeAddr) Network() string { return "npipe" }
+func (a namedPipeAddr) String() string  { return string(a) }
+
+// namedPipeConn is a minimal net.Conn over an overlapped named pipe handle.
+// Overlapped I/O lets the HTTP transport read and write concurrently.
+type namedPipeConn struct {
+	handle windows.Handle
+	path   string
+}
+
+// dialNamedPipe opens a client connection to a named pipe, retrying while the pipe is busy
+func dialNamedPipe(ctx context.Context, path string) (net.Conn, error) {
+	name, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		handle, err := windows.CreateFile(
+			name,
+			windows.GENERIC_READ|windows.GENERIC_WRITE,
+			0,
+			nil,
+			windows.OPEN_EXISTING,
+			windows.FILE_FLAG_OVERLAPPED,
+			0,
+		)
+		if err == nil {
+			return &namedPipeConn{handle: handle, path: path}, nil
+		}
+		if err != windows.ERROR_PIPE_BUSY {
+			return nil, &net.OpError{Op: "dial", Net: "npipe", Addr: namedPipeAddr(path), Err: err}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// overlappedIO runs a single overlapped operation and waits for it to complete
+func (c *namedPipeConn) overlappedIO(op func(*windows.Overlapped) error) (int, error) {
+	event, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer windows.CloseHandle(event)
+
+	overlapped := &windows.Overlapped{HEvent: event}
+	if err := op(overlapped); err != nil && err != windows.ERROR_IO_PENDING {
+		return 0, err
+	}
+
+	var transferred uint32
+	if err := windows.GetOverlappedResult(c.handle, overlapped, &transferred, true); err != nil {
+		return int(transferred), err
+	}
+	return int(transferred), nil
+}
+
+func (c *namedPipeConn) Read(b []byte) (int, error) {
+	n, err := c.overlappedIO(func(o *windows.Overlapped) error {
+		return windows.ReadFile(c.handle, b, nil, o)
+	})
+	if err == windows.ERROR_BROKEN_PIPE || err == windows.ERROR_OPERATION_ABORTED {
+		return n, io.EOF
+	}
+	return n, err
+}
+
+func (c *namedPipeConn) Write(b []byte) (int, error) {
+	return c.overlappedIO(func(o *windows.Overlapped) error {
+		return windows.WriteFile(c.handle, b, nil, o)
+	})
+}
+
+func (c *namedPipeConn) Close() error {
+	// Cancel pending reads so the transport's read loop can exit
+	windows.CancelIoEx(c.handle, nil)
+	return windows.CloseHandle(c.handle)
+}
+
+func (c *namedPipeConn) LocalAddr() net.Addr  { return namedPipeAddr(c.path) }
+func (c *namedPipeConn) RemoteAddr() net.Addr { return namedPipeAddr(c.path) }
+
+// Deadlines are not supported; the HTTP client timeout bounds each request
+func (c *namedPipeConn) SetDeadline(t time.Time) error      { return nil }
+func (c *namedPipeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *namedPipeConn) SetWriteDeadline(t time.Time) error { return nil }
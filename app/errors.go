@@ -1,6 +1,10 @@
 package app
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+)
 
 // ErrorCode represents specific error types for service operations
 type ErrorCode int
@@ -11,8 +15,29 @@ const (
 	ErrOperationTimeout
 	ErrInvalidState
 	ErrSystemError
+	ErrNotReady          // The service started but did not pass its readiness probe in time
+	ErrOperationCanceled // The operation was canceled before it completed
 )
 
+// errorCodeNames are stable identifiers used in metrics and logs
+var errorCodeNames = map[ErrorCode]string{
+	ErrPermissionDenied:  "permission_denied",
+	ErrServiceNotFound:   "service_not_found",
+	ErrOperationTimeout:  "operation_timeout",
+	ErrInvalidState:      "invalid_state",
+	ErrSystemError:       "system_error",
+	ErrNotReady:          "not_ready",
+	ErrOperationCanceled: "operation_canceled",
+}
+
+// String returns the stable identifier of an error code
+func (c ErrorCode) String() string {
+	if name, exists := errorCodeNames[c]; exists {
+		return name
+	}
+	return fmt.Sprintf("error_%d", int(c))
+}
+
 // ServiceError represents an error that occurred during a service operation
 type ServiceError struct {
 	Code    ErrorCode
@@ -27,3 +52,32 @@ func (e *ServiceError) Error() string {
 	}
 	return e.Message
 }
+
+// withErrorDetail appends a detail in parentheses to the message of an operation error,
+// keeping its code. Other errors become system errors of the service.
+func withErrorDetail(err error, name, detail string) error {
+	serviceErr := &ServiceError{Code: ErrSystemError, Message: err.Error(), Service: name}
+	var original *ServiceError
+	if errors.As(err, &original) {
+		serviceErr.Code, serviceErr.Message = original.Code, original.Message
+	}
+	serviceErr.Message = fmt.Sprintf("%s (%s)", serviceErr.Message, detail)
+	return serviceErr
+}
+
+// contextError returns the error of an operation that ended because its context did:
+// a timeout if its deadline passed, otherwise a cancellation
+func contextError(ctx context.Context, name string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &ServiceError{
+			Code:    ErrOperationTimeout,
+			Message: "Operation timed out",
+			Service: name,
+		}
+	}
+	return &ServiceError{
+		Code:    ErrOperationCanceled,
+		Message: "Operation was canceled",
+		Service: name,
+	}
+}
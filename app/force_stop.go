@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// forceStopSettleTimeout is how long the service manager may take to notice that the
+	// terminated process is gone
+	forceStopSettleTimeout = 10 * time.Second
+	// statusWaitInterval is the delay between two status queries while waiting for a service
+	statusWaitInterval = 250 * time.Millisecond
+)
+
+// ForceStopResult describes the outcome of a force stop
+type ForceStopResult struct {
+	Service        string   `json:"Service"`
+	Forced         bool     `json:"Forced"`                   // The process tree was terminated because the graceful stop did not finish
+	PID            uint32   `json:"PID,omitempty"`            // Main process of the service, if it was terminated
+	Terminated     []uint32 `json:"Terminated,omitempty"`     // Terminated processes, the main process first
+	GracefulError  string   `json:"GracefulError,omitempty"`  // Why the graceful stop did not finish
+	GracePeriodSec int      `json:"GracePeriodSec,omitempty"` // How long the graceful stop was given
+}
+
+// ForceStopService stops a database service on behalf of the GUI, terminating its processes
+// if it does not stop within its operation timeout. confirmed must be true.
+func (sm *ServiceManager) ForceStopService(name string, confirmed bool) (*ForceStopResult, error) {
+	return sm.control(InitiatorGUI).ForceStopService(name, confirmed)
+}
+
+// ForceStopService stops a service gracefully and, if it is still stopping once its operation
+// timeout has passed, terminates its process tree. Terminating a process can lose data, so the
+// caller must confirm it explicitly. The outcome is recorded as a "force-stop" operation.
+func (c *ServiceControl) ForceStopService(name string, confirmed bool) (*ForceStopResult, error) {
+	if !confirmed {
+		return nil, &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Force stop terminates the service's processes and must be confirmed",
+			Service: name,
+		}
+	}
+
+	result := &ForceStopResult{Service: name}
+	err := c.run(c.ctx, "force-stop", name, func(ctx context.Context, name string) error {
+		return c.forceStop(ctx, name, result)
+	})
+	return result, err
+}
+
+// forceStop performs the force stop operation, filling in result as it goes
+func (c *ServiceControl) forceStop(ctx context.Context, name string, result *ForceStopResult) error {
+	sm := c.manager
+	if err := sm.RequireElevationForOperation(); err != nil {
+		return err
+	}
+
+	status, err := sm.adapter.GetServiceStatus(name)
+	if err != nil {
+		return err
+	}
+	if status == StatusStopped {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already stopped",
+			Service: name,
+		}
+	}
+
+	grace := sm.operationTimeout(name)
+	result.GracePeriodSec = int(grace / time.Second)
+
+	// A service stuck stopping already got its stop request; give it the grace period to finish
+	if status == StatusStopping {
+		err = sm.withOperationTimeout(ctx, name, func(ctx context.Context) error {
+			return sm.waitForStatus(ctx, name, StatusStopped)
+		})
+	} else {
+		if _, err := c.stopDependents(ctx, name); err != nil {
+			return err
+		}
+		err = sm.stopService(ctx, name)
+	}
+
+	var serviceErr *ServiceError
+	if err == nil || !errors.As(err, &serviceErr) || serviceErr.Code != ErrOperationTimeout {
+		return err
+	}
+	result.GracefulError = err.Error()
+
+	if err := sm.terminateServiceProcesses(name, result); err != nil {
+		return err
+	}
+
+	// The service manager marks the service stopped once it notices its process is gone
+	settleCtx, cancel := context.WithTimeout(ctx, forceStopSettleTimeout)
+	defer cancel()
+	if err := sm.waitForStatus(settleCtx, name, StatusStopped); err != nil {
+		return withErrorDetail(err, name, fmt.Sprintf("terminated processes %v, but the service has not stopped", result.Terminated))
+	}
+
+	sm.statusWatcher.Refresh(name)
+	return nil
+}
+
+// terminateServiceProcesses terminates the main process of a service and its descendants.
+// Processes hosting other running services as well are left alone.
+func (sm *ServiceManager) terminateServiceProcesses(name string, result *ForceStopResult) error {
+	services, err := sm.adapter.ListServices()
+	if err != nil {
+		return err
+	}
+
+	var pid uint32
+	var sharedWith []string
+	for _, service := range services {
+		if service.Name == name {
+			pid = service.PID
+		}
+	}
+	for _, service := range services {
+		if service.Name != name && pid != 0 && service.PID == pid {
+			sharedWith = append(sharedWith, service.Name)
+		}
+	}
+
+	if pid == 0 {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: "Cannot force stop: the service's process is unknown",
+			Service: name,
+		}
+	}
+	if len(sharedWith) > 0 {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Cannot force stop: process %d also hosts %v", pid, sharedWith),
+			Service: name,
+		}
+	}
+
+	result.Forced, result.PID = true, pid
+	for _, target := range sm.processTree(pid) {
+		if err := sm.terminateProcess(target); err != nil {
+			return &ServiceError{
+				Code:    ErrSystemError,
+				Message: fmt.Sprintf("Failed to terminate process %d: %v", target, err),
+				Service: name,
+			}
+		}
+		result.Terminated = append(result.Terminated, target)
+	}
+	return nil
+}
+
+// processTree returns a process followed by its descendants in ascending PID order. The root
+// comes first so that it cannot replace the children terminated after it.
+func (sm *ServiceManager) processTree(pid uint32) []uint32 {
+	tree := []uint32{pid}
+	if sm.ports == nil {
+		return tree
+	}
+
+	processes, err := sm.ports.listProcesses()
+	if err != nil {
+		// Without the process table only the main process can be terminated
+		return tree
+	}
+
+	snapshot := &portSnapshot{processes: processes}
+	var descendants []uint32
+	for candidate := range processes {
+		if candidate != pid && snapshot.inProcessTree(candidate, pid) {
+			descendants = append(descendants, candidate)
+		}
+	}
+	sort.Slice(descendants, func(i, j int) bool { return descendants[i] < descendants[j] })
+	return append(tree, descendants...)
+}
+
+// waitForStatus polls a service until it reaches a status or ctx ends
+func (sm *ServiceManager) waitForStatus(ctx context.Context, name string, target ServiceStatus) error {
+	ticker := time.NewTicker(statusWaitInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := sm.adapter.GetServiceStatus(name)
+		if err != nil {
+			return err
+		}
+		if status == target {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return contextError(ctx, name)
+		case <-ticker.C:
+		}
+	}
+}
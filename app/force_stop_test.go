@@ -0,0 +1,104 @@
+package app
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// createForceStopManager returns a manager for PostgreSQL running as pg_ctl (100) with the
+// postgres processes 200 and 201, whose stop hangs until released
+func createForceStopManager(t *testing.T) (*ServiceManager, *fakeServiceAdapter, *[]uint32) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "postgresql-x64-16", Status: StatusRunning, PID: 100},
+		OSService{Name: "Memurai", Status: StatusRunning, PID: 300},
+	)
+	adapter.pending["stop postgresql-x64-16"] = make(chan struct{})
+
+	sm := createTestServiceManager(t, adapter)
+	sm.ports = newFakePortScanner(nil, postgresProcessTree)
+
+	timeouts := OperationTimeoutConfig{Services: map[string]int{"postgresql-x64-16": 1}}
+	if err := sm.configManager.SetOperationTimeouts(timeouts); err != nil {
+		t.Fatalf("SetOperationTimeouts() failed: %v", err)
+	}
+
+	var mu sync.Mutex
+	var terminated []uint32
+	sm.terminateProcess = func(pid uint32) error {
+		mu.Lock()
+		defer mu.Unlock()
+		terminated = append(terminated, pid)
+		if pid == 100 {
+			adapter.setStatus("postgresql-x64-16", StatusStopped)
+		}
+		return nil
+	}
+	return sm, adapter, &terminated
+}
+
+func TestForceStopRequiresConfirmation(t *testing.T) {
+	sm, adapter, terminated := createForceStopManager(t)
+
+	_, err := sm.ForceStopService("postgresql-x64-16", false)
+	if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrInvalidState {
+		t.Fatalf("Expected ErrInvalidState, got %v", err)
+	}
+	if calls := adapter.callLog(); len(calls) != 0 || len(*terminated) != 0 {
+		t.Errorf("Expected nothing to happen, got calls %v and terminated %v", calls, *terminated)
+	}
+}
+
+func TestForceStopTerminatesHungService(t *testing.T) {
+	sm, _, terminated := createForceStopManager(t)
+
+	result, err := sm.ForceStopService("postgresql-x64-16", true)
+	if err != nil {
+		t.Fatalf("ForceStopService() failed: %v", err)
+	}
+
+	if !result.Forced || result.PID != 100 || result.GracefulError == "" || result.GracePeriodSec != 1 {
+		t.Errorf("Expected a forced stop after 1s, got %+v", result)
+	}
+	// The unrelated process 300 is left alone
+	if expected := []uint32{100, 200, 201}; !reflect.DeepEqual(result.Terminated, expected) || !reflect.DeepEqual(*terminated, expected) {
+		t.Errorf("Expected %v terminated, got %v (%v)", expected, result.Terminated, *terminated)
+	}
+
+	history, err := sm.GetHistory(AuditFilter{Operation: "force-stop"})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || !history[0].Success || history[0].ResultingStatus != StatusStopped {
+		t.Errorf("Expected a successful force-stop audit entry, got %+v", history)
+	}
+}
+
+func TestForceStopWithoutTermination(t *testing.T) {
+	sm, adapter, terminated := createForceStopManager(t)
+	delete(adapter.pending, "stop postgresql-x64-16")
+
+	result, err := sm.ForceStopService("postgresql-x64-16", true)
+	if err != nil {
+		t.Fatalf("ForceStopService() failed: %v", err)
+	}
+	if result.Forced || len(*terminated) != 0 {
+		t.Errorf("Expected a graceful stop, got %+v", result)
+	}
+}
+
+func TestForceStopRefusesSharedProcess(t *testing.T) {
+	sm, adapter, terminated := createForceStopManager(t)
+	adapter.setStatus("postgresql-x64-16", StatusStopping)
+	adapter.mu.Lock()
+	adapter.services["Memurai"].PID = 100
+	adapter.mu.Unlock()
+
+	_, err := sm.ForceStopService("postgresql-x64-16", true)
+	if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrInvalidState {
+		t.Fatalf("Expected ErrInvalidState, got %v", err)
+	}
+	if len(*terminated) != 0 {
+		t.Errorf("Expected no process to be terminated, got %v", *terminated)
+	}
+}
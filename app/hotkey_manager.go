@@ -6,9 +6,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
-	"unsafe"
 )
 
 // Windows API constants for hotkey registration
@@ -45,30 +43,6 @@ const (
 	VK_RIGHT  = 0x27
 )
 
-// Windows API function declarations
-var (
-	user32   = syscall.NewLazyDLL("user32.dll")
-	kernel32 = syscall.NewLazyDLL("kernel32.dll")
-
-	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
-	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
-	procGetMessage         = user32.NewProc("GetMessageW")
-	procPeekMessage        = user32.NewProc("PeekMessageW")
-	procTranslateMessage   = user32.NewProc("TranslateMessage")
-	procDispatchMessage    = user32.NewProc("DispatchMessageW")
-	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
-)
-
-// MSG represents a Windows message structure
-type MSG struct {
-	Hwnd    uintptr
-	Message uint32
-	WParam  uintptr
-	LParam  uintptr
-	Time    uint32
-	Pt      struct{ X, Y int32 }
-}
-
 // HotkeyManager manages global keyboard shortcuts for window restoration
 type HotkeyManager struct {
 	ctx                context.Context
@@ -179,15 +153,8 @@ func (hm *HotkeyManager) RegisterHotkey(combination string) error {
 	hotkeyID := hm.keyIDCounter
 	hm.keyIDCounter++
 
-	// Register the hotkey with Windows
-	ret, _, err := procRegisterHotKey.Call(
-		0,                  // hWnd (0 for current thread)
-		uintptr(hotkeyID),  // id
-		uintptr(modifiers), // fsModifiers
-		uintptr(vkCode),    // vk
-	)
-
-	if ret == 0 {
+	// Register the hotkey with the operating system
+	if err := registerGlobalHotkey(hotkeyID, modifiers, vkCode); err != nil {
 		return fmt.Errorf("failed to register hotkey %s: %w", combination, err)
 	}
 
@@ -212,13 +179,8 @@ func (hm *HotkeyManager) unregisterHotkeyInternal(combination string) error {
 		return fmt.Errorf("hotkey %s is not registered", combination)
 	}
 
-	// Unregister from Windows
-	ret, _, err := procUnregisterHotKey.Call(
-		0,                 // hWnd
-		uintptr(hotkeyID), // id
-	)
-
-	if ret == 0 {
+	// Unregister from the operating system
+	if err := unregisterGlobalHotkey(hotkeyID); err != nil {
 		return fmt.Errorf("failed to unregister hotkey %s: %w", combination, err)
 	}
 
@@ -357,67 +319,6 @@ func (hm *HotkeyManager) keyToVirtualKeyCode(key string) (uint32, error) {
 	}
 }
 
-// startMessageLoop runs the Windows message loop to handle hotkey events
-func (hm *HotkeyManager) startMessageLoop() {
-	hm.mutex.Lock()
-	hm.messageLoopRunning = true
-	hm.mutex.Unlock()
-
-	defer func() {
-		hm.mutex.Lock()
-		hm.messageLoopRunning = false
-		hm.mutex.Unlock()
-
-		// Recover from any panics in the message loop
-		if r := recover(); r != nil {
-			// In a production app, this would go to a proper logger
-			// For now, we just ensure the loop doesn't crash the app
-		}
-	}()
-
-	var msg MSG
-
-	for {
-		select {
-		case <-hm.stopMessageLoop:
-			return
-		case <-hm.ctx.Done():
-			return
-		default:
-			// Use PeekMessage to avoid blocking and allow for clean shutdown
-			ret, _, _ := procPeekMessage.Call(
-				uintptr(unsafe.Pointer(&msg)),
-				0, // hWnd (0 for any window)
-				0, // wMsgFilterMin
-				0, // wMsgFilterMax
-				1, // PM_REMOVE
-			)
-
-			if ret == 0 { // No message available
-				// Proper sleep to prevent busy waiting and reduce CPU usage
-				select {
-				case <-hm.stopMessageLoop:
-					return
-				case <-hm.ctx.Done():
-					return
-				case <-time.After(50 * time.Millisecond): // Sleep for 50ms to reduce CPU usage
-					continue
-				}
-			}
-
-			// Process hotkey messages
-			if msg.Message == WM_HOTKEY {
-				hm.handleHotkeyMessage(int32(msg.WParam))
-			} else {
-				// For non-hotkey messages, still translate and dispatch
-				// This ensures proper Windows message handling
-				procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
-				procDispatchMessage.Call(uintptr(unsafe.Pointer(&msg)))
-			}
-		}
-	}
-}
-
 // handleHotkeyMessage handles a hotkey activation message
 func (hm *HotkeyManager) handleHotkeyMessage(hotkeyID int32) {
 	hm.mutex.RLock()
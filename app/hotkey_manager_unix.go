@@ -0,0 +1,21 @@
+//go:build unix
+
+package app
+
+import "errors"
+
+// errGlobalHotkeysUnsupported is returned when the platform has no global hotkey API
+var errGlobalHotkeysUnsupported = errors.New("global hotkeys are not supported on this platform")
+
+// registerGlobalHotkey is not supported outside Windows
+func registerGlobalHotkey(hotkeyID int32, modifiers, vkCode uint32) error {
+	return errGlobalHotkeysUnsupported
+}
+
+// unregisterGlobalHotkey is not supported outside Windows
+func unregisterGlobalHotkey(hotkeyID int32) error {
+	return errGlobalHotkeysUnsupported
+}
+
+// startMessageLoop has nothing to pump outside Windows
+func (hm *HotkeyManager) startMessageLoop() {}
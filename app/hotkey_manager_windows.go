@@ -0,0 +1,120 @@
+package app
+
+import (
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Windows API function declarations
+var (
+	user32   = syscall.NewLazyDLL("user32.dll")
+	kernel32 = syscall.NewLazyDLL("kernel32.dll")
+
+	procRegisterHotKey     = user32.NewProc("RegisterHotKey")
+	procUnregisterHotKey   = user32.NewProc("UnregisterHotKey")
+	procGetMessage         = user32.NewProc("GetMessageW")
+	procPeekMessage        = user32.NewProc("PeekMessageW")
+	procTranslateMessage   = user32.NewProc("TranslateMessage")
+	procDispatchMessage    = user32.NewProc("DispatchMessageW")
+	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+)
+
+// MSG represents a Windows message structure
+type MSG struct {
+	Hwnd    uintptr
+	Message uint32
+	WParam  uintptr
+	LParam  uintptr
+	Time    uint32
+	Pt      struct{ X, Y int32 }
+}
+
+// registerGlobalHotkey registers a hotkey with Windows for the current thread
+func registerGlobalHotkey(hotkeyID int32, modifiers, vkCode uint32) error {
+	ret, _, err := procRegisterHotKey.Call(
+		0,                  // hWnd (0 for current thread)
+		uintptr(hotkeyID),  // id
+		uintptr(modifiers), // fsModifiers
+		uintptr(vkCode),    // vk
+	)
+
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// unregisterGlobalHotkey removes a hotkey registration from Windows
+func unregisterGlobalHotkey(hotkeyID int32) error {
+	ret, _, err := procUnregisterHotKey.Call(
+		0,                 // hWnd
+		uintptr(hotkeyID), // id
+	)
+
+	if ret == 0 {
+		return err
+	}
+	return nil
+}
+
+// startMessageLoop runs the Windows message loop to handle hotkey events
+func (hm *HotkeyManager) startMessageLoop() {
+	hm.mutex.Lock()
+	hm.messageLoopRunning = true
+	hm.mutex.Unlock()
+
+	defer func() {
+		hm.mutex.Lock()
+		hm.messageLoopRunning = false
+		hm.mutex.Unlock()
+
+		// Recover from any panics in the message loop
+		if r := recover(); r != nil {
+			// In a production app, this would go to a proper logger
+			// For now, we just ensure the loop doesn't crash the app
+		}
+	}()
+
+	var msg MSG
+
+	for {
+		select {
+		case <-hm.stopMessageLoop:
+			return
+		case <-hm.ctx.Done():
+			return
+		default:
+			// Use PeekMessage to avoid blocking and allow for clean shutdown
+			ret, _, _ := procPeekMessage.Call(
+				uintptr(unsafe.Pointer(&msg)),
+				0, // hWnd (0 for any window)
+				0, // wMsgFilterMin
+				0, // wMsgFilterMax
+				1, // PM_REMOVE
+			)
+
+			if ret == 0 { // No message available
+				// Proper sleep to prevent busy waiting and reduce CPU usage
+				select {
+				case <-hm.stopMessageLoop:
+					return
+				case <-hm.ctx.Done():
+					return
+				case <-time.After(50 * time.Millisecond): // Sleep for 50ms to reduce CPU usage
+					continue
+				}
+			}
+
+			// Process hotkey messages
+			if msg.Message == WM_HOTKEY {
+				hm.handleHotkeyMessage(int32(msg.WParam))
+			} else {
+				// For non-hotkey messages, still translate and dispatch
+				// This ensures proper Windows message handling
+				procTranslateMessage.Call(uintptr(unsafe.Pointer(&msg)))
+				procDispatchMessage.Call(uintptr(unsafe.Pointer(&msg)))
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultIdleTimeout is how long a service must go without client connections to be stopped
+	defaultIdleTimeout = 30 * time.Minute
+	// defaultIdleGracePeriod is how long after a start a service is never stopped for being idle
+	defaultIdleGracePeriod = 15 * time.Minute
+	// idleCheckInterval is how often the connections of idle-stop services are counted
+	idleCheckInterval = time.Minute
+)
+
+// IdlePolicy stops a running service once no client has been connected to the TCP ports it
+// listens on for a while, freeing the memory of databases nobody is using
+type IdlePolicy struct {
+	AutoStop       bool     `json:"auto_stop"`
+	IdleMinutes    int      `json:"idle_minutes,omitempty"`    // Minutes without client connections before stopping, defaults to 30
+	GraceMinutes   int      `json:"grace_minutes,omitempty"`   // Minutes after a start during which the service is kept running, defaults to 15
+	ExcludeClients []string `json:"exclude_clients,omitempty"` // Client IPs or CIDR ranges whose connections do not count, e.g. a monitoring agent
+}
+
+// idleTimeout returns how long the service must be idle before it is stopped
+func (p IdlePolicy) idleTimeout() time.Duration {
+	if p.IdleMinutes > 0 {
+		return time.Duration(p.IdleMinutes) * time.Minute
+	}
+	return defaultIdleTimeout
+}
+
+// gracePeriod returns how long after a start the service is kept running regardless of use
+func (p IdlePolicy) gracePeriod() time.Duration {
+	if p.GraceMinutes > 0 {
+		return time.Duration(p.GraceMinutes) * time.Minute
+	}
+	return defaultIdleGracePeriod
+}
+
+// excludedNetworks parses the excluded clients; a single address excludes just that host
+func (p IdlePolicy) excludedNetworks() ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(p.ExcludeClients))
+	for _, client := range p.ExcludeClients {
+		if !strings.Contains(client, "/") {
+			ip := net.ParseIP(client)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid client address %q", client)
+			}
+			if ipv4 := ip.To4(); ipv4 != nil {
+				ip = ipv4
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(client)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client range %q", client)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// validateIdlePolicies checks that no policy has a negative setting or an invalid excluded client
+func validateIdlePolicies(policies map[string]IdlePolicy) error {
+	for name, policy := range policies {
+		if policy.IdleMinutes < 0 || policy.GraceMinutes < 0 {
+			return fmt.Errorf("idle policy for service %q cannot have negative settings", name)
+		}
+		if _, err := policy.excludedNetworks(); err != nil {
+			return fmt.Errorf("idle policy for service %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// idleActivity is when a running service was started and when a client was last connected to it
+type idleActivity struct {
+	started  time.Time
+	lastUsed time.Time
+}
+
+// idleMonitor stops idle-stop services that have had no client connections for too long
+type idleMonitor struct {
+	manager  *ServiceManager
+	now      func() time.Time // Replaced in tests to advance the clock
+	mu       sync.Mutex
+	notify   func(title, message string) // Shows idle stops to the user, e.g. in the tray
+	activity map[string]idleActivity     // Running idle-stop services by name
+}
+
+// newIdleMonitor creates an idle monitor for the services of a manager
+func newIdleMonitor(sm *ServiceManager) *idleMonitor {
+	return &idleMonitor{
+		manager:  sm,
+		now:      time.Now,
+		activity: make(map[string]idleActivity),
+	}
+}
+
+// setNotifier sets how idle stops are shown to the user
+func (m *idleMonitor) setNotifier(notify func(title, message string)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notify = notify
+}
+
+// Run counts connections periodically until the context is cancelled
+func (m *idleMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(idleCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check()
+		}
+	}
+}
+
+// observe restarts the grace period of a service that was started and forgets one that stopped
+func (m *idleMonitor) observe(event ServiceStatusEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case !event.NewStatus.hasProcess():
+		delete(m.activity, event.Name)
+	case event.NewStatus == StatusRunning && !event.OldStatus.hasProcess():
+		now := m.now()
+		m.activity[event.Name] = idleActivity{started: now, lastUsed: now}
+	}
+}
+
+// check counts the client connections of every running idle-stop service and stops those
+// that have been idle for longer than their policy allows
+func (m *idleMonitor) check() {
+	sm := m.manager
+	if sm.configManager == nil || sm.ports == nil {
+		return
+	}
+	policies := sm.configManager.GetIdlePolicies()
+	if len(policies) == 0 {
+		return
+	}
+
+	services, err := sm.GetServices()
+	if err != nil {
+		return
+	}
+	snapshot, err := sm.ports.connectionSnapshot()
+	if err != nil {
+		log.Printf("Idle monitor failed to read connections: %v", err)
+		return
+	}
+
+	var idle []string
+	now := m.now()
+	m.mu.Lock()
+	for _, service := range services {
+		policy, enabled := policies[service.Name]
+		if !enabled || service.Status != StatusRunning || service.PID == 0 {
+			continue
+		}
+
+		// Services already running when first seen get the grace period from then on
+		activity, exists := m.activity[service.Name]
+		if !exists {
+			activity = idleActivity{started: now, lastUsed: now}
+		}
+
+		excluded, _ := policy.excludedNetworks()
+		connections, listening := snapshot.clientConnections(service.PID, excluded)
+		// Without a listening port the use of the service cannot be measured, so it is kept running
+		if connections > 0 || !listening {
+			activity.lastUsed = now
+		}
+		m.activity[service.Name] = activity
+
+		if now.Sub(activity.started) >= policy.gracePeriod() && now.Sub(activity.lastUsed) >= policy.idleTimeout() {
+			idle = append(idle, service.Name)
+		}
+	}
+	m.mu.Unlock()
+
+	sort.Strings(idle)
+	for _, name := range idle {
+		m.stop(name, policies[name])
+	}
+}
+
+// stop stops an idle service and tells the user
+func (m *idleMonitor) stop(name string, policy IdlePolicy) {
+	err := m.manager.control(InitiatorIdle).StopService(name)
+	if err == nil {
+		m.show("Idle service stopped", fmt.Sprintf("%s had no client connections for %s and was stopped", name, policy.idleTimeout()))
+		return
+	}
+
+	// Wait a full idle timeout before trying again
+	m.mu.Lock()
+	if activity, exists := m.activity[name]; exists {
+		activity.lastUsed = m.now()
+		m.activity[name] = activity
+	}
+	m.mu.Unlock()
+
+	log.Printf("Idle monitor failed to stop %s: %v", name, err)
+	m.show("Idle service stop failed", fmt.Sprintf("Failed to stop idle service %s: %v", name, err))
+}
+
+// show passes a message to the notifier, if one is set
+func (m *idleMonitor) show(title, message string) {
+	m.mu.Lock()
+	notify := m.notify
+	m.mu.Unlock()
+
+	if notify != nil {
+		notify(title, message)
+	}
+}
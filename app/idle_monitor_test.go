@@ -0,0 +1,143 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// createIdleManager returns a manager with SQL Server running as PID 100 and listening on
+// port 1433, a controllable clock and the recorded notifications
+func createIdleManager(t *testing.T, policy IdlePolicy, connections ...tcpConnection) (*ServiceManager, *fakeServiceAdapter, *time.Time, *[]string) {
+	adapter := newFakeServiceAdapter(OSService{Name: "MSSQLSERVER", Status: StatusRunning, PID: 100})
+	sm := createTestServiceManager(t, adapter)
+	if err := sm.configManager.SetIdlePolicy("MSSQLSERVER", policy); err != nil {
+		t.Fatalf("SetIdlePolicy() failed: %v", err)
+	}
+
+	scanner := newFakePortScanner([]ListeningEndpoint{
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 1433, PID: 100},
+		{Protocol: ProtocolUDP, Address: "0.0.0.0", Port: 1434, PID: 100},
+	}, nil)
+	scanner.listConnections = func() ([]tcpConnection, error) { return connections, nil }
+	sm.detector.(*WindowsServiceDetector).ports = scanner
+	sm.ports = scanner
+
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	sm.idleMonitor.now = func() time.Time { return now }
+
+	notifications := &[]string{}
+	sm.idleMonitor.setNotifier(func(title, message string) {
+		*notifications = append(*notifications, title)
+	})
+	return sm, adapter, &now, notifications
+}
+
+// checkAt advances the clock to minutes after the start of the test and checks for idle services
+func checkAt(sm *ServiceManager, now *time.Time, start time.Time, minutes int) {
+	*now = start.Add(time.Duration(minutes) * time.Minute)
+	sm.idleMonitor.check()
+}
+
+func TestIdleMonitorStopsIdleService(t *testing.T) {
+	sm, adapter, now, notifications := createIdleManager(t, IdlePolicy{AutoStop: true})
+	start := *now
+
+	checkAt(sm, now, start, 0)
+	checkAt(sm, now, start, 29)
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusRunning {
+		t.Fatalf("Expected the service to run until the idle timeout, got %s", status)
+	}
+
+	checkAt(sm, now, start, 30)
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusStopped {
+		t.Fatalf("Expected the idle service to be stopped, got %s", status)
+	}
+	if strings.Join(*notifications, ",") != "Idle service stopped" {
+		t.Errorf("Unexpected notifications %v", *notifications)
+	}
+
+	history, err := sm.GetHistory(AuditFilter{Initiator: InitiatorIdle})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Operation != "stop" {
+		t.Errorf("Expected the stop to be audited, got %+v", history)
+	}
+}
+
+func TestIdleMonitorKeepsServiceWithClients(t *testing.T) {
+	sm, adapter, now, _ := createIdleManager(t, IdlePolicy{AutoStop: true},
+		// A client of the service, and the service's own outgoing connection which is not use
+		tcpConnection{localPort: 1433, remoteAddress: "10.0.0.5", remotePort: 50211, pid: 100},
+		tcpConnection{localPort: 50300, remoteAddress: "10.0.0.8", remotePort: 1433, pid: 100},
+	)
+	start := *now
+
+	for minutes := 0; minutes <= 120; minutes += 30 {
+		checkAt(sm, now, start, minutes)
+	}
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusRunning {
+		t.Errorf("Expected a service with clients to keep running, got %s", status)
+	}
+}
+
+func TestIdleMonitorExcludedClients(t *testing.T) {
+	sm, adapter, now, _ := createIdleManager(t, IdlePolicy{AutoStop: true, ExcludeClients: []string{"10.0.0.0/24", "::1"}},
+		tcpConnection{localPort: 1433, remoteAddress: "::ffff:10.0.0.9", remotePort: 50211, pid: 100},
+		tcpConnection{localPort: 1433, remoteAddress: "::1", remotePort: 50212, pid: 100},
+	)
+	start := *now
+
+	checkAt(sm, now, start, 0)
+	checkAt(sm, now, start, 30)
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusStopped {
+		t.Errorf("Expected connections of excluded clients not to count, got %s", status)
+	}
+}
+
+func TestIdleMonitorGracePeriod(t *testing.T) {
+	sm, adapter, now, _ := createIdleManager(t, IdlePolicy{AutoStop: true, IdleMinutes: 5, GraceMinutes: 60})
+	start := *now
+
+	// The service was started through ShutDB at the start of the test
+	sm.handleStatusChange(ServiceStatusEvent{Name: "MSSQLSERVER", OldStatus: StatusStopped, NewStatus: StatusRunning})
+	checkAt(sm, now, start, 30)
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusRunning {
+		t.Fatalf("Expected the service to run during the grace period, got %s", status)
+	}
+
+	checkAt(sm, now, start, 60)
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusStopped {
+		t.Errorf("Expected the service to be stopped after the grace period, got %s", status)
+	}
+}
+
+func TestIdleMonitorRequiresOptIn(t *testing.T) {
+	sm, adapter, now, notifications := createIdleManager(t, IdlePolicy{AutoStop: false})
+	start := *now
+
+	checkAt(sm, now, start, 0)
+	checkAt(sm, now, start, 120)
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusRunning || len(*notifications) != 0 {
+		t.Errorf("Expected a service without idle stop to keep running, got %s and %v", status, *notifications)
+	}
+}
+
+func TestIdlePolicyValidation(t *testing.T) {
+	configManager, _ := createTestConfigManager(t)
+
+	tests := []IdlePolicy{
+		{AutoStop: true, IdleMinutes: -1},
+		{AutoStop: true, ExcludeClients: []string{"monitoring.local"}},
+		{AutoStop: true, ExcludeClients: []string{"10.0.0.0/33"}},
+	}
+	for _, policy := range tests {
+		if err := configManager.SetIdlePolicy("OracleServiceXE", policy); err == nil {
+			t.Errorf("Expected %+v to be rejected", policy)
+		}
+	}
+	if policies := configManager.GetIdlePolicies(); len(policies) != 0 {
+		t.Errorf("Rejected policies must not be stored, got %+v", policies)
+	}
+}
@@ -0,0 +1,241 @@
+package app
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Components of a product installation, so that e.g. an Agent can be shown under its engine
+const (
+	ComponentEngine      = "engine"
+	ComponentAgent       = "agent"
+	ComponentFullText    = "full_text"
+	ComponentLaunchpad   = "launchpad"
+	ComponentTelemetry   = "telemetry"
+	ComponentAnalysis    = "analysis"
+	ComponentReporting   = "reporting"
+	ComponentBrowser     = "browser"
+	ComponentVSSWriter   = "vss_writer"
+	ComponentIntegration = "integration"
+)
+
+// mssqlDefaultInstance is the instance name of SQL Server's default instance
+const mssqlDefaultInstance = "MSSQLSERVER"
+
+// ServiceInstance identifies the product installation a service belongs to
+type ServiceInstance struct {
+	Product      string `json:"Product"`                // e.g. "SQL Server"
+	MajorVersion string `json:"MajorVersion,omitempty"` // e.g. "16", "9.6" or "8.0"
+	Name         string `json:"Name,omitempty"`         // e.g. "SQLEXPRESS" or the cluster "main"
+	Architecture string `json:"Architecture,omitempty"` // "x64" or "x86"
+	Component    string `json:"Component,omitempty"`    // e.g. "engine" or "agent"
+	ID           string `json:"ID,omitempty"`           // Shared by every service of the same instance
+}
+
+// productNames are the display names of the built-in service types
+var productNames = map[ServiceType]string{
+	TypePostgreSQL:    "PostgreSQL",
+	TypeMongoDB:       "MongoDB",
+	TypeMySQL:         "MySQL",
+	TypeMariaDB:       "MariaDB",
+	TypeMSSQL:         "SQL Server",
+	TypeOracle:        "Oracle Database",
+	TypeRedis:         "Redis",
+	TypeCassandra:     "Cassandra",
+	TypeElasticsearch: "Elasticsearch",
+	TypeCouchDB:       "CouchDB",
+	TypeInfluxDB:      "InfluxDB",
+	TypeNeo4j:         "Neo4j",
+	TypeRabbitMQ:      "RabbitMQ",
+	TypeMemcached:     "Memcached",
+	TypeSQLite:        "SQLite",
+	TypeDB2:           "Db2",
+	TypeFirebird:      "Firebird",
+}
+
+// mssqlComponents maps SQL Server service names, without a "$INSTANCE" suffix, to their component.
+// Components marked shared serve every instance on the machine.
+var mssqlComponents = map[string]struct {
+	component string
+	shared    bool
+}{
+	"mssqlserver":            {ComponentEngine, false},
+	"mssql":                  {ComponentEngine, false},
+	"sqlserveragent":         {ComponentAgent, false},
+	"sqlagent":               {ComponentAgent, false},
+	"mssqlfdlauncher":        {ComponentFullText, false},
+	"msftesql":               {ComponentFullText, false},
+	"mssqllaunchpad":         {ComponentLaunchpad, false},
+	"sqltelemetry":           {ComponentTelemetry, false},
+	"mssqlserverolapservice": {ComponentAnalysis, false},
+	"msolap":                 {ComponentAnalysis, false},
+	"reportserver":           {ComponentReporting, false},
+	"sqlbrowser":             {ComponentBrowser, true},
+	"sqlwriter":              {ComponentVSSWriter, true},
+	"sqlceip":                {ComponentTelemetry, true},
+}
+
+var (
+	// mssqlBinaryPattern finds the version and instance in e.g. "...\MSSQL16.SQLEXPRESS\MSSQL\Binn\sqlservr.exe"
+	mssqlBinaryPattern = regexp.MustCompile(`(?i)\\MSSQL(\d+)\.([^\\]+)\\`)
+	// msdtsServerPattern finds the version in Integration Services names such as "MsDtsServer160"
+	msdtsServerPattern = regexp.MustCompile(`(?i)^msdtsserver(\d{2})\d$`)
+	// postgresServicePattern matches the EDB installer's "postgresql-x64-16" and "postgresql-9.6"
+	postgresServicePattern = regexp.MustCompile(`(?i)^postgresql-(?:(x64|x86)-)?(\d+(?:\.\d+)?)$`)
+	// postgresClusterPattern matches Debian's per-cluster units such as "postgresql@16-main"
+	postgresClusterPattern = regexp.MustCompile(`^postgresql@(\d+(?:\.\d+)?)-(.+)$`)
+	// mysqlServicePattern matches the MySQL installer's "MySQL80" and "MySQL57_2"
+	mysqlServicePattern = regexp.MustCompile(`(?i)^mysql(\d)(\d)(?:_(.+))?$`)
+)
+
+// parseServiceInstance derives the instance identity of a detected service from the naming
+// conventions of its product, its binary path and its version
+func parseServiceInstance(service *Service, osService *OSService) *ServiceInstance {
+	instance := &ServiceInstance{Product: productNames[service.Type]}
+	if instance.Product == "" {
+		instance.Product = string(service.Type)
+	}
+
+	name := normalizeServiceName(osService)
+	switch service.Type {
+	case TypeMSSQL:
+		parseMSSQLInstance(instance, name, osService.BinaryPath)
+	case TypePostgreSQL:
+		if match := postgresServicePattern.FindStringSubmatch(name); match != nil {
+			instance.Architecture = strings.ToLower(match[1])
+			instance.MajorVersion = match[2]
+		} else if match := postgresClusterPattern.FindStringSubmatch(name); match != nil {
+			instance.MajorVersion, instance.Name = match[1], match[2]
+		}
+	case TypeMySQL:
+		if match := mysqlServicePattern.FindStringSubmatch(name); match != nil {
+			instance.MajorVersion = match[1] + "." + match[2]
+			instance.Name = match[3]
+		}
+	}
+
+	// systemd template instances such as redis-server@6380 name their instance after the "@"
+	if instance.Name == "" && osService.Backend != BackendDocker {
+		if _, suffix, found := strings.Cut(name, "@"); found && suffix != "" {
+			instance.Name = suffix
+		}
+	}
+
+	if instance.MajorVersion == "" {
+		instance.MajorVersion = majorVersion(service.Type, service.Version)
+	}
+	if instance.Architecture == "" {
+		instance.Architecture = binaryArchitecture(osService.BinaryPath)
+	}
+
+	instance.ID = instanceID(service.Type, instance)
+	return instance
+}
+
+// instanceID returns the key shared by all services of an instance, or "" for services that
+// cannot be attributed to one, such as SQL Server Browser
+func instanceID(serviceType ServiceType, instance *ServiceInstance) string {
+	switch {
+	case serviceType == TypePostgreSQL && instance.MajorVersion != "":
+		// Side-by-side PostgreSQL installations are told apart by their major version,
+		// Debian clusters additionally by their cluster name
+		if instance.Name != "" {
+			return string(serviceType) + ":" + instance.MajorVersion + "/" + instance.Name
+		}
+		return string(serviceType) + ":" + instance.MajorVersion
+	case instance.Name != "":
+		// SQL Server instance names are case-insensitive
+		return string(serviceType) + ":" + strings.ToUpper(instance.Name)
+	}
+	return ""
+}
+
+// parseMSSQLInstance parses SQL Server's NAME$INSTANCE convention, where a name without an
+// instance suffix belongs to the default instance MSSQLSERVER
+func parseMSSQLInstance(instance *ServiceInstance, name, binaryPath string) {
+	base, instanceName, named := strings.Cut(name, "$")
+	lowerBase := strings.ToLower(base)
+
+	if match := msdtsServerPattern.FindStringSubmatch(name); match != nil {
+		instance.Component = ComponentIntegration
+		instance.MajorVersion = strings.TrimPrefix(match[1], "0")
+		return
+	}
+
+	component, known := mssqlComponents[lowerBase]
+	if !known {
+		return
+	}
+	instance.Component = component.component
+	if component.shared {
+		return
+	}
+
+	if named {
+		instance.Name = instanceName
+	} else {
+		instance.Name = mssqlDefaultInstance
+	}
+
+	if match := mssqlBinaryPattern.FindStringSubmatch(binaryPath); match != nil {
+		instance.MajorVersion = match[1]
+	}
+}
+
+// majorVersion returns the major version of a product version. PostgreSQL before 10,
+// MySQL and MariaDB use two components, e.g. "9.6", "8.0" or "10.11".
+func majorVersion(serviceType ServiceType, version string) string {
+	if version == "" {
+		return ""
+	}
+	parts := strings.Split(version, ".")
+
+	twoParts := serviceType == TypeMySQL || serviceType == TypeMariaDB ||
+		(serviceType == TypePostgreSQL && len(parts[0]) == 1)
+	if twoParts && len(parts) >= 2 {
+		return parts[0] + "." + parts[1]
+	}
+	return parts[0]
+}
+
+// binaryArchitecture infers the architecture from well-known directory names in a binary path
+func binaryArchitecture(binaryPath string) string {
+	lower := strings.ToLower(binaryPath)
+	switch {
+	case lower == "":
+		return ""
+	case strings.Contains(lower, `\program files (x86)\`), strings.Contains(lower, "i386"):
+		return "x86"
+	case strings.Contains(lower, "x86_64"), strings.Contains(lower, "amd64"), strings.Contains(lower, "x64"):
+		return "x64"
+	}
+	return ""
+}
+
+// linkRelatedServices fills in RelatedServices for every service that shares its instance with others,
+// e.g. SQLAgent$SQLEXPRESS and MSSQL$SQLEXPRESS
+func linkRelatedServices(services []Service) {
+	members := make(map[string][]string)
+	for _, service := range services {
+		if service.Instance != nil && service.Instance.ID != "" {
+			members[service.Instance.ID] = append(members[service.Instance.ID], service.Name)
+		}
+	}
+
+	for i := range services {
+		service := &services[i]
+		if service.Instance == nil || len(members[service.Instance.ID]) < 2 {
+			continue
+		}
+
+		related := make([]string, 0, len(members[service.Instance.ID])-1)
+		for _, name := range members[service.Instance.ID] {
+			if name != service.Name {
+				related = append(related, name)
+			}
+		}
+		sort.Strings(related)
+		service.RelatedServices = related
+	}
+}
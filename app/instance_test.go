@@ -0,0 +1,160 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseServiceInstance(t *testing.T) {
+	tests := []struct {
+		name        string
+		osService   OSService
+		serviceType ServiceType
+		version     string
+		expected    ServiceInstance
+	}{
+		{
+			name:        "SQL Server default instance",
+			osService:   OSService{Name: "MSSQLSERVER", BinaryPath: `C:\Program Files\Microsoft SQL Server\MSSQL16.MSSQLSERVER\MSSQL\Binn\sqlservr.exe`},
+			serviceType: TypeMSSQL,
+			expected:    ServiceInstance{Product: "SQL Server", MajorVersion: "16", Name: "MSSQLSERVER", Component: ComponentEngine, ID: "mssql:MSSQLSERVER"},
+		},
+		{
+			name:        "SQL Server named instance",
+			osService:   OSService{Name: "MSSQL$SQLEXPRESS", BinaryPath: `C:\Program Files\Microsoft SQL Server\MSSQL15.SQLEXPRESS\MSSQL\Binn\sqlservr.exe`},
+			serviceType: TypeMSSQL,
+			expected:    ServiceInstance{Product: "SQL Server", MajorVersion: "15", Name: "SQLEXPRESS", Component: ComponentEngine, ID: "mssql:SQLEXPRESS"},
+		},
+		{
+			name:        "SQL Server Agent of a named instance",
+			osService:   OSService{Name: "SQLAgent$SQLEXPRESS", BinaryPath: `C:\Program Files\Microsoft SQL Server\MSSQL15.SQLEXPRESS\MSSQL\Binn\SQLAGENT.EXE`},
+			serviceType: TypeMSSQL,
+			expected:    ServiceInstance{Product: "SQL Server", MajorVersion: "15", Name: "SQLEXPRESS", Component: ComponentAgent, ID: "mssql:SQLEXPRESS"},
+		},
+		{
+			name:        "SQL Server Agent of the default instance",
+			osService:   OSService{Name: "SQLSERVERAGENT"},
+			serviceType: TypeMSSQL,
+			expected:    ServiceInstance{Product: "SQL Server", Name: "MSSQLSERVER", Component: ComponentAgent, ID: "mssql:MSSQLSERVER"},
+		},
+		{
+			name:        "SQL Server Browser is shared",
+			osService:   OSService{Name: "SQLBrowser", BinaryPath: `C:\Program Files (x86)\Microsoft SQL Server\90\Shared\sqlbrowser.exe`},
+			serviceType: TypeMSSQL,
+			expected:    ServiceInstance{Product: "SQL Server", Architecture: "x86", Component: ComponentBrowser},
+		},
+		{
+			name:        "SQL Server Integration Services",
+			osService:   OSService{Name: "MsDtsServer160"},
+			serviceType: TypeMSSQL,
+			expected:    ServiceInstance{Product: "SQL Server", MajorVersion: "16", Component: ComponentIntegration},
+		},
+		{
+			name:        "PostgreSQL EDB installer",
+			osService:   OSService{Name: "postgresql-x64-16"},
+			serviceType: TypePostgreSQL,
+			version:     "16.2",
+			expected:    ServiceInstance{Product: "PostgreSQL", MajorVersion: "16", Architecture: "x64", ID: "postgresql:16"},
+		},
+		{
+			name:        "PostgreSQL before 10",
+			osService:   OSService{Name: "postgresql-9.6"},
+			serviceType: TypePostgreSQL,
+			expected:    ServiceInstance{Product: "PostgreSQL", MajorVersion: "9.6", ID: "postgresql:9.6"},
+		},
+		{
+			name:        "PostgreSQL Debian cluster",
+			osService:   OSService{Name: "postgresql@15-main.service"},
+			serviceType: TypePostgreSQL,
+			expected:    ServiceInstance{Product: "PostgreSQL", MajorVersion: "15", Name: "main", ID: "postgresql:15/main"},
+		},
+		{
+			name:        "PostgreSQL version from binary",
+			osService:   OSService{Name: "postgresql.service"},
+			serviceType: TypePostgreSQL,
+			version:     "9.5.25",
+			expected:    ServiceInstance{Product: "PostgreSQL", MajorVersion: "9.5", ID: "postgresql:9.5"},
+		},
+		{
+			name:        "MySQL installer",
+			osService:   OSService{Name: "MySQL80"},
+			serviceType: TypeMySQL,
+			version:     "8.0.36",
+			expected:    ServiceInstance{Product: "MySQL", MajorVersion: "8.0"},
+		},
+		{
+			name:        "MySQL second instance",
+			osService:   OSService{Name: "MySQL57_2"},
+			serviceType: TypeMySQL,
+			expected:    ServiceInstance{Product: "MySQL", MajorVersion: "5.7", Name: "2", ID: "mysql:2"},
+		},
+		{
+			name:        "MariaDB version from binary",
+			osService:   OSService{Name: "MariaDB"},
+			serviceType: TypeMariaDB,
+			version:     "10.11.6",
+			expected:    ServiceInstance{Product: "MariaDB", MajorVersion: "10.11"},
+		},
+		{
+			name:        "systemd template instance",
+			osService:   OSService{Name: "redis-server@6380.service", Backend: BackendNative},
+			serviceType: TypeRedis,
+			version:     "7.2.4",
+			expected:    ServiceInstance{Product: "Redis", MajorVersion: "7", Name: "6380", ID: "redis:6380"},
+		},
+		{
+			name:        "custom type",
+			osService:   OSService{Name: "clickhouse-server"},
+			serviceType: ServiceType("clickhouse"),
+			expected:    ServiceInstance{Product: "clickhouse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{Name: tt.osService.Name, Type: tt.serviceType, Version: tt.version}
+			instance := parseServiceInstance(service, &tt.osService)
+			if *instance != tt.expected {
+				t.Errorf("parseServiceInstance(%q) = %+v, expected %+v", tt.osService.Name, *instance, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDetectServicesLinksInstances(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "MSSQL$SQLEXPRESS", Status: StatusRunning},
+		OSService{Name: "SQLAgent$SQLEXPRESS", Status: StatusStopped},
+		OSService{Name: "MSSQLSERVER", Status: StatusRunning},
+		OSService{Name: "SQLSERVERAGENT", Status: StatusRunning},
+		OSService{Name: "SQLBrowser", Status: StatusRunning},
+		OSService{Name: "SQLWriter", Status: StatusRunning},
+	)
+	detector := NewWindowsServiceDetector(adapter)
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+
+	expected := map[string][]string{
+		"MSSQL$SQLEXPRESS":    {"SQLAgent$SQLEXPRESS"},
+		"SQLAgent$SQLEXPRESS": {"MSSQL$SQLEXPRESS"},
+		"MSSQLSERVER":         {"SQLSERVERAGENT"},
+		"SQLSERVERAGENT":      {"MSSQLSERVER"},
+		"SQLBrowser":          nil,
+		"SQLWriter":           nil,
+	}
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %d services, got %+v", len(expected), services)
+	}
+	for _, service := range services {
+		if service.Instance == nil {
+			t.Errorf("Service %s has no instance", service.Name)
+			continue
+		}
+		if !reflect.DeepEqual(service.RelatedServices, expected[service.Name]) {
+			t.Errorf("Service %s: expected related services %v, got %v", service.Name, expected[service.Name], service.RelatedServices)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MatchKind is how strongly a pattern matched a service name
+type MatchKind string
+
+const (
+	MatchKindExact     MatchKind = "exact"     // The whole name equals the pattern
+	MatchKindPrefix    MatchKind = "prefix"    // The name starts with the pattern
+	MatchKindToken     MatchKind = "token"     // A word of the name, e.g. "redis" in "billing-redis-cache"
+	MatchKindSubstring MatchKind = "substring" // The pattern appears anywhere in the name
+	MatchKindRegex     MatchKind = "regex"     // A regular expression of a detection rule matched
+)
+
+// matchKindRanks orders match kinds from strongest to weakest
+var matchKindRanks = map[MatchKind]int{
+	MatchKindExact:     4,
+	MatchKindPrefix:    3,
+	MatchKindToken:     2,
+	MatchKindSubstring: 1,
+	MatchKindRegex:     1,
+}
+
+// Sources of a classification
+const (
+	MatchSourceBuiltin = "builtin"
+	MatchSourceRule    = "rule"
+)
+
+// Fields a built-in pattern can be matched against besides the name
+const (
+	matchFieldExecutable = "executable"
+	matchFieldImage      = "image"
+)
+
+// Pattern priorities break ties between matches of the same kind
+const (
+	priorityComponent = 0 // Auxiliary components such as SQL Server Agent
+	priorityAlias     = 1 // Abbreviations such as "postgres" or "mongo"
+	priorityProduct   = 2 // Full product names
+)
+
+// servicePattern maps a lowercase pattern to a service type
+type servicePattern struct {
+	pattern     string
+	serviceType ServiceType
+	priority    int
+}
+
+// MatchExplanation describes why a service was classified as its type
+type MatchExplanation struct {
+	Type         ServiceType   `json:"Type"`
+	Source       string        `json:"Source"`         // "builtin" or "rule"
+	Rule         string        `json:"Rule,omitempty"` // Name of the detection rule
+	Field        string        `json:"Field"`          // "name", "display_name", "executable" or "image"
+	Value        string        `json:"Value"`          // The text that was matched
+	Pattern      string        `json:"Pattern"`
+	Kind         MatchKind     `json:"Kind"`
+	Priority     int           `json:"Priority"`
+	Alternatives []ServiceType `json:"Alternatives,omitempty"` // Other types that matched less strongly
+	Description  string        `json:"Description"`
+}
+
+// describe fills in a human-readable summary of the match
+func (e *MatchExplanation) describe() {
+	field := strings.ReplaceAll(e.Field, "_", " ")
+
+	var how string
+	switch e.Kind {
+	case MatchKindExact:
+		how = fmt.Sprintf("is %q", e.Pattern)
+	case MatchKindPrefix:
+		how = fmt.Sprintf("starts with %q", e.Pattern)
+	case MatchKindToken:
+		how = fmt.Sprintf("contains the word %q", e.Pattern)
+	case MatchKindRegex:
+		how = fmt.Sprintf("matches /%s/", e.Pattern)
+	default:
+		how = fmt.Sprintf("contains %q", e.Pattern)
+	}
+
+	source := "built-in pattern"
+	if e.Source == MatchSourceRule {
+		source = fmt.Sprintf("detection rule %q", e.Rule)
+	}
+
+	e.Description = fmt.Sprintf("Classified as %s because the %s %q %s (%s)", e.Type, field, e.Value, how, source)
+	if len(e.Alternatives) > 0 {
+		names := make([]string, len(e.Alternatives))
+		for i, alternative := range e.Alternatives {
+			names[i] = string(alternative)
+		}
+		e.Description += fmt.Sprintf("; weaker matches: %s", strings.Join(names, ", "))
+	}
+}
+
+// patternMatch is a candidate match of a single pattern
+type patternMatch struct {
+	pattern  servicePattern
+	index    int // Declaration order of the pattern
+	kind     MatchKind
+	position int // Byte offset of the match, earlier matches win ties
+}
+
+// better reports whether m ranks above other: by kind, then priority, then earlier
+// position, then longer pattern, then declaration order
+func (m patternMatch) better(other patternMatch) bool {
+	if matchKindRanks[m.kind] != matchKindRanks[other.kind] {
+		return matchKindRanks[m.kind] > matchKindRanks[other.kind]
+	}
+	if m.pattern.priority != other.pattern.priority {
+		return m.pattern.priority > other.pattern.priority
+	}
+	if m.position != other.position {
+		return m.position < other.position
+	}
+	if len(m.pattern.pattern) != len(other.pattern.pattern) {
+		return len(m.pattern.pattern) > len(other.pattern.pattern)
+	}
+	return m.index < other.index
+}
+
+// matchPattern returns the strongest way a lowercase pattern matches a value
+func matchPattern(value, pattern string) (MatchKind, int, bool) {
+	lower := strings.ToLower(value)
+
+	if lower == pattern {
+		return MatchKindExact, 0, true
+	}
+	if strings.HasPrefix(lower, pattern) {
+		return MatchKindPrefix, 0, true
+	}
+	for _, token := range nameTokens(value) {
+		if strings.ToLower(token.text) == pattern {
+			return MatchKindToken, token.offset, true
+		}
+	}
+	if position := strings.Index(lower, pattern); position >= 0 {
+		return MatchKindSubstring, position, true
+	}
+	return "", 0, false
+}
+
+// nameToken is a word of a service name and its byte offset
+type nameToken struct {
+	text   string
+	offset int
+}
+
+// nameTokens splits a name into words at separators, camel case humps and letter/digit changes,
+// e.g. "SQLAgent$SQLEXPRESS" becomes "SQL", "Agent", "SQLEXPRESS" and "redis-server7" becomes
+// "redis", "server", "7"
+func nameTokens(name string) []nameToken {
+	var tokens []nameToken
+	runes := []rune(name)
+	start, startOffset, offset := -1, 0, 0
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, nameToken{text: string(runes[start:end]), offset: startOffset})
+			start = -1
+		}
+	}
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			flush(i)
+		} else if start >= 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			boundary := (unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev))) ||
+				(unicode.IsUpper(r) && unicode.IsUpper(prev) && nextLower) ||
+				(unicode.IsDigit(r) != unicode.IsDigit(prev))
+			if boundary {
+				flush(i)
+			}
+		}
+		if start < 0 && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			start, startOffset = i, offset
+		}
+		offset += len(string(r))
+	}
+	flush(len(runes))
+
+	return tokens
+}
+
+// matchServicePatterns ranks every pattern that matches a value and explains the best match.
+// The result does not depend on map iteration or any other source of randomness.
+func matchServicePatterns(patterns []servicePattern, field, value string) (*MatchExplanation, bool) {
+	if value == "" {
+		return nil, false
+	}
+
+	var matches []patternMatch
+	for i, pattern := range patterns {
+		if kind, position, ok := matchPattern(value, pattern.pattern); ok {
+			matches = append(matches, patternMatch{pattern: pattern, index: i, kind: kind, position: position})
+		}
+	}
+	if len(matches) == 0 {
+		return nil, false
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].better(matches[j]) })
+
+	best := matches[0]
+	explanation := &MatchExplanation{
+		Type:     best.pattern.serviceType,
+		Source:   MatchSourceBuiltin,
+		Field:    field,
+		Value:    value,
+		Pattern:  best.pattern.pattern,
+		Kind:     best.kind,
+		Priority: best.pattern.priority,
+	}
+
+	seen := map[ServiceType]bool{best.pattern.serviceType: true}
+	for _, match := range matches[1:] {
+		if !seen[match.pattern.serviceType] {
+			seen[match.pattern.serviceType] = true
+			explanation.Alternatives = append(explanation.Alternatives, match.pattern.serviceType)
+		}
+	}
+
+	explanation.describe()
+	return explanation, true
+}
+
+// normalizeServiceName strips decorations that are not part of the product name:
+// the backend qualifier of containers ("docker:redis") and systemd's ".service" suffix
+func normalizeServiceName(service *OSService) string {
+	name := service.Name
+	if service.Backend != "" && service.Backend != BackendNative {
+		name = strings.TrimPrefix(name, service.Backend+backendSeparator)
+	}
+	return strings.TrimSuffix(name, ".service")
+}
@@ -0,0 +1,252 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchServiceCorpus(t *testing.T) {
+	detector := NewWindowsServiceDetector(newFakeServiceAdapter())
+
+	tests := []struct {
+		name     string
+		service  OSService
+		expected ServiceType // Empty if the service must not be detected
+	}{
+		// PostgreSQL
+		{"EDB installer", OSService{Name: "postgresql-x64-16"}, TypePostgreSQL},
+		{"EDB installer 9.6", OSService{Name: "postgresql-x64-9.6"}, TypePostgreSQL},
+		{"32-bit installer", OSService{Name: "postgresql-9.4"}, TypePostgreSQL},
+		{"systemd", OSService{Name: "postgresql.service"}, TypePostgreSQL},
+		{"systemd cluster", OSService{Name: "postgresql@16-main.service"}, TypePostgreSQL},
+		{"exporter", OSService{Name: "postgres_exporter.service"}, TypePostgreSQL},
+		{"container", OSService{Name: "docker:postgres", Backend: BackendDocker}, TypePostgreSQL},
+		{"in-house name", OSService{Name: "billing-postgres-primary"}, TypePostgreSQL},
+
+		// MySQL and MariaDB
+		{"MySQL 8", OSService{Name: "MySQL80"}, TypeMySQL},
+		{"MySQL 5.7", OSService{Name: "MySQL57"}, TypeMySQL},
+		{"mysqld unit", OSService{Name: "mysqld.service"}, TypeMySQL},
+		{"MySQL Router", OSService{Name: "MySQLRouter"}, TypeMySQL},
+		{"MariaDB", OSService{Name: "MariaDB"}, TypeMariaDB},
+		{"MariaDB unit", OSService{Name: "mariadb.service"}, TypeMariaDB},
+
+		// SQL Server
+		{"default instance", OSService{Name: "MSSQLSERVER"}, TypeMSSQL},
+		{"named instance", OSService{Name: "MSSQL$SQLEXPRESS"}, TypeMSSQL},
+		{"agent", OSService{Name: "SQLSERVERAGENT"}, TypeMSSQL},
+		{"named agent", OSService{Name: "SQLAgent$SQLEXPRESS"}, TypeMSSQL},
+		{"browser", OSService{Name: "SQLBrowser"}, TypeMSSQL},
+		{"VSS writer", OSService{Name: "SQLWriter"}, TypeMSSQL},
+		{"CEIP", OSService{Name: "SQLTELEMETRY$SQLEXPRESS"}, TypeMSSQL},
+		{"integration services", OSService{Name: "MsDtsServer160"}, TypeMSSQL},
+		{"launchpad", OSService{Name: "MSSQLLaunchpad$SQLEXPRESS"}, TypeMSSQL},
+		{"full-text", OSService{Name: "MSSQLFDLauncher"}, TypeMSSQL},
+		{"reporting services", OSService{Name: "ReportServer"}, TypeMSSQL},
+		{"Linux", OSService{Name: "mssql-server.service"}, TypeMSSQL},
+
+		// Other relational databases
+		{"Oracle database", OSService{Name: "OracleServiceXE"}, TypeOracle},
+		{"Oracle listener", OSService{Name: "OracleOraDB21Home1TNSListener"}, TypeOracle},
+		{"Oracle VSS writer", OSService{Name: "OracleVssWriterXE"}, TypeOracle},
+		{"DB2 instance", OSService{Name: "DB2-0"}, TypeDB2},
+		{"DB2 management", OSService{Name: "DB2MGMTSVC_DB2COPY1"}, TypeDB2},
+		{"Firebird server", OSService{Name: "FirebirdServerDefaultInstance"}, TypeFirebird},
+		{"Firebird guardian", OSService{Name: "FirebirdGuardianDefaultInstance"}, TypeFirebird},
+
+		// NoSQL databases
+		{"MongoDB", OSService{Name: "MongoDB"}, TypeMongoDB},
+		{"mongod unit", OSService{Name: "mongod.service"}, TypeMongoDB},
+		{"mongo container", OSService{Name: "docker:mongo", Backend: BackendDocker}, TypeMongoDB},
+		{"Cassandra", OSService{Name: "cassandra.service"}, TypeCassandra},
+		{"CouchDB", OSService{Name: "Apache CouchDB"}, TypeCouchDB},
+		{"Neo4j", OSService{Name: "neo4j"}, TypeNeo4j},
+
+		// Caches, search and brokers
+		{"Redis", OSService{Name: "Redis"}, TypeRedis},
+		{"redis-server unit", OSService{Name: "redis-server.service"}, TypeRedis},
+		{"Redis sentinel", OSService{Name: "redis-sentinel.service"}, TypeRedis},
+		{"camel case", OSService{Name: "AcmeRedisCache"}, TypeRedis},
+		{"RedisInsight", OSService{Name: "RedisInsight"}, TypeRedis},
+		{"Memcached", OSService{Name: "memcached.service"}, TypeMemcached},
+		{"Elasticsearch", OSService{Name: "elasticsearch-service-x64"}, TypeElasticsearch},
+		{"Elasticsearch unit", OSService{Name: "elasticsearch.service"}, TypeElasticsearch},
+		{"InfluxDB", OSService{Name: "influxdb"}, TypeInfluxDB},
+		{"influxd", OSService{Name: "influxd.service"}, TypeInfluxDB},
+		{"RabbitMQ", OSService{Name: "RabbitMQ"}, TypeRabbitMQ},
+		{"RabbitMQ unit", OSService{Name: "rabbitmq-server.service"}, TypeRabbitMQ},
+
+		// Names mentioning several products resolve the same way every time
+		{"prefix beats word", OSService{Name: "mysql-router-postgres-bridge"}, TypeMySQL},
+		{"prefix beats word reversed", OSService{Name: "postgres-mysql-bridge"}, TypePostgreSQL},
+		{"exact beats substring", OSService{Name: "redis"}, TypeRedis},
+		{"earlier word wins", OSService{Name: "sync-mongo-to-elastic"}, TypeMongoDB},
+		{"earlier word wins reversed", OSService{Name: "sync-elastic-to-mongo"}, TypeElasticsearch},
+		{"word beats substring", OSService{Name: "replicator-redis-for-amysqlx"}, TypeRedis},
+		{"product beats alias", OSService{Name: "xpostgresxmongodb"}, TypeMongoDB},
+
+		// Generic names are matched by the executable the service runs
+		{"pg_ctl wrapper", OSService{Name: "Service1", BinaryPath: `C:\Program Files\PostgreSQL\16\bin\pg_ctl.exe`}, TypePostgreSQL},
+		{"postgres binary", OSService{Name: "appdb.service", BinaryPath: "/usr/lib/postgresql/16/bin/postgres"}, TypePostgreSQL},
+		{"LocalDB", OSService{Name: "LocalDB", BinaryPath: `C:\Program Files\Microsoft SQL Server\160\LocalDB\Binn\sqlservr.exe`}, TypeMSSQL},
+		{"mysqld", OSService{Name: "DBSvc", BinaryPath: `C:\mysql\bin\mysqld.exe`}, TypeMySQL},
+		{"mariadbd", OSService{Name: "orders.service", BinaryPath: "/usr/sbin/mariadbd"}, TypeMariaDB},
+		{"redis-server", OSService{Name: "cache01", BinaryPath: "/usr/bin/redis-server"}, TypeRedis},
+		{"Memurai", OSService{Name: "Memurai", BinaryPath: `C:\Program Files\Memurai\memurai.exe`}, TypeRedis},
+		{"mongod", OSService{Name: "docstore", BinaryPath: `C:\Program Files\MongoDB\Server\7.0\bin\mongod.exe`}, TypeMongoDB},
+		{"Oracle listener", OSService{Name: "Listener", BinaryPath: `C:\app\oracle\product\21c\bin\TNSLSNR.EXE`}, TypeOracle},
+		{"Firebird", OSService{Name: "FB3", BinaryPath: `C:\Firebird\fbserver.exe`}, TypeFirebird},
+		{"name beats executable", OSService{Name: "redis-sidecar", BinaryPath: "/usr/bin/envoy"}, TypeRedis},
+		{"unrelated executable", OSService{Name: "Service1", BinaryPath: `C:\Windows\system32\svchost.exe`}, ""},
+
+		// Containers are matched by image when the name is not recognised
+		{"image", OSService{Name: "docker:billing-db", Backend: BackendDocker, Image: "docker.io/library/postgres:16"}, TypePostgreSQL},
+		{"registry image", OSService{Name: "docker:sql", Backend: BackendDocker, Image: "mcr.microsoft.com/mssql/server:2022-latest"}, TypeMSSQL},
+		{"image digest", OSService{Name: "docker:cache", Backend: BackendDocker, Image: "bitnami/redis@sha256:0123"}, TypeRedis},
+
+		// Unrelated services
+		{"print spooler", OSService{Name: "Spooler"}, ""},
+		{"Windows Update", OSService{Name: "wuauserv"}, ""},
+		{"IIS", OSService{Name: "W3SVC"}, ""},
+		{"sshd", OSService{Name: "sshd.service"}, ""},
+		{"nginx container", OSService{Name: "docker:web", Backend: BackendDocker, Image: "nginx:1.27"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := tt.service
+			match, matched := detector.MatchService(&service)
+
+			if tt.expected == "" {
+				if matched {
+					t.Errorf("Expected %s not to be detected, got %s (%s)", tt.service.Name, match.Type, match.Description)
+				}
+				return
+			}
+			if !matched {
+				t.Fatalf("Expected %s to be detected as %s", tt.service.Name, tt.expected)
+			}
+			if match.Type != tt.expected {
+				t.Errorf("Expected %s to be %s, got %s (%s)", tt.service.Name, tt.expected, match.Type, match.Description)
+			}
+
+			// The result must not depend on iteration order
+			for i := 0; i < 20; i++ {
+				again, _ := detector.MatchService(&service)
+				if again.Type != match.Type || again.Pattern != match.Pattern {
+					t.Fatalf("Nondeterministic match: %s/%s then %s/%s", match.Type, match.Pattern, again.Type, again.Pattern)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchPatternKinds(t *testing.T) {
+	tests := []struct {
+		value    string
+		pattern  string
+		kind     MatchKind
+		position int
+	}{
+		{"Redis", "redis", MatchKindExact, 0},
+		{"redis-server", "redis", MatchKindPrefix, 0},
+		{"billing-redis-cache", "redis", MatchKindToken, 8},
+		{"AcmeRedisCache", "redis", MatchKindToken, 4},
+		{"app-redis7", "redis", MatchKindToken, 4},
+		{"SQLAgent$SQLEXPRESS", "agent", MatchKindToken, 3},
+		{"amysqlx", "mysql", MatchKindSubstring, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+"/"+tt.pattern, func(t *testing.T) {
+			kind, position, ok := matchPattern(tt.value, tt.pattern)
+			if !ok || kind != tt.kind || position != tt.position {
+				t.Errorf("Expected %s at %d, got %s at %d (matched %v)", tt.kind, tt.position, kind, position, ok)
+			}
+		})
+	}
+
+	if _, _, ok := matchPattern("Spooler", "redis"); ok {
+		t.Error("Expected no match")
+	}
+}
+
+func TestMatchExplanation(t *testing.T) {
+	detector := NewWindowsServiceDetector(newFakeServiceAdapter())
+
+	service := OSService{Name: "mysql-router-postgres-bridge"}
+	match, matched := detector.MatchService(&service)
+	if !matched {
+		t.Fatal("Expected a match")
+	}
+
+	if match.Source != MatchSourceBuiltin || match.Field != MatchName || match.Pattern != "mysql" || match.Kind != MatchKindPrefix || match.Priority != priorityProduct {
+		t.Errorf("Unexpected explanation: %+v", match)
+	}
+	if len(match.Alternatives) != 1 || match.Alternatives[0] != TypePostgreSQL {
+		t.Errorf("Expected postgresql as the weaker alternative, got %v", match.Alternatives)
+	}
+	for _, part := range []string{"mysql", `starts with "mysql"`, "built-in pattern", "weaker matches: postgresql"} {
+		if !strings.Contains(match.Description, part) {
+			t.Errorf("Expected description to contain %q, got %q", part, match.Description)
+		}
+	}
+}
+
+func TestMatchExplanationForRules(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "svc-042", DisplayName: "MinIO Object Storage", Status: StatusRunning},
+		OSService{Name: "ClickHouseServer", Status: StatusRunning},
+	)
+	detector := createTestRuleDetector(t, adapter, []DetectionRule{
+		{Name: "minio", Include: []string{"minio"}, Type: "minio", Category: CategoryNoSQL},
+		{Name: "clickhouse", IncludeRegex: []string{`^click`}, Type: "clickhouse", Category: CategorySearch},
+	})
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+	if len(services) != 2 {
+		t.Fatalf("Expected 2 services, got %+v", services)
+	}
+
+	minio, clickhouse := services[0].Match, services[1].Match
+	if minio == nil || minio.Source != MatchSourceRule || minio.Rule != "minio" || minio.Field != MatchDisplayName || minio.Kind != MatchKindPrefix {
+		t.Errorf("Unexpected explanation for minio: %+v", minio)
+	}
+	if clickhouse == nil || clickhouse.Kind != MatchKindRegex || clickhouse.Pattern != "^click" || !strings.Contains(clickhouse.Description, `detection rule "clickhouse"`) {
+		t.Errorf("Unexpected explanation for clickhouse: %+v", clickhouse)
+	}
+}
+
+func TestDetectServicesByExecutable(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "Service1", Status: StatusRunning, BinaryPath: `C:\Program Files\PostgreSQL\16\bin\pg_ctl.exe`, Arguments: []string{"runservice"}, PID: 4242},
+		OSService{Name: "Spooler", Status: StatusRunning, BinaryPath: `C:\Windows\System32\spoolsv.exe`},
+	)
+	detector := NewWindowsServiceDetector(adapter)
+	detector.ports = newFakePortScanner(nil, nil)
+	detector.versionOf = func(binaryPath string) string {
+		if strings.HasSuffix(binaryPath, "pg_ctl.exe") {
+			return "16.2"
+		}
+		return ""
+	}
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+	if len(services) != 1 {
+		t.Fatalf("Expected 1 service, got %+v", services)
+	}
+
+	service := services[0]
+	if service.Type != TypePostgreSQL || service.Category != CategorySQL || service.Version != "16.2" {
+		t.Errorf("Unexpected service: %+v", service)
+	}
+	if service.Match.Field != matchFieldExecutable || service.Match.Value != "pg_ctl" || service.Match.Kind != MatchKindExact {
+		t.Errorf("Unexpected explanation: %+v", service.Match)
+	}
+}
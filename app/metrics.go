@@ -0,0 +1,261 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// operationDurationBuckets are the histogram bounds in seconds; database starts can take minutes
+var operationDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// operationCounterKey identifies a series of the operations counter
+type operationCounterKey struct {
+	operation string
+	service   string
+	outcome   string
+	errorCode string
+}
+
+// operationDurationKey identifies a series of the operation duration histogram
+type operationDurationKey struct {
+	operation string
+	service   string
+}
+
+// statusChangeKey identifies a series of the status change counter
+type statusChangeKey struct {
+	service string
+	from    ServiceStatus
+	to      ServiceStatus
+}
+
+// durationHistogram is a cumulative Prometheus histogram
+type durationHistogram struct {
+	buckets []uint64 // Count per bucket in operationDurationBuckets, not cumulative
+	count   uint64
+	sum     float64
+}
+
+// ServiceMetrics collects operation outcomes and durations and renders them,
+// together with the current service state, in the Prometheus text exposition format
+type ServiceMetrics struct {
+	mu            sync.Mutex
+	operations    map[operationCounterKey]uint64
+	durations     map[operationDurationKey]*durationHistogram
+	statusChanges map[statusChangeKey]uint64
+}
+
+// NewServiceMetrics creates an empty metrics collector
+func NewServiceMetrics() *ServiceMetrics {
+	return &ServiceMetrics{
+		operations:    make(map[operationCounterKey]uint64),
+		durations:     make(map[operationDurationKey]*durationHistogram),
+		statusChanges: make(map[statusChangeKey]uint64),
+	}
+}
+
+// ObserveOperation records the outcome and duration of a service operation
+func (m *ServiceMetrics) ObserveOperation(operation, service string, duration time.Duration, err error) {
+	key := operationCounterKey{operation: operation, service: service, outcome: "success"}
+	if err != nil {
+		key.outcome = "failure"
+		key.errorCode = "unknown"
+
+		var serviceErr *ServiceError
+		if errors.As(err, &serviceErr) {
+			key.errorCode = serviceErr.Code.String()
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.operations[key]++
+
+	durationKey := operationDurationKey{operation: operation, service: service}
+	histogram, exists := m.durations[durationKey]
+	if !exists {
+		histogram = &durationHistogram{buckets: make([]uint64, len(operationDurationBuckets))}
+		m.durations[durationKey] = histogram
+	}
+
+	seconds := duration.Seconds()
+	for i, bound := range operationDurationBuckets {
+		if seconds <= bound {
+			histogram.buckets[i]++
+			break
+		}
+	}
+	histogram.count++
+	histogram.sum += seconds
+}
+
+// ObserveStatusChange counts a status transition, including ones made outside ShutDB such as crashes
+func (m *ServiceMetrics) ObserveStatusChange(event ServiceStatusEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.statusChanges[statusChangeKey{service: event.Name, from: event.OldStatus, to: event.NewStatus}]++
+}
+
+// WriteTo renders all metrics for the given services in the Prometheus text format
+func (m *ServiceMetrics) WriteTo(w io.Writer, services []Service) error {
+	var b strings.Builder
+
+	sorted := append([]Service(nil), services...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	writeMetricHeader(&b, "shutdb_service_up", "gauge", "Whether the service is running (1) or not (0).")
+	for _, service := range sorted {
+		up := 0
+		if service.Status == StatusRunning {
+			up = 1
+		}
+		writeSample(&b, "shutdb_service_up", []string{
+			"service", service.Name,
+			"type", string(service.Type),
+			"category", string(service.Category),
+			"backend", service.Backend,
+		}, float64(up))
+	}
+
+	writeMetricHeader(&b, "shutdb_service_startup_type", "gauge", "The configured startup type of the service; the current type is 1.")
+	for _, service := range sorted {
+		startupTypes := []StartupType{StartupAutomatic, StartupAutomaticDelayed, StartupManual, StartupDisabled}
+		if !service.StartupType.settable() && service.StartupType != "" {
+			// Boot and system startup only apply to drivers, so they are reported when in use
+			startupTypes = append(startupTypes, service.StartupType)
+		}
+		for _, startupType := range startupTypes {
+			value := 0
+			if service.StartupType == startupType {
+				value = 1
+			}
+			writeSample(&b, "shutdb_service_startup_type", []string{
+				"service", service.Name,
+				"startup_type", string(startupType),
+			}, float64(value))
+		}
+	}
+
+	m.mu.Lock()
+
+	operationKeys := make([]operationCounterKey, 0, len(m.operations))
+	for key := range m.operations {
+		operationKeys = append(operationKeys, key)
+	}
+	sort.Slice(operationKeys, func(i, j int) bool {
+		x, y := operationKeys[i], operationKeys[j]
+		if x.operation != y.operation {
+			return x.operation < y.operation
+		}
+		if x.service != y.service {
+			return x.service < y.service
+		}
+		if x.outcome != y.outcome {
+			return x.outcome < y.outcome
+		}
+		return x.errorCode < y.errorCode
+	})
+
+	writeMetricHeader(&b, "shutdb_service_operations_total", "counter", "Service operations by outcome and error code.")
+	for _, key := range operationKeys {
+		writeSample(&b, "shutdb_service_operations_total", []string{
+			"operation", key.operation,
+			"service", key.service,
+			"outcome", key.outcome,
+			"error_code", key.errorCode,
+		}, float64(m.operations[key]))
+	}
+
+	durationKeys := make([]operationDurationKey, 0, len(m.durations))
+	for key := range m.durations {
+		durationKeys = append(durationKeys, key)
+	}
+	sort.Slice(durationKeys, func(i, j int) bool {
+		if durationKeys[i].operation != durationKeys[j].operation {
+			return durationKeys[i].operation < durationKeys[j].operation
+		}
+		return durationKeys[i].service < durationKeys[j].service
+	})
+
+	writeMetricHeader(&b, "shutdb_service_operation_duration_seconds", "histogram", "Duration of service operations, including readiness checks.")
+	for _, key := range durationKeys {
+		histogram := m.durations[key]
+		labels := []string{"operation", key.operation, "service", key.service}
+
+		var cumulative uint64
+		for i, bound := range operationDurationBuckets {
+			cumulative += histogram.buckets[i]
+			writeSample(&b, "shutdb_service_operation_duration_seconds_bucket", append(labels, "le", formatFloat(bound)), float64(cumulative))
+		}
+		writeSample(&b, "shutdb_service_operation_duration_seconds_bucket", append(labels, "le", "+Inf"), float64(histogram.count))
+		writeSample(&b, "shutdb_service_operation_duration_seconds_sum", labels, histogram.sum)
+		writeSample(&b, "shutdb_service_operation_duration_seconds_count", labels, float64(histogram.count))
+	}
+
+	changeKeys := make([]statusChangeKey, 0, len(m.statusChanges))
+	for key := range m.statusChanges {
+		changeKeys = append(changeKeys, key)
+	}
+	sort.Slice(changeKeys, func(i, j int) bool {
+		x, y := changeKeys[i], changeKeys[j]
+		if x.service != y.service {
+			return x.service < y.service
+		}
+		if x.from != y.from {
+			return x.from < y.from
+		}
+		return x.to < y.to
+	})
+
+	writeMetricHeader(&b, "shutdb_service_status_changes_total", "counter", "Observed service status transitions, including ones made outside ShutDB.")
+	for _, key := range changeKeys {
+		writeSample(&b, "shutdb_service_status_changes_total", []string{
+			"service", key.service,
+			"from", string(key.from),
+			"to", string(key.to),
+		}, float64(m.statusChanges[key]))
+	}
+
+	m.mu.Unlock()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric family
+func writeMetricHeader(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// writeSample writes a single sample; labels are given as name/value pairs
+func writeSample(b *strings.Builder, name string, labels []string, value float64) {
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1]))
+		}
+		b.WriteByte('}')
+	}
+	fmt.Fprintf(b, " %s\n", formatFloat(value))
+}
+
+// escapeLabelValue escapes a label value for the text exposition format
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat formats a sample value in its shortest form, e.g. "1" or "0.25"
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
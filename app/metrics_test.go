@@ -0,0 +1,129 @@
+package app
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServiceMetricsOperations(t *testing.T) {
+	metrics := NewServiceMetrics()
+	metrics.ObserveOperation("start", "Redis", 300*time.Millisecond, nil)
+	metrics.ObserveOperation("start", "Redis", 3*time.Second, &ServiceError{Code: ErrNotReady, Message: "not ready", Service: "Redis"})
+	metrics.ObserveOperation("stop", "Redis", 50*time.Millisecond, &ServiceError{Code: ErrInvalidState, Message: "already stopped", Service: "Redis"})
+
+	var out strings.Builder
+	if err := metrics.WriteTo(&out, nil); err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	text := out.String()
+
+	expected := []string{
+		`# TYPE shutdb_service_operations_total counter`,
+		`shutdb_service_operations_total{operation="start",service="Redis",outcome="failure",error_code="not_ready"} 1`,
+		`shutdb_service_operations_total{operation="start",service="Redis",outcome="success",error_code=""} 1`,
+		`shutdb_service_operations_total{operation="stop",service="Redis",outcome="failure",error_code="invalid_state"} 1`,
+		`# TYPE shutdb_service_operation_duration_seconds histogram`,
+		`shutdb_service_operation_duration_seconds_bucket{operation="start",service="Redis",le="0.25"} 0`,
+		`shutdb_service_operation_duration_seconds_bucket{operation="start",service="Redis",le="0.5"} 1`,
+		`shutdb_service_operation_duration_seconds_bucket{operation="start",service="Redis",le="5"} 2`,
+		`shutdb_service_operation_duration_seconds_bucket{operation="start",service="Redis",le="+Inf"} 2`,
+		`shutdb_service_operation_duration_seconds_sum{operation="start",service="Redis"} 3.3`,
+		`shutdb_service_operation_duration_seconds_count{operation="start",service="Redis"} 2`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Missing line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestServiceMetricsServiceState(t *testing.T) {
+	metrics := NewServiceMetrics()
+	metrics.ObserveStatusChange(ServiceStatusEvent{Name: "MSSQLSERVER", OldStatus: StatusRunning, NewStatus: StatusStopped})
+
+	services := []Service{
+		{Name: "Redis", Status: StatusStopped, Type: TypeRedis, Category: CategoryCache, StartupType: StartupManual, Backend: BackendNative},
+		{Name: "MSSQLSERVER", Status: StatusRunning, Type: TypeMSSQL, Category: CategorySQL, StartupType: StartupAutomatic, Backend: BackendNative},
+		{Name: `odd"name`, Status: StatusRunning, Type: TypeRedis, Category: CategoryCache, StartupType: StartupDisabled, Backend: BackendDocker},
+	}
+
+	var out strings.Builder
+	if err := metrics.WriteTo(&out, services); err != nil {
+		t.Fatalf("WriteTo() failed: %v", err)
+	}
+	text := out.String()
+
+	expected := []string{
+		`shutdb_service_up{service="MSSQLSERVER",type="mssql",category="sql_databases",backend="native"} 1`,
+		`shutdb_service_up{service="Redis",type="redis",category="cache_memory",backend="native"} 0`,
+		`shutdb_service_up{service="odd\"name",type="redis",category="cache_memory",backend="docker"} 1`,
+		`shutdb_service_startup_type{service="MSSQLSERVER",startup_type="automatic"} 1`,
+		`shutdb_service_startup_type{service="MSSQLSERVER",startup_type="manual"} 0`,
+		`shutdb_service_startup_type{service="MSSQLSERVER",startup_type="automatic_delayed"} 0`,
+		`shutdb_service_startup_type{service="Redis",startup_type="manual"} 1`,
+		`shutdb_service_status_changes_total{service="MSSQLSERVER",from="running",to="stopped"} 1`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Missing line %q in:\n%s", line, text)
+		}
+	}
+
+	// Series are sorted so scrapes are stable
+	if strings.Index(text, `service="MSSQLSERVER",type`) > strings.Index(text, `service="Redis",type`) {
+		t.Error("Expected services to be sorted by name")
+	}
+}
+
+func TestServiceManagerRecordsOperationMetrics(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	sm := createTestServiceManager(t, adapter)
+
+	sm.WaitForOperation(sm.StartService("Redis"))
+	sm.WaitForOperation(sm.StartService("Redis"))
+
+	var out strings.Builder
+	if err := sm.writeMetrics(&out); err != nil {
+		t.Fatalf("writeMetrics() failed: %v", err)
+	}
+	text := out.String()
+
+	for _, line := range []string{
+		`shutdb_service_operations_total{operation="start",service="Redis",outcome="success",error_code=""} 1`,
+		`shutdb_service_operations_total{operation="start",service="Redis",outcome="failure",error_code="invalid_state"} 1`,
+		`shutdb_service_up{service="Redis",type="redis",category="cache_memory",backend=""} 1`,
+	} {
+		if !strings.Contains(text, line+"\n") {
+			t.Errorf("Missing line %q in:\n%s", line, text)
+		}
+	}
+}
+
+func TestAPIServerMetrics(t *testing.T) {
+	apiServer, _, server, token := createTestAPIServer(t)
+
+	if status := apiRequest(t, server, token, http.MethodGet, "/metrics", nil); status != http.StatusNotFound {
+		t.Errorf("Expected metrics to be disabled by default, got status %d", status)
+	}
+
+	if err := apiServer.configManager.SetMetricsEnabled(true); err != nil {
+		t.Fatalf("SetMetricsEnabled() failed: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/metrics", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain; version=0.0.4") {
+		t.Errorf("Unexpected content type %q", contentType)
+	}
+}
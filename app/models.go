@@ -9,8 +9,21 @@ const (
 	StatusStarting   ServiceStatus = "starting"
 	StatusStopping   ServiceStatus = "stopping"
 	StatusRestarting ServiceStatus = "restarting"
+	StatusPaused     ServiceStatus = "paused" // Running but not accepting new work, e.g. connections
+	StatusPausing    ServiceStatus = "pausing"
+	StatusContinuing ServiceStatus = "continuing"
 )
 
+// hasProcess reports whether a service's process is up, including while it is paused
+func (s ServiceStatus) hasProcess() bool {
+	switch s {
+	case StatusRunning, StatusPaused, StatusPausing, StatusContinuing:
+		return true
+	default:
+		return false
+	}
+}
+
 // ServiceType represents the type of database service
 type ServiceType string
 
@@ -38,11 +51,39 @@ const (
 type StartupType string
 
 const (
-	StartupAutomatic StartupType = "automatic"
-	StartupManual    StartupType = "manual"
-	StartupDisabled  StartupType = "disabled"
+	StartupAutomatic        StartupType = "automatic"
+	StartupAutomaticDelayed StartupType = "automatic_delayed" // Started shortly after the other automatic services
+	StartupManual           StartupType = "manual"
+	StartupDisabled         StartupType = "disabled"
+	StartupBoot             StartupType = "boot"   // Loaded by the boot loader, drivers only
+	StartupSystem           StartupType = "system" // Loaded during kernel initialization, drivers only
 )
 
+// settable reports whether a startup type can be set with SetStartupType.
+// Boot and system startup are only reported, as they apply to drivers.
+func (t StartupType) settable() bool {
+	switch t {
+	case StartupAutomatic, StartupAutomaticDelayed, StartupManual, StartupDisabled:
+		return true
+	default:
+		return false
+	}
+}
+
+// supportsStartupType reports whether a startup type can be set on the service. Services whose
+// backend did not report its startup types are assumed to accept every settable one.
+func (s *Service) supportsStartupType(startupType StartupType) bool {
+	if len(s.StartupTypes) == 0 {
+		return startupType.settable()
+	}
+	for _, supported := range s.StartupTypes {
+		if supported == startupType {
+			return true
+		}
+	}
+	return false
+}
+
 // ServiceCategory represents the logical grouping of services
 type ServiceCategory string
 
@@ -56,15 +97,27 @@ const (
 
 // Service represents a database service with its current state
 type Service struct {
-	Name        string          `json:"Name"`
-	DisplayName string          `json:"DisplayName"`
-	Status      ServiceStatus   `json:"Status"`
-	Type        ServiceType     `json:"Type"`
-	StartupType StartupType     `json:"StartupType"`
-	Category    ServiceCategory `json:"Category"`
+	Name            string              `json:"Name"`
+	DisplayName     string              `json:"DisplayName"`
+	Status          ServiceStatus       `json:"Status"`
+	Type            ServiceType         `json:"Type"`
+	StartupType     StartupType         `json:"StartupType"`
+	Category        ServiceCategory     `json:"Category"`
+	Backend         string              `json:"Backend"`
+	Version         string              `json:"Version,omitempty"`         // Product version of the service binary, if known
+	Match           *MatchExplanation   `json:"Match,omitempty"`           // Why the service was classified as its type
+	Instance        *ServiceInstance    `json:"Instance,omitempty"`        // Product installation the service belongs to
+	RelatedServices []string            `json:"RelatedServices,omitempty"` // Other services of the same instance, e.g. a SQL Server Agent
+	PID             uint32              `json:"PID,omitempty"`             // Process ID while running
+	Ports           []ListeningEndpoint `json:"Ports,omitempty"`           // Sockets the service's processes listen on
+	ExpectedPort    int                 `json:"ExpectedPort,omitempty"`    // TCP port the service listens on when started, if known
+	PublishedPort   int                 `json:"PublishedPort,omitempty"`   // Host port a container publishes its type's standard port on
+	CanPause        bool                `json:"CanPause,omitempty"`        // The service accepts pause and continue requests
+	StartupTypes    []StartupType       `json:"StartupTypes,omitempty"`    // Startup types the service's backend can set
 }
 
-// GetCategoryInfo returns metadata about a service category
+// GetCategoryInfo returns metadata about a service category.
+// Custom service types always belong to one of the built-in categories.
 func GetCategoryInfo(category ServiceCategory) map[string]string {
 	categoryInfo := map[ServiceCategory]map[string]string{
 		CategorySQL: {
@@ -105,38 +158,43 @@ func GetCategoryInfo(category ServiceCategory) map[string]string {
 	return map[string]string{"name": "Unknown", "description": "", "icon": "settings", "color": "#666666"}
 }
 
-// GetServiceCategory returns the category for a given service type
+// builtinServiceCategories maps the built-in service types to their categories
+var builtinServiceCategories = map[ServiceType]ServiceCategory{
+	// SQL Databases
+	TypeMSSQL:      CategorySQL,
+	TypePostgreSQL: CategorySQL,
+	TypeMySQL:      CategorySQL,
+	TypeMariaDB:    CategorySQL,
+	TypeOracle:     CategorySQL,
+	TypeDB2:        CategorySQL,
+	TypeFirebird:   CategorySQL,
+
+	// NoSQL Databases
+	TypeMongoDB:   CategoryNoSQL,
+	TypeCassandra: CategoryNoSQL,
+	TypeCouchDB:   CategoryNoSQL,
+	TypeNeo4j:     CategoryNoSQL,
+
+	// Cache & In-Memory
+	TypeRedis:     CategoryCache,
+	TypeMemcached: CategoryCache,
+	TypeSQLite:    CategoryCache,
+
+	// Search & Analytics
+	TypeElasticsearch: CategorySearch,
+	TypeInfluxDB:      CategorySearch,
+
+	// Message Brokers
+	TypeRabbitMQ: CategoryMessaging,
+}
+
+// GetServiceCategory returns the category for a given service type,
+// including custom types defined by detection rules
 func GetServiceCategory(serviceType ServiceType) ServiceCategory {
-	categoryMap := map[ServiceType]ServiceCategory{
-		// SQL Databases
-		TypeMSSQL:      CategorySQL,
-		TypePostgreSQL: CategorySQL,
-		TypeMySQL:      CategorySQL,
-		TypeMariaDB:    CategorySQL,
-		TypeOracle:     CategorySQL,
-		TypeDB2:        CategorySQL,
-		TypeFirebird:   CategorySQL,
-
-		// NoSQL Databases
-		TypeMongoDB:   CategoryNoSQL,
-		TypeCassandra: CategoryNoSQL,
-		TypeCouchDB:   CategoryNoSQL,
-		TypeNeo4j:     CategoryNoSQL,
-
-		// Cache & In-Memory
-		TypeRedis:     CategoryCache,
-		TypeMemcached: CategoryCache,
-		TypeSQLite:    CategoryCache,
-
-		// Search & Analytics
-		TypeElasticsearch: CategorySearch,
-		TypeInfluxDB:      CategorySearch,
-
-		// Message Brokers
-		TypeRabbitMQ: CategoryMessaging,
+	if category, exists := builtinServiceCategories[serviceType]; exists {
+		return category
 	}
-
-	if category, exists := categoryMap[serviceType]; exists {
+	if category, exists := customServiceCategory(serviceType); exists {
 		return category
 	}
 	return CategorySQL // Default fallback
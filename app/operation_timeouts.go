@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultOperationTimeout is how long a service may take to start, stop or restart
+// unless configured otherwise
+const defaultOperationTimeout = 30 * time.Second
+
+// OperationTimeoutConfig sets how long start, stop and restart operations wait for a service
+// to change state. A timeout for the service name takes precedence over one for its type,
+// which takes precedence over the default. Readiness probes have timeouts of their own.
+type OperationTimeoutConfig struct {
+	DefaultSeconds int                 `json:"default_seconds,omitempty"` // defaults to 30 seconds
+	Services       map[string]int      `json:"services,omitempty"`        // Seconds by service name
+	Types          map[ServiceType]int `json:"types,omitempty"`           // Seconds by service type
+}
+
+// timeout returns the operation timeout of a service
+func (c OperationTimeoutConfig) timeout(name string, serviceType ServiceType) time.Duration {
+	if seconds := c.Services[name]; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if seconds := c.Types[serviceType]; seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if c.DefaultSeconds > 0 {
+		return time.Duration(c.DefaultSeconds) * time.Second
+	}
+	return defaultOperationTimeout
+}
+
+// copy returns a copy that does not share the override maps
+func (c OperationTimeoutConfig) copy() OperationTimeoutConfig {
+	copied := OperationTimeoutConfig{DefaultSeconds: c.DefaultSeconds}
+	if c.Services != nil {
+		copied.Services = make(map[string]int, len(c.Services))
+		for name, seconds := range c.Services {
+			copied.Services[name] = seconds
+		}
+	}
+	if c.Types != nil {
+		copied.Types = make(map[ServiceType]int, len(c.Types))
+		for serviceType, seconds := range c.Types {
+			copied.Types[serviceType] = seconds
+		}
+	}
+	return copied
+}
+
+// validateOperationTimeouts checks that no timeout is negative
+func validateOperationTimeouts(config OperationTimeoutConfig) error {
+	if config.DefaultSeconds < 0 {
+		return fmt.Errorf("operation timeout: default cannot be negative")
+	}
+	for name, seconds := range config.Services {
+		if seconds < 0 {
+			return fmt.Errorf("operation timeout for service %q cannot be negative", name)
+		}
+	}
+	for serviceType, seconds := range config.Types {
+		if seconds < 0 {
+			return fmt.Errorf("operation timeout for type %q cannot be negative", serviceType)
+		}
+	}
+	return nil
+}
+
+// operationTimeout returns how long an operation on a service may wait for it to change state
+func (sm *ServiceManager) operationTimeout(name string) time.Duration {
+	if sm.configManager == nil {
+		return defaultOperationTimeout
+	}
+	serviceType, _ := sm.serviceType(name)
+	return sm.configManager.GetOperationTimeout(name, serviceType)
+}
+
+// withOperationTimeout runs an adapter operation with the timeout configured for the service.
+// A timeout error is extended with how long the operation waited and the last state of the service.
+func (sm *ServiceManager) withOperationTimeout(ctx context.Context, name string, op func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, sm.operationTimeout(name))
+	defer cancel()
+
+	started := time.Now()
+	err := op(ctx)
+
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrOperationTimeout {
+		return err
+	}
+
+	detail := fmt.Sprintf("waited %s", time.Since(started).Round(100*time.Millisecond))
+	if status, statusErr := sm.adapter.GetServiceStatus(name); statusErr == nil {
+		detail += fmt.Sprintf(", last state %s", status)
+	}
+	return withErrorDetail(err, name, detail)
+}
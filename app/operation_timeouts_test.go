@@ -0,0 +1,99 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOperationTimeoutConfigPrecedence(t *testing.T) {
+	config := OperationTimeoutConfig{
+		DefaultSeconds: 20,
+		Services:       map[string]int{"MSSQLSERVER": 90},
+		Types:          map[ServiceType]int{TypeMSSQL: 60, TypeRedis: 0},
+	}
+
+	tests := []struct {
+		name        string
+		service     string
+		serviceType ServiceType
+		expected    time.Duration
+	}{
+		{"service override", "MSSQLSERVER", TypeMSSQL, 90 * time.Second},
+		{"type override", "MSSQL$SQLEXPRESS", TypeMSSQL, 60 * time.Second},
+		{"zero falls through", "Redis", TypeRedis, 20 * time.Second},
+		{"configured default", "postgresql-x64-16", TypePostgreSQL, 20 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if timeout := config.timeout(tt.service, tt.serviceType); timeout != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, timeout)
+			}
+		})
+	}
+
+	if timeout := (OperationTimeoutConfig{}).timeout("Redis", TypeRedis); timeout != defaultOperationTimeout {
+		t.Errorf("Expected the built-in default %s, got %s", defaultOperationTimeout, timeout)
+	}
+}
+
+func TestValidateOperationTimeouts(t *testing.T) {
+	invalid := []OperationTimeoutConfig{
+		{DefaultSeconds: -1},
+		{Services: map[string]int{"Redis": -5}},
+		{Types: map[ServiceType]int{TypeRedis: -5}},
+	}
+	for _, config := range invalid {
+		if err := validateOperationTimeouts(config); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+
+	cm, _ := createTestConfigManager(t)
+	if err := cm.SetOperationTimeouts(OperationTimeoutConfig{DefaultSeconds: -1}); err == nil {
+		t.Error("Expected SetOperationTimeouts() to reject a negative default")
+	}
+}
+
+func TestConfigManagerOperationTimeouts(t *testing.T) {
+	cm, _ := createTestConfigManager(t)
+
+	timeouts := OperationTimeoutConfig{Types: map[ServiceType]int{TypeMSSQL: 120}}
+	if err := cm.SetOperationTimeouts(timeouts); err != nil {
+		t.Fatalf("SetOperationTimeouts() failed: %v", err)
+	}
+
+	// The saved overrides are not shared with the caller
+	timeouts.Types[TypeMSSQL] = 5
+	if timeout := cm.GetOperationTimeout("MSSQLSERVER", TypeMSSQL); timeout != 120*time.Second {
+		t.Errorf("Expected 2m0s, got %s", timeout)
+	}
+
+	if err := cm.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	if timeout := cm.GetOperationTimeout("MSSQLSERVER", TypeMSSQL); timeout != 120*time.Second {
+		t.Errorf("Expected the timeout to persist, got %s", timeout)
+	}
+}
+
+func TestServiceManagerOperationTimeoutDetail(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "MSSQLSERVER", Status: StatusRunning})
+	adapter.pending["stop MSSQLSERVER"] = make(chan struct{})
+	sm := createTestServiceManager(t, adapter)
+
+	timeouts := OperationTimeoutConfig{Services: map[string]int{"MSSQLSERVER": 1}}
+	if err := sm.configManager.SetOperationTimeouts(timeouts); err != nil {
+		t.Fatalf("SetOperationTimeouts() failed: %v", err)
+	}
+
+	err := sm.WaitForOperation(sm.StopService("MSSQLSERVER"))
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Code != ErrOperationTimeout {
+		t.Fatalf("Expected ErrOperationTimeout, got %v", err)
+	}
+	if !strings.Contains(serviceErr.Message, "waited 1") || !strings.Contains(serviceErr.Message, "last state running") {
+		t.Errorf("Expected the wait and last state in %q", serviceErr.Message)
+	}
+}
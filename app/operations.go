@@ -0,0 +1,229 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// OperationEventName is the Wails event emitted when a control operation finishes
+	OperationEventName = "operation:finished"
+
+	// maxFinishedOperations is how many finished operations are kept for WaitForOperation
+	maxFinishedOperations = 100
+)
+
+// OperationState is the progress of a control operation
+type OperationState string
+
+const (
+	OperationRunning   OperationState = "running"
+	OperationSucceeded OperationState = "succeeded"
+	OperationFailed    OperationState = "failed"
+	OperationCanceled  OperationState = "canceled"
+)
+
+// Operation describes a control operation that is running or finished recently
+type Operation struct {
+	ID         string         `json:"ID"`
+	Operation  string         `json:"Operation"`
+	Service    string         `json:"Service"`
+	Initiator  Initiator      `json:"Initiator"`
+	State      OperationState `json:"State"`
+	StartedAt  time.Time      `json:"StartedAt"`
+	FinishedAt time.Time      `json:"FinishedAt,omitempty"`
+	ErrorCode  *ErrorCode     `json:"ErrorCode,omitempty"`
+	Error      string         `json:"Error,omitempty"`
+}
+
+// trackedOperation is an operation together with the means to cancel and await it
+type trackedOperation struct {
+	Operation
+	seq    uint64 // Orders operations by when they began
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// operationTracker assigns IDs to control operations so that they can be listed, awaited and canceled
+type operationTracker struct {
+	mu         sync.Mutex
+	nextID     uint64
+	operations map[string]*trackedOperation
+	finished   []string // IDs of finished operations, oldest first
+}
+
+// newOperationTracker creates an empty operation tracker
+func newOperationTracker() *operationTracker {
+	return &operationTracker{operations: make(map[string]*trackedOperation)}
+}
+
+// begin registers a running operation and returns it with the context it runs in,
+// which ends when ctx does or when the operation is canceled
+func (t *operationTracker) begin(ctx context.Context, operation, name string, initiator Initiator) (*trackedOperation, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.nextID++
+	op := &trackedOperation{
+		Operation: Operation{
+			ID:        fmt.Sprintf("op-%d", t.nextID),
+			Operation: operation,
+			Service:   name,
+			Initiator: initiator,
+			State:     OperationRunning,
+			StartedAt: time.Now(),
+		},
+		seq:    t.nextID,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	t.operations[op.ID] = op
+	return op, ctx
+}
+
+// finish records the outcome of an operation, wakes its waiters and returns its final description
+func (t *operationTracker) finish(op *trackedOperation, err error) Operation {
+	op.cancel()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	op.err = err
+	op.FinishedAt = time.Now()
+	op.State = OperationSucceeded
+	if err != nil {
+		op.State = OperationFailed
+		op.Error = err.Error()
+
+		var serviceErr *ServiceError
+		if errors.As(err, &serviceErr) {
+			code := serviceErr.Code
+			op.ErrorCode = &code
+			if code == ErrOperationCanceled {
+				op.State = OperationCanceled
+			}
+		}
+	}
+	close(op.done)
+
+	t.finished = append(t.finished, op.ID)
+	for len(t.finished) > maxFinishedOperations {
+		delete(t.operations, t.finished[0])
+		t.finished = t.finished[1:]
+	}
+	return op.Operation
+}
+
+// lookup returns a known operation
+func (t *operationTracker) lookup(id string) (*trackedOperation, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	op, exists := t.operations[id]
+	if !exists {
+		return nil, &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Unknown operation: %s", id),
+		}
+	}
+	return op, nil
+}
+
+// cancel cancels a running operation. The operation finishes with an ErrOperationCanceled
+// error as soon as it notices.
+func (t *operationTracker) cancel(id string) error {
+	op, err := t.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-op.done:
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Operation %s has already finished", id),
+			Service: op.Service,
+		}
+	default:
+	}
+
+	op.cancel()
+	return nil
+}
+
+// wait blocks until an operation finishes and returns its error
+func (t *operationTracker) wait(ctx context.Context, id string) error {
+	op, err := t.lookup(id)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-op.done:
+		return op.err
+	case <-ctx.Done():
+		return contextError(ctx, op.Service)
+	}
+}
+
+// list returns the running and recently finished operations, oldest first
+func (t *operationTracker) list() []Operation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked := make([]*trackedOperation, 0, len(t.operations))
+	for _, op := range t.operations {
+		tracked = append(tracked, op)
+	}
+	sort.Slice(tracked, func(i, j int) bool { return tracked[i].seq < tracked[j].seq })
+
+	operations := make([]Operation, len(tracked))
+	for i, op := range tracked {
+		operations[i] = op.Operation
+	}
+	return operations
+}
+
+// stopRequested reports whether an operation that stops a service is running or finished
+// less than grace ago
+func (t *operationTracker) stopRequested(name string, grace time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, op := range t.operations {
+		if op.Service != name {
+			continue
+		}
+		switch op.Operation.Operation {
+		case "stop", "restart", "force-stop":
+			if op.State == OperationRunning || time.Since(op.FinishedAt) < grace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetOperations returns the running and recently finished control operations
+func (sm *ServiceManager) GetOperations() []Operation {
+	return sm.operations.list()
+}
+
+// WaitForOperation waits until an operation started by StartService, StopService or
+// RestartService finishes and returns its error
+func (sm *ServiceManager) WaitForOperation(id string) error {
+	return sm.operations.wait(sm.baseContext(), id)
+}
+
+// CancelOperation cancels a running operation. Waiting for the service stops immediately,
+// but a state change the OS has already begun may still complete.
+func (sm *ServiceManager) CancelOperation(id string) error {
+	return sm.operations.cancel(id)
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitForOperationState polls until an operation reaches a state
+func waitForOperationState(t *testing.T, sm *ServiceManager, id string, state OperationState) Operation {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, op := range sm.GetOperations() {
+			if op.ID == id && op.State == state {
+				return op
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Operation %s did not reach state %s: %+v", id, state, sm.GetOperations())
+	return Operation{}
+}
+
+func TestServiceManagerOperationSucceeds(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	sm := createTestServiceManager(t, adapter)
+
+	id := sm.StartService("Redis")
+	if id == "" {
+		t.Fatal("Expected an operation ID")
+	}
+	if err := sm.WaitForOperation(id); err != nil {
+		t.Fatalf("WaitForOperation() failed: %v", err)
+	}
+
+	op := waitForOperationState(t, sm, id, OperationSucceeded)
+	if op.Operation != "start" || op.Service != "Redis" || op.Initiator != InitiatorGUI || op.FinishedAt.IsZero() {
+		t.Errorf("Unexpected operation %+v", op)
+	}
+
+	// The audit entry refers to the operation
+	history, err := sm.GetHistory(AuditFilter{})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].OperationID != id {
+		t.Errorf("Expected an audit entry for %s, got %+v", id, history)
+	}
+}
+
+func TestServiceManagerCancelOperation(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	adapter.pending["start Redis"] = make(chan struct{})
+	sm := createTestServiceManager(t, adapter)
+
+	id := sm.StartService("Redis")
+	waitForOperationState(t, sm, id, OperationRunning)
+
+	if err := sm.CancelOperation(id); err != nil {
+		t.Fatalf("CancelOperation() failed: %v", err)
+	}
+
+	err := sm.WaitForOperation(id)
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrOperationCanceled {
+		t.Fatalf("Expected ErrOperationCanceled, got %v", err)
+	}
+	op := waitForOperationState(t, sm, id, OperationCanceled)
+	if op.ErrorCode == nil || *op.ErrorCode != ErrOperationCanceled {
+		t.Errorf("Expected the canceled error code, got %+v", op)
+	}
+
+	// A finished operation cannot be canceled again
+	if err := sm.CancelOperation(id); !errors.As(err, &serviceErr) || serviceErr.Code != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState, got %v", err)
+	}
+	if err := sm.CancelOperation("op-missing"); !errors.As(err, &serviceErr) || serviceErr.Code != ErrInvalidState {
+		t.Errorf("Expected ErrInvalidState for an unknown operation, got %v", err)
+	}
+}
+
+func TestServiceControlStopsWithContext(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusRunning})
+	adapter.pending["stop Redis"] = make(chan struct{})
+	sm := createTestServiceManager(t, adapter)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := sm.control(InitiatorAPI).withContext(ctx).StopService("Redis")
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrOperationTimeout {
+		t.Fatalf("Expected ErrOperationTimeout once the deadline passed, got %v", err)
+	}
+}
+
+func TestOperationTrackerKeepsRecentOperations(t *testing.T) {
+	tracker := newOperationTracker()
+	var first string
+	for i := 0; i < maxFinishedOperations+5; i++ {
+		op, _ := tracker.begin(context.Background(), "start", "Redis", InitiatorCLI)
+		if i == 0 {
+			first = op.ID
+		}
+		tracker.finish(op, nil)
+	}
+
+	operations := tracker.list()
+	if len(operations) != maxFinishedOperations {
+		t.Fatalf("Expected %d operations, got %d", maxFinishedOperations, len(operations))
+	}
+	if _, err := tracker.lookup(first); err == nil {
+		t.Error("Expected the oldest operation to be forgotten")
+	}
+	for i := 1; i < len(operations); i++ {
+		if operations[i-1].StartedAt.After(operations[i].StartedAt) {
+			t.Fatal("Expected operations oldest first")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+//go:build !windows && !linux
+
+package app
+
+import (
+	"context"
+	"runtime"
+)
+
+// unsupportedServiceAdapter is used on platforms without a service backend
+type unsupportedServiceAdapter struct{}
+
+// newPlatformServiceAdapter returns the service adapter for the current OS
+func newPlatformServiceAdapter() OSServiceAdapter {
+	return unsupportedServiceAdapter{}
+}
+
+// unsupported returns the error reported by every operation on this platform
+func (unsupportedServiceAdapter) unsupported(name string) error {
+	return &ServiceError{
+		Code:    ErrSystemError,
+		Message: "Service control is not supported on " + runtime.GOOS,
+		Service: name,
+	}
+}
+
+func (a unsupportedServiceAdapter) ListServices() ([]OSService, error) {
+	return nil, a.unsupported("")
+}
+
+func (a unsupportedServiceAdapter) GetServiceStatus(name string) (ServiceStatus, error) {
+	return StatusStopped, a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) GetStartupType(name string) (StartupType, error) {
+	return StartupDisabled, a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) StartService(ctx context.Context, name string) error {
+	return a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) StopService(ctx context.Context, name string) error {
+	return a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) RestartService(ctx context.Context, name string) error {
+	return a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) PauseService(ctx context.Context, name string) error {
+	return a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) ContinueService(ctx context.Context, name string) error {
+	return a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) SetStartupType(name string, startupType StartupType) error {
+	return a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) GetDependencies(name string) ([]string, error) {
+	return nil, a.unsupported(name)
+}
+
+func (a unsupportedServiceAdapter) GetDependents(name string) ([]string, error) {
+	return nil, a.unsupported(name)
+}
@@ -0,0 +1,265 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeServiceAdapter is an in-memory OSServiceAdapter for tests
+type fakeServiceAdapter struct {
+	mu       sync.Mutex
+	services map[string]*OSService
+	startup  map[string]StartupType
+	order    []string
+	calls    []string
+	failOn   map[string]error
+	listErr  error
+	requires map[string][]string      // Dependencies of each service
+	pending  map[string]chan struct{} // Operations that stay pending until released or canceled
+}
+
+func newFakeServiceAdapter(services ...OSService) *fakeServiceAdapter {
+	f := &fakeServiceAdapter{
+		services: make(map[string]*OSService),
+		startup:  make(map[string]StartupType),
+		failOn:   make(map[string]error),
+		requires: make(map[string][]string),
+		pending:  make(map[string]chan struct{}),
+	}
+	for i := range services {
+		service := services[i]
+		f.services[service.Name] = &service
+		f.startup[service.Name] = StartupManual
+		f.order = append(f.order, service.Name)
+	}
+	return f
+}
+
+// record logs a call and returns any injected failure for it
+func (f *fakeServiceAdapter) record(op, name string) (*OSService, error) {
+	f.calls = append(f.calls, op+" "+name)
+	if err := f.failOn[op+" "+name]; err != nil {
+		return nil, err
+	}
+	service, exists := f.services[name]
+	if !exists {
+		return nil, &ServiceError{Code: ErrServiceNotFound, Message: fmt.Sprintf("Service not found: %s", name), Service: name}
+	}
+	return service, nil
+}
+
+// waitPending blocks an operation listed in pending until it is released or ctx ends
+func (f *fakeServiceAdapter) waitPending(ctx context.Context, op, name string) error {
+	f.mu.Lock()
+	release, exists := f.pending[op+" "+name]
+	f.mu.Unlock()
+	if !exists {
+		return nil
+	}
+
+	select {
+	case <-release:
+		return nil
+	case <-ctx.Done():
+		return contextError(ctx, name)
+	}
+}
+
+// setStatus changes a service status behind the adapter's back
+func (f *fakeServiceAdapter) setStatus(name string, status ServiceStatus) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.services[name].Status = status
+}
+
+// callLog returns a copy of the recorded calls
+func (f *fakeServiceAdapter) callLog() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.calls...)
+}
+
+func (f *fakeServiceAdapter) ListServices() ([]OSService, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+	services := make([]OSService, 0, len(f.order))
+	for _, name := range f.order {
+		services = append(services, *f.services[name])
+	}
+	return services, nil
+}
+
+func (f *fakeServiceAdapter) GetServiceStatus(name string) (ServiceStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	service, exists := f.services[name]
+	if !exists {
+		return StatusStopped, &ServiceError{Code: ErrServiceNotFound, Message: fmt.Sprintf("Service not found: %s", name), Service: name}
+	}
+	return service.Status, nil
+}
+
+func (f *fakeServiceAdapter) GetStartupType(name string) (StartupType, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.services[name]; !exists {
+		return StartupDisabled, &ServiceError{Code: ErrServiceNotFound, Message: fmt.Sprintf("Service not found: %s", name), Service: name}
+	}
+	return f.startup[name], nil
+}
+
+func (f *fakeServiceAdapter) StartService(ctx context.Context, name string) error {
+	if err := f.waitPending(ctx, "start", name); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	service, err := f.record("start", name)
+	if err != nil {
+		return err
+	}
+	if service.Status == StatusRunning {
+		return &ServiceError{Code: ErrInvalidState, Message: "Service is already running", Service: name}
+	}
+	service.Status = StatusRunning
+	return nil
+}
+
+func (f *fakeServiceAdapter) StopService(ctx context.Context, name string) error {
+	if err := f.waitPending(ctx, "stop", name); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	service, err := f.record("stop", name)
+	if err != nil {
+		return err
+	}
+	if service.Status == StatusStopped {
+		return &ServiceError{Code: ErrInvalidState, Message: "Service is already stopped", Service: name}
+	}
+	// Like the SCM, refuse to stop a service while services that depend on it are running
+	for _, dependent := range f.order {
+		for _, dependency := range f.requires[dependent] {
+			if dependency == name && f.services[dependent].Status == StatusRunning {
+				return &ServiceError{Code: ErrSystemError, Message: "A stop control has been sent to a service that other running services are dependent on", Service: name}
+			}
+		}
+	}
+	service.Status = StatusStopped
+	return nil
+}
+
+func (f *fakeServiceAdapter) RestartService(ctx context.Context, name string) error {
+	if err := f.waitPending(ctx, "restart", name); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	service, err := f.record("restart", name)
+	if err != nil {
+		return err
+	}
+	service.Status = StatusRunning
+	return nil
+}
+
+func (f *fakeServiceAdapter) PauseService(ctx context.Context, name string) error {
+	return f.pauseOrContinue(ctx, "pause", name, StatusRunning, StatusPaused)
+}
+
+func (f *fakeServiceAdapter) ContinueService(ctx context.Context, name string) error {
+	return f.pauseOrContinue(ctx, "continue", name, StatusPaused, StatusRunning)
+}
+
+// pauseOrContinue moves a pausable service from the required status to the target status
+func (f *fakeServiceAdapter) pauseOrContinue(ctx context.Context, op, name string, required, target ServiceStatus) error {
+	if err := f.waitPending(ctx, op, name); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	service, err := f.record(op, name)
+	if err != nil {
+		return err
+	}
+	if !service.CanPause || service.Status != required {
+		return &ServiceError{Code: ErrInvalidState, Message: fmt.Sprintf("Cannot %s a %s service", op, service.Status), Service: name}
+	}
+	service.Status = target
+	return nil
+}
+
+func (f *fakeServiceAdapter) SetStartupType(name string, startupType StartupType) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.record("startup-type", name); err != nil {
+		return err
+	}
+	f.startup[name] = startupType
+	return nil
+}
+
+func (f *fakeServiceAdapter) GetDependencies(name string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.services[name]; !exists {
+		return nil, &ServiceError{Code: ErrServiceNotFound, Message: fmt.Sprintf("Service not found: %s", name), Service: name}
+	}
+	return append([]string(nil), f.requires[name]...), nil
+}
+
+func (f *fakeServiceAdapter) GetDependents(name string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, exists := f.services[name]; !exists {
+		return nil, &ServiceError{Code: ErrServiceNotFound, Message: fmt.Sprintf("Service not found: %s", name), Service: name}
+	}
+	var dependents []string
+	for _, dependent := range f.order {
+		for _, dependency := range f.requires[dependent] {
+			if dependency == name {
+				dependents = append(dependents, dependent)
+			}
+		}
+	}
+	return dependents, nil
+}
+
+func TestSplitServiceCommandLine(t *testing.T) {
+	tests := []struct {
+		commandLine string
+		binary      string
+		args        []string
+	}{
+		{`"C:\Program Files\PostgreSQL\16\bin\pg_ctl.exe" runservice -N "postgresql-x64-16" -D "C:\Program Files\PostgreSQL\16\data" -w`,
+			`C:\Program Files\PostgreSQL\16\bin\pg_ctl.exe`, []string{"runservice", "-N", "postgresql-x64-16", "-D", `C:\Program Files\PostgreSQL\16\data`, "-w"}},
+		{`C:\Program Files\MySQL\MySQL Server 8.0\bin\mysqld.exe --defaults-file="C:\ProgramData\MySQL\my.ini" MySQL80`,
+			`C:\Program Files\MySQL\MySQL Server 8.0\bin\mysqld.exe`, []string{`--defaults-file=C:\ProgramData\MySQL\my.ini`, "MySQL80"}},
+		{`"C:\Program Files\Microsoft SQL Server\MSSQL16.SQLEXPRESS\MSSQL\Binn\sqlservr.exe" -sSQLEXPRESS`,
+			`C:\Program Files\Microsoft SQL Server\MSSQL16.SQLEXPRESS\MSSQL\Binn\sqlservr.exe`, []string{"-sSQLEXPRESS"}},
+		{`C:\Redis\redis-server.exe`, `C:\Redis\redis-server.exe`, nil},
+		{`C:\Windows\system32\svchost.exe -k netsvcs -p`, `C:\Windows\system32\svchost.exe`, []string{"-k", "netsvcs", "-p"}},
+		{`/usr/lib/postgresql/16/bin/postgres -D /var/lib/postgresql/16/main`, `/usr/lib/postgresql/16/bin/postgres`, []string{"-D", "/var/lib/postgresql/16/main"}},
+		{`"C:\Unterminated\app.exe`, `C:\Unterminated\app.exe`, nil},
+		{"", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.commandLine, func(t *testing.T) {
+			binary, args := splitServiceCommandLine(tt.commandLine)
+			if binary != tt.binary {
+				t.Errorf("Expected binary %q, got %q", tt.binary, binary)
+			}
+			if strings.Join(args, "|") != strings.Join(tt.args, "|") || len(args) != len(tt.args) {
+				t.Errorf("Expected arguments %q, got %q", tt.args, args)
+			}
+		})
+	}
+}
@@ -0,0 +1,603 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// WindowsServiceAdapter implements OSServiceAdapter for Windows
+type WindowsServiceAdapter struct {
+	// No persistent connection - we'll connect per operation
+}
+
+// NewWindowsServiceAdapter creates a new Windows service adapter
+func NewWindowsServiceAdapter() *WindowsServiceAdapter {
+	return &WindowsServiceAdapter{}
+}
+
+// newPlatformServiceAdapter returns the service adapter for the current OS
+func newPlatformServiceAdapter() OSServiceAdapter {
+	return NewWindowsServiceAdapter()
+}
+
+// connectSCM establishes a connection to the Windows Service Control Manager
+func (w *WindowsServiceAdapter) connectSCM() (*mgr.Mgr, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrPermissionDenied,
+			Message: "Failed to connect to Service Control Manager. Administrator privileges may be required.",
+		}
+	}
+	return m, nil
+}
+
+// openService opens a specific service with proper error handling
+func (w *WindowsServiceAdapter) openService(m *mgr.Mgr, name string) (*mgr.Service, error) {
+	s, err := m.OpenService(name)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("Service not found: %s", name),
+			Service: name,
+		}
+	}
+	return s, nil
+}
+
+// ListServices retrieves all Windows services with optimized memory usage
+func (w *WindowsServiceAdapter) ListServices() ([]OSService, error) {
+	m, err := w.connectSCM()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+
+	// List all services
+	serviceNames, err := m.ListServices()
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to list services: %v", err),
+		}
+	}
+
+	// Pre-allocate with exact capacity for better memory efficiency
+	services := make([]OSService, 0, len(serviceNames))
+
+	// Process services in smaller batches to reduce memory pressure
+	batchSize := 50
+	for i := 0; i < len(serviceNames); i += batchSize {
+		end := i + batchSize
+		if end > len(serviceNames) {
+			end = len(serviceNames)
+		}
+
+		for j := i; j < end; j++ {
+			name := serviceNames[j]
+			s, err := m.OpenService(name)
+			if err != nil {
+				// Skip services we can't open (likely permission issues)
+				continue
+			}
+
+			// Get service status with timeout protection
+			status, err := s.Query()
+			if err != nil {
+				s.Close()
+				// Skip services we can't query
+				continue
+			}
+
+			service := OSService{
+				Name:         name,
+				DisplayName:  name,
+				Status:       mapWindowsStateToStatus(status.State),
+				PID:          status.ProcessId,
+				CanPause:     status.Accepts&svc.AcceptPauseAndContinue != 0,
+				StartupTypes: windowsStartupTypes,
+			}
+
+			if config, err := s.Config(); err == nil {
+				if config.DisplayName != "" {
+					service.DisplayName = config.DisplayName
+				}
+				service.BinaryPath, service.Arguments = splitServiceCommandLine(expandEnvironmentStrings(config.BinaryPathName))
+				service.Account = config.ServiceStartName
+			}
+
+			s.Close()
+
+			services = append(services, service)
+		}
+	}
+
+	return services, nil
+}
+
+// expandEnvironmentStrings expands %VARIABLE% references such as %SystemRoot% in an ImagePath
+func expandEnvironmentStrings(value string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+
+	src, err := windows.UTF16PtrFromString(value)
+	if err != nil {
+		return value
+	}
+	n, err := windows.ExpandEnvironmentStrings(src, nil, 0)
+	if err != nil || n == 0 {
+		return value
+	}
+	dst := make([]uint16, n)
+	if _, err := windows.ExpandEnvironmentStrings(src, &dst[0], n); err != nil {
+		return value
+	}
+	return windows.UTF16ToString(dst)
+}
+
+// GetServiceStatus retrieves the current status of a specific service
+func (w *WindowsServiceAdapter) GetServiceStatus(name string) (ServiceStatus, error) {
+	m, err := w.connectSCM()
+	if err != nil {
+		return StatusStopped, err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return StatusStopped, err
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return StatusStopped, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to query service status: %v", err),
+			Service: name,
+		}
+	}
+
+	return mapWindowsStateToStatus(status.State), nil
+}
+
+// mapWindowsStateToStatus converts Windows service state to our ServiceStatus enum
+func mapWindowsStateToStatus(state svc.State) ServiceStatus {
+	switch state {
+	case svc.Running:
+		return StatusRunning
+	case svc.Stopped:
+		return StatusStopped
+	case svc.StartPending:
+		return StatusStarting
+	case svc.StopPending:
+		return StatusStopping
+	case svc.Paused:
+		return StatusPaused
+	case svc.PausePending:
+		return StatusPausing
+	case svc.ContinuePending:
+		return StatusContinuing
+	default:
+		return StatusStopped
+	}
+}
+
+// StartService starts a Windows service and waits until it is running, following the
+// progress the service reports to the SCM
+func (w *WindowsServiceAdapter) StartService(ctx context.Context, name string) error {
+	m, err := w.connectSCM()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Check current state
+	status, err := s.Query()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to query service status: %v", err),
+			Service: name,
+		}
+	}
+
+	// Validate state - can't start if already running or starting
+	if status.State == svc.Running {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already running",
+			Service: name,
+		}
+	}
+
+	if status.State == svc.StartPending {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already starting",
+			Service: name,
+		}
+	}
+
+	// Start the service
+	err = s.Start()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to start service: %v", err),
+			Service: name,
+		}
+	}
+
+	return w.waitForState(ctx, s, name, "start", StatusRunning, StatusStarting)
+}
+
+// StopService stops a Windows service and waits until it is stopped, following the
+// progress the service reports to the SCM
+func (w *WindowsServiceAdapter) StopService(ctx context.Context, name string) error {
+	m, err := w.connectSCM()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Check current state
+	status, err := s.Query()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to query service status: %v", err),
+			Service: name,
+		}
+	}
+
+	// Validate state - can't stop if already stopped or stopping
+	if status.State == svc.Stopped {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already stopped",
+			Service: name,
+		}
+	}
+
+	if status.State == svc.StopPending {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already stopping",
+			Service: name,
+		}
+	}
+
+	// Stop the service
+	_, err = s.Control(svc.Stop)
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to stop service: %v", err),
+			Service: name,
+		}
+	}
+
+	return w.waitForState(ctx, s, name, "stop", StatusStopped, StatusStopping)
+}
+
+// PauseService pauses a running Windows service and waits until it is paused
+func (w *WindowsServiceAdapter) PauseService(ctx context.Context, name string) error {
+	return w.pauseOrContinue(ctx, name, "pause", svc.Pause, svc.Running, StatusPaused, StatusPausing)
+}
+
+// ContinueService resumes a paused Windows service and waits until it is running
+func (w *WindowsServiceAdapter) ContinueService(ctx context.Context, name string) error {
+	return w.pauseOrContinue(ctx, name, "continue", svc.Continue, svc.Paused, StatusRunning, StatusContinuing)
+}
+
+// pauseOrContinue sends a pause or continue control to a service in the required state and
+// waits for the target status
+func (w *WindowsServiceAdapter) pauseOrContinue(ctx context.Context, name, action string, control svc.Cmd, required svc.State, target, pending ServiceStatus) error {
+	m, err := w.connectSCM()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to query service status: %v", err),
+			Service: name,
+		}
+	}
+
+	if status.Accepts&svc.AcceptPauseAndContinue == 0 {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service does not accept pause and continue requests",
+			Service: name,
+		}
+	}
+	if status.State != required {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Service is %s, cannot %s it", mapWindowsStateToStatus(status.State), action),
+			Service: name,
+		}
+	}
+
+	if _, err := s.Control(control); err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to %s service: %v", action, err),
+			Service: name,
+		}
+	}
+
+	return w.waitForState(ctx, s, name, action, target, pending)
+}
+
+// RestartService restarts a Windows service (stop then start sequence)
+func (w *WindowsServiceAdapter) RestartService(ctx context.Context, name string) error {
+	m, err := w.connectSCM()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Check current state
+	status, err := s.Query()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to query service status: %v", err),
+			Service: name,
+		}
+	}
+
+	// If service is running, stop it first
+	if status.State == svc.Running || status.State == svc.StartPending {
+		_, err = s.Control(svc.Stop)
+		if err != nil {
+			return &ServiceError{
+				Code:    ErrSystemError,
+				Message: fmt.Sprintf("Failed to stop service during restart: %v", err),
+				Service: name,
+			}
+		}
+
+		if err := w.waitForState(ctx, s, name, "stop", StatusStopped, StatusStopping); err != nil {
+			return err
+		}
+	}
+
+	// Start the service
+	err = s.Start()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to start service during restart: %v", err),
+			Service: name,
+		}
+	}
+
+	return w.waitForState(ctx, s, name, "start", StatusRunning, StatusStarting)
+}
+
+// waitForState waits until a service reaches the target status, polling as often as its
+// wait hint suggests and failing once its check point stops advancing
+func (w *WindowsServiceAdapter) waitForState(ctx context.Context, s *mgr.Service, name, action string, target, pending ServiceStatus) error {
+	return waitForProgress(ctx, name, action, target, pending, func() (serviceProgress, error) {
+		// mgr.Service.Query leaves out the check point and wait hint
+		var status windows.SERVICE_STATUS_PROCESS
+		var needed uint32
+		err := windows.QueryServiceStatusEx(s.Handle, windows.SC_STATUS_PROCESS_INFO,
+			(*byte)(unsafe.Pointer(&status)), uint32(unsafe.Sizeof(status)), &needed)
+		if err != nil {
+			return serviceProgress{}, &ServiceError{
+				Code:    ErrSystemError,
+				Message: fmt.Sprintf("Failed to query service status: %v", err),
+				Service: name,
+			}
+		}
+
+		return serviceProgress{
+			Status:     mapWindowsStateToStatus(svc.State(status.CurrentState)),
+			CheckPoint: status.CheckPoint,
+			WaitHint:   time.Duration(status.WaitHint) * time.Millisecond,
+		}, nil
+	})
+}
+
+// GetStartupType retrieves the startup type of a service
+func (w *WindowsServiceAdapter) GetStartupType(name string) (StartupType, error) {
+	m, err := w.connectSCM()
+	if err != nil {
+		return StartupDisabled, err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return StartupDisabled, err
+	}
+	defer s.Close()
+
+	config, err := s.Config()
+	if err != nil {
+		return StartupDisabled, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to get service configuration: %v", err),
+			Service: name,
+		}
+	}
+
+	switch config.StartType {
+	case mgr.StartAutomatic:
+		if config.DelayedAutoStart {
+			return StartupAutomaticDelayed, nil
+		}
+		return StartupAutomatic, nil
+	case mgr.StartManual:
+		return StartupManual, nil
+	case mgr.StartDisabled:
+		return StartupDisabled, nil
+	case windows.SERVICE_BOOT_START:
+		return StartupBoot, nil
+	case windows.SERVICE_SYSTEM_START:
+		return StartupSystem, nil
+	default:
+		return StartupManual, nil
+	}
+}
+
+// windowsStartupTypes are the startup types the Service Control Manager can set on a service
+var windowsStartupTypes = []StartupType{StartupAutomatic, StartupAutomaticDelayed, StartupManual, StartupDisabled}
+
+// SetStartupType changes the start type of a Windows service. Automatic (Delayed Start)
+// is an automatic start type with the DelayedAutoStart flag set.
+func (w *WindowsServiceAdapter) SetStartupType(name string, startupType StartupType) error {
+	var startType uint32
+	switch startupType {
+	case StartupAutomatic, StartupAutomaticDelayed:
+		startType = mgr.StartAutomatic
+	case StartupManual:
+		startType = mgr.StartManual
+	case StartupDisabled:
+		startType = mgr.StartDisabled
+	default:
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Startup type %s cannot be set", startupType),
+			Service: name,
+		}
+	}
+
+	m, err := w.connectSCM()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Get current config
+	config, err := s.Config()
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to get service configuration: %v", err),
+			Service: name,
+		}
+	}
+
+	// UpdateConfig also writes the delayed flag, which only applies to automatic services
+	config.StartType = startType
+	config.DelayedAutoStart = startupType == StartupAutomaticDelayed
+	err = s.UpdateConfig(config)
+	if err != nil {
+		return &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to set startup type to %s: %v", startupType, err),
+			Service: name,
+		}
+	}
+
+	return nil
+}
+
+// scGroupIdentifier prefixes load order groups in a service's dependency list
+const scGroupIdentifier = "+"
+
+// GetDependencies returns the services a Windows service depends on. Load order groups
+// are omitted since they cannot be controlled as services.
+func (w *WindowsServiceAdapter) GetDependencies(name string) ([]string, error) {
+	m, err := w.connectSCM()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	config, err := s.Config()
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to get service configuration: %v", err),
+			Service: name,
+		}
+	}
+
+	dependencies := make([]string, 0, len(config.Dependencies))
+	for _, dependency := range config.Dependencies {
+		if dependency != "" && !strings.HasPrefix(dependency, scGroupIdentifier) {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies, nil
+}
+
+// GetDependents returns the services that depend on a Windows service, directly or indirectly,
+// in the order the SCM would stop them
+func (w *WindowsServiceAdapter) GetDependents(name string) ([]string, error) {
+	m, err := w.connectSCM()
+	if err != nil {
+		return nil, err
+	}
+	defer m.Disconnect()
+
+	s, err := w.openService(m, name)
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	dependents, err := s.ListDependentServices(svc.AnyActivity)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to list dependent services: %v", err),
+			Service: name,
+		}
+	}
+	return dependents, nil
+}
@@ -0,0 +1,299 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Transport protocols of listening endpoints
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// maxProcessDepth bounds the walk up the process tree, guarding against PID reuse cycles
+const maxProcessDepth = 32
+
+// ListeningEndpoint is a local address on which a process accepts TCP connections or UDP datagrams
+type ListeningEndpoint struct {
+	Protocol string `json:"Protocol"` // "tcp" or "udp"
+	Address  string `json:"Address"`  // Local IP address, e.g. "0.0.0.0", "127.0.0.1" or "::"
+	Port     int    `json:"Port"`
+	PID      uint32 `json:"PID"`
+}
+
+// PortConflict is another process holding a port that a service needs
+type PortConflict struct {
+	Protocol string `json:"Protocol"`
+	Address  string `json:"Address"`
+	Port     int    `json:"Port"`
+	PID      uint32 `json:"PID"`
+	Process  string `json:"Process"` // Executable name of the holder, if it can be read
+}
+
+// processInfo is the part of a process table entry needed to attribute sockets to services
+type processInfo struct {
+	parentPID uint32
+	name      string
+}
+
+// tcpConnection is an established TCP connection, seen from the process owning its local end
+type tcpConnection struct {
+	localPort     int
+	remoteAddress string
+	remotePort    int
+	pid           uint32
+}
+
+// portScanner lists listening sockets, established connections and running processes of the operating system
+type portScanner struct {
+	listEndpoints   func() ([]ListeningEndpoint, error)
+	listConnections func() ([]tcpConnection, error)
+	listProcesses   func() (map[uint32]processInfo, error)
+}
+
+// newPortScanner creates a scanner for the current platform
+func newPortScanner() *portScanner {
+	return &portScanner{
+		listEndpoints:   listListeningEndpoints,
+		listConnections: listTCPConnections,
+		listProcesses:   listProcesses,
+	}
+}
+
+// portSnapshot is the set of listening sockets and processes at one point in time, and
+// the established connections if they were read
+type portSnapshot struct {
+	endpoints   []ListeningEndpoint
+	connections []tcpConnection
+	processes   map[uint32]processInfo
+}
+
+// snapshot reads the current listening sockets and processes
+func (s *portScanner) snapshot() (*portSnapshot, error) {
+	endpoints, err := s.listEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list listening sockets: %w", err)
+	}
+
+	// Without the process table, sockets are still attributed to the service's own process
+	processes, err := s.listProcesses()
+	if err != nil {
+		processes = nil
+	}
+	return &portSnapshot{endpoints: endpoints, processes: processes}, nil
+}
+
+// connectionSnapshot reads the current listening sockets and processes together with the
+// established TCP connections
+func (s *portScanner) connectionSnapshot() (*portSnapshot, error) {
+	snapshot, err := s.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.connections, err = s.listConnections(); err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	return snapshot, nil
+}
+
+// inProcessTree reports whether pid is root or one of its descendants. Services such as
+// PostgreSQL on Windows run a wrapper (pg_ctl) whose child process owns the sockets.
+func (p *portSnapshot) inProcessTree(pid, root uint32) bool {
+	for depth := 0; depth < maxProcessDepth; depth++ {
+		if pid == root {
+			return true
+		}
+		info, exists := p.processes[pid]
+		if !exists || info.parentPID == 0 || info.parentPID == pid {
+			return false
+		}
+		pid = info.parentPID
+	}
+	return false
+}
+
+// endpointsOf returns the endpoints of a process and its descendants, ordered by protocol, port and address
+func (p *portSnapshot) endpointsOf(pid uint32) []ListeningEndpoint {
+	if pid == 0 {
+		return nil
+	}
+
+	var endpoints []ListeningEndpoint
+	for _, endpoint := range p.endpoints {
+		if p.inProcessTree(endpoint.PID, pid) {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	sortEndpoints(endpoints)
+	return endpoints
+}
+
+// clientConnections counts the established connections to the TCP ports a process and its
+// descendants listen on, skipping clients in the excluded networks. It also reports whether
+// the process listens on any TCP port at all; without one, use cannot be measured.
+func (p *portSnapshot) clientConnections(pid uint32, excluded []*net.IPNet) (int, bool) {
+	ports := make(map[int]bool)
+	for _, endpoint := range p.endpointsOf(pid) {
+		if endpoint.Protocol == ProtocolTCP {
+			ports[endpoint.Port] = true
+		}
+	}
+	if len(ports) == 0 {
+		return 0, false
+	}
+
+	count := 0
+	for _, connection := range p.connections {
+		if !ports[connection.localPort] || !p.inProcessTree(connection.pid, pid) {
+			continue
+		}
+		if clientExcluded(connection.remoteAddress, excluded) {
+			continue
+		}
+		count++
+	}
+	return count, true
+}
+
+// clientExcluded reports whether a client address lies in one of the excluded networks
+func clientExcluded(address string, excluded []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	// IPv4 clients of dual-stack sockets appear as ::ffff:a.b.c.d
+	if ipv4 := ip.To4(); ipv4 != nil {
+		ip = ipv4
+	}
+	for _, network := range excluded {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts returns the TCP endpoints on a port held by processes outside the service's own
+// process tree. While the service runs, endpoints whose owner could not be read are taken to be
+// its own sockets rather than conflicts.
+func (p *portSnapshot) conflicts(port int, ownPID uint32) []PortConflict {
+	conflicts := []PortConflict{}
+	for _, endpoint := range p.endpoints {
+		if endpoint.Protocol != ProtocolTCP || endpoint.Port != port {
+			continue
+		}
+		if ownPID != 0 && (endpoint.PID == 0 || p.inProcessTree(endpoint.PID, ownPID)) {
+			continue
+		}
+		conflicts = append(conflicts, PortConflict{
+			Protocol: endpoint.Protocol,
+			Address:  endpoint.Address,
+			Port:     endpoint.Port,
+			PID:      endpoint.PID,
+			Process:  p.processes[endpoint.PID].name,
+		})
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Protocol != conflicts[j].Protocol {
+			return conflicts[i].Protocol < conflicts[j].Protocol
+		}
+		if conflicts[i].PID != conflicts[j].PID {
+			return conflicts[i].PID < conflicts[j].PID
+		}
+		return conflicts[i].Address < conflicts[j].Address
+	})
+	return conflicts
+}
+
+// sortEndpoints orders endpoints by protocol, port and address
+func sortEndpoints(endpoints []ListeningEndpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Protocol != endpoints[j].Protocol {
+			return endpoints[i].Protocol < endpoints[j].Protocol
+		}
+		if endpoints[i].Port != endpoints[j].Port {
+			return endpoints[i].Port < endpoints[j].Port
+		}
+		return endpoints[i].Address < endpoints[j].Address
+	})
+}
+
+// describePortConflicts summarises conflicts for an error message, listing each holder once,
+// e.g. "port 5432 is already in use by postgres (PID 812)"
+func describePortConflicts(conflicts []PortConflict) string {
+	var holders []string
+	seen := make(map[uint32]bool)
+	for _, conflict := range conflicts {
+		if seen[conflict.PID] {
+			continue
+		}
+		seen[conflict.PID] = true
+
+		process := conflict.Process
+		if process == "" {
+			process = "another process"
+		}
+		holders = append(holders, fmt.Sprintf("%s (PID %d)", process, conflict.PID))
+	}
+	if len(holders) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("port %d is already in use by %s", conflicts[0].Port, strings.Join(holders, ", "))
+}
+
+// portFlags are the command-line options database servers take their port from,
+// e.g. "postgres -p 5433", "mysqld --port=3307" or "redis-server --port 6380"
+var portFlags = map[string]bool{
+	"-p":     true,
+	"-port":  true,
+	"--port": true,
+}
+
+// commandLinePort returns the port passed on a service's command line, or 0
+func commandLinePort(arguments []string) int {
+	for i, argument := range arguments {
+		flag, value, hasValue := strings.Cut(argument, "=")
+		if !portFlags[flag] {
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(arguments) {
+				return 0
+			}
+			value = arguments[i+1]
+		}
+		if port, err := strconv.Atoi(value); err == nil && port > 0 && port <= 65535 {
+			return port
+		}
+	}
+	return 0
+}
+
+// expectedServicePort returns the TCP port a service will listen on once started, or 0 if it
+// cannot be predicted. Named SQL Server instances and auxiliary components use dynamic ports
+// and containers publish theirs through Docker, so only default ports of native services count.
+func expectedServicePort(service *Service, osService *OSService) int {
+	if service.Backend == BackendDocker {
+		return 0
+	}
+	if service.Type == TypeMSSQL && (service.Instance == nil ||
+		service.Instance.Component != ComponentEngine || service.Instance.Name != mssqlDefaultInstance) {
+		return 0
+	}
+
+	if port := commandLinePort(osService.Arguments); port != 0 {
+		return port
+	}
+	return defaultServicePorts[service.Type]
+}
+
+// publishedServicePort returns the host port a container publishes the standard port of its
+// type on, or 0 for native services and containers that do not publish it
+func publishedServicePort(service *Service, osService *OSService) int {
+	return osService.PublishedPorts[defaultServicePorts[service.Type]]
+}
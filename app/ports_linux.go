@@ -0,0 +1,273 @@
+package app
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// procRoot is the mount point of procfs
+const procRoot = "/proc"
+
+// Socket states in /proc/net/tcp and /proc/net/udp
+const (
+	procTCPEstablished = "01" // TCP_ESTABLISHED
+	procTCPListen      = "0A" // TCP_LISTEN
+	procUDPBound       = "07" // TCP_CLOSE, used by UDP sockets that are not connected
+)
+
+// procNetTables are the socket tables to scan with their protocol and listening state
+var procNetTables = []struct {
+	file     string
+	protocol string
+	state    string
+}{
+	{"net/tcp", ProtocolTCP, procTCPListen},
+	{"net/tcp6", ProtocolTCP, procTCPListen},
+	{"net/udp", ProtocolUDP, procUDPBound},
+	{"net/udp6", ProtocolUDP, procUDPBound},
+}
+
+// listListeningEndpoints reads listening sockets from /proc/net and finds their owners
+// through the socket links in /proc/<pid>/fd
+func listListeningEndpoints() ([]ListeningEndpoint, error) {
+	sockets := make(map[uint64]ListeningEndpoint)
+	for _, table := range procNetTables {
+		file, err := os.Open(filepath.Join(procRoot, table.file))
+		if os.IsNotExist(err) {
+			continue // IPv6 disabled
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = parseProcNet(file, table.protocol, table.state, sockets)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", table.file, err)
+		}
+	}
+
+	owners := socketOwners(sockets)
+	endpoints := make([]ListeningEndpoint, 0, len(sockets))
+	for inode, endpoint := range sockets {
+		// Sockets of processes we may not inspect are reported without an owner
+		endpoint.PID = owners[inode]
+		endpoints = append(endpoints, endpoint)
+	}
+	sortEndpoints(endpoints)
+	return endpoints, nil
+}
+
+// procTCPTables are the TCP socket tables, scanned for established connections
+var procTCPTables = []string{"net/tcp", "net/tcp6"}
+
+// listTCPConnections reads established TCP connections from /proc/net and finds the
+// process owning their local end
+func listTCPConnections() ([]tcpConnection, error) {
+	sockets := make(map[uint64]tcpConnection)
+	for _, table := range procTCPTables {
+		file, err := os.Open(filepath.Join(procRoot, table))
+		if os.IsNotExist(err) {
+			continue // IPv6 disabled
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = parseProcConnections(file, sockets)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", table, err)
+		}
+	}
+
+	owners := socketOwners(sockets)
+	connections := make([]tcpConnection, 0, len(sockets))
+	for inode, connection := range sockets {
+		connection.pid = owners[inode]
+		connections = append(connections, connection)
+	}
+	return connections, nil
+}
+
+// parseProcConnections adds the established connections from a /proc/net TCP table to sockets, keyed by inode
+func parseProcConnections(r io.Reader, sockets map[uint64]tcpConnection) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // Header
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != procTCPEstablished {
+			continue
+		}
+
+		_, localPort, err := parseProcAddress(fields[1])
+		if err != nil {
+			return err
+		}
+		remoteAddress, remotePort, err := parseProcAddress(fields[2])
+		if err != nil {
+			return err
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+
+		sockets[inode] = tcpConnection{localPort: localPort, remoteAddress: remoteAddress.String(), remotePort: remotePort}
+	}
+	return scanner.Err()
+}
+
+// parseProcNet adds the sockets in the given state from a /proc/net table to sockets, keyed by inode
+func parseProcNet(r io.Reader, protocol, state string, sockets map[uint64]ListeningEndpoint) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Scan() // Header
+
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+
+		address, port, err := parseProcAddress(fields[1])
+		if err != nil {
+			return err
+		}
+		// Connected UDP sockets have a remote port and are not listening
+		if protocol == ProtocolUDP && !strings.HasSuffix(fields[2], ":0000") {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+
+		sockets[inode] = ListeningEndpoint{Protocol: protocol, Address: address.String(), Port: port}
+	}
+	return scanner.Err()
+}
+
+// parseProcAddress decodes "0100007F:1538" into 127.0.0.1 and 5432. The address is stored
+// as 32-bit words in host byte order and the port in hex.
+func parseProcAddress(value string) (net.IP, int, error) {
+	addressHex, portHex, found := strings.Cut(value, ":")
+	if !found {
+		return nil, 0, fmt.Errorf("invalid address %q", value)
+	}
+
+	raw, err := hex.DecodeString(addressHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid address %q", value)
+	}
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid port in %q", value)
+	}
+
+	address := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(address[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+	return address, int(port), nil
+}
+
+// socketOwners maps the inodes of sockets to the PID of a process holding them
+func socketOwners[T any](sockets map[uint64]T) map[uint64]uint32 {
+	owners := make(map[uint64]uint32)
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return owners
+	}
+
+	for _, entry := range entries {
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+
+		fdDir := filepath.Join(procRoot, entry.Name(), "fd")
+		fds, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue // Exited, or owned by another user
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := parseSocketLink(link)
+			if !ok {
+				continue
+			}
+			// Inherited sockets are shared with children; the lowest PID is the one that opened it
+			if _, wanted := sockets[inode]; wanted {
+				if owner, exists := owners[inode]; !exists || uint32(pid) < owner {
+					owners[inode] = uint32(pid)
+				}
+			}
+		}
+	}
+	return owners
+}
+
+// parseSocketLink extracts the inode from an fd link such as "socket:[12345]"
+func parseSocketLink(link string) (uint64, bool) {
+	value, found := strings.CutPrefix(link, "socket:[")
+	if !found || !strings.HasSuffix(value, "]") {
+		return 0, false
+	}
+	inode, err := strconv.ParseUint(strings.TrimSuffix(value, "]"), 10, 64)
+	return inode, err == nil
+}
+
+// listProcesses reads the parent and name of every process from /proc/<pid>/stat
+func listProcesses() (map[uint32]processInfo, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make(map[uint32]processInfo)
+	for _, entry := range entries {
+		pid, err := strconv.ParseUint(entry.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "stat"))
+		if err != nil {
+			continue
+		}
+		if info, ok := parseProcStat(string(data)); ok {
+			processes[uint32(pid)] = info
+		}
+	}
+	return processes, nil
+}
+
+// parseProcStat parses "pid (comm) state ppid ...". The command name may itself contain
+// spaces and parentheses, so it ends at the last ")".
+func parseProcStat(stat string) (processInfo, bool) {
+	open := strings.IndexByte(stat, '(')
+	end := strings.LastIndexByte(stat, ')')
+	if open < 0 || end < open {
+		return processInfo{}, false
+	}
+
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 2 {
+		return processInfo{}, false
+	}
+	parent, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return processInfo{}, false
+	}
+	return processInfo{parentPID: uint32(parent), name: stat[open+1 : end]}, true
+}
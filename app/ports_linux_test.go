@@ -0,0 +1,121 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProcNet(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000   113        0 21351 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 21400 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:1538 0100007F:D2F0 01 00000000:00000000 00:00000000 00000000   113        0 30001 1 0000000000000000 20 4 30 10 -1
+`
+	tcp6 := `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:1538 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   113        0 21352 1 0000000000000000 100 0 0 10 0
+   1: 00000000000000000000000001000000:0CEA 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   999        0 21401 1 0000000000000000 100 0 0 10 0
+`
+	udp := `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  100: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 15000 2 0000000000000000 0
+  101: 0100007F:C350 0100007F:0035 07 00000000:00000000 00:00000000 00000000   101        0 15001 2 0000000000000000 0
+`
+
+	sockets := make(map[uint64]ListeningEndpoint)
+	for _, table := range []struct{ data, protocol, state string }{
+		{tcp, ProtocolTCP, procTCPListen},
+		{tcp6, ProtocolTCP, procTCPListen},
+		{udp, ProtocolUDP, procUDPBound},
+	} {
+		if err := parseProcNet(strings.NewReader(table.data), table.protocol, table.state, sockets); err != nil {
+			t.Fatalf("parseProcNet() failed: %v", err)
+		}
+	}
+
+	expected := map[uint64]ListeningEndpoint{
+		21351: {Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432},
+		21400: {Protocol: ProtocolTCP, Address: "127.0.0.1", Port: 6379},
+		21352: {Protocol: ProtocolTCP, Address: "::", Port: 5432},
+		21401: {Protocol: ProtocolTCP, Address: "::1", Port: 3306},
+		15000: {Protocol: ProtocolUDP, Address: "127.0.0.53", Port: 53},
+	}
+	if len(sockets) != len(expected) {
+		t.Fatalf("Expected %d sockets, got %+v", len(expected), sockets)
+	}
+	for inode, endpoint := range expected {
+		if sockets[inode] != endpoint {
+			t.Errorf("Socket %d: expected %+v, got %+v", inode, endpoint, sockets[inode])
+		}
+	}
+}
+
+func TestParseProcConnections(t *testing.T) {
+	tcp := `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1538 00000000:0000 0A 00000000:00000000 00:00000000 00000000   113        0 21351 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1538 0100007F:D2F0 01 00000000:00000000 00:00000000 00000000   113        0 30001 1 0000000000000000 20 4 30 10 -1
+   2: 0100007F:1538 0100007F:D2F4 06 00000000:00000000 00:00000000 00000000     0        0     0 3 0000000000000000
+`
+
+	sockets := make(map[uint64]tcpConnection)
+	if err := parseProcConnections(strings.NewReader(tcp), sockets); err != nil {
+		t.Fatalf("parseProcConnections() failed: %v", err)
+	}
+
+	expected := tcpConnection{localPort: 5432, remoteAddress: "127.0.0.1", remotePort: 54000}
+	if len(sockets) != 1 || sockets[30001] != expected {
+		t.Errorf("Expected only the established connection %+v, got %+v", expected, sockets)
+	}
+}
+
+func TestParseSocketLink(t *testing.T) {
+	tests := []struct {
+		link     string
+		inode    uint64
+		expected bool
+	}{
+		{"socket:[21351]", 21351, true},
+		{"pipe:[1234]", 0, false},
+		{"/var/lib/postgresql/16/main/base/1/1259", 0, false},
+		{"socket:[abc]", 0, false},
+	}
+
+	for _, tt := range tests {
+		inode, ok := parseSocketLink(tt.link)
+		if ok != tt.expected || inode != tt.inode {
+			t.Errorf("parseSocketLink(%q) = %d, %v, expected %d, %v", tt.link, inode, ok, tt.inode, tt.expected)
+		}
+	}
+}
+
+func TestParseProcStat(t *testing.T) {
+	tests := []struct {
+		stat     string
+		expected processInfo
+		ok       bool
+	}{
+		{"812 (postgres) S 1 812 812 0 -1 4194560", processInfo{parentPID: 1, name: "postgres"}, true},
+		{"913 (postgres: walwriter) S 812 812 812 0 -1", processInfo{parentPID: 812, name: "postgres: walwriter"}, true},
+		{"77 (a) b) S 3 77", processInfo{parentPID: 3, name: "a) b"}, true},
+		{"garbage", processInfo{}, false},
+	}
+
+	for _, tt := range tests {
+		info, ok := parseProcStat(tt.stat)
+		if ok != tt.ok || info != tt.expected {
+			t.Errorf("parseProcStat(%q) = %+v, %v, expected %+v, %v", tt.stat, info, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestListListeningEndpoints(t *testing.T) {
+	// Smoke test against the real procfs; the result depends on the machine
+	if _, err := listListeningEndpoints(); err != nil {
+		t.Fatalf("listListeningEndpoints() failed: %v", err)
+	}
+	processes, err := listProcesses()
+	if err != nil {
+		t.Fatalf("listProcesses() failed: %v", err)
+	}
+	if len(processes) == 0 {
+		t.Error("Expected at least the test process")
+	}
+}
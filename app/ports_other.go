@@ -0,0 +1,23 @@
+//go:build !windows && !linux
+
+package app
+
+import "errors"
+
+// errPortDiscoveryUnsupported is returned where listening sockets cannot be enumerated
+var errPortDiscoveryUnsupported = errors.New("port discovery is not supported on this platform")
+
+// listListeningEndpoints is not implemented on this platform
+func listListeningEndpoints() ([]ListeningEndpoint, error) {
+	return nil, errPortDiscoveryUnsupported
+}
+
+// listTCPConnections is not implemented on this platform
+func listTCPConnections() ([]tcpConnection, error) {
+	return nil, errPortDiscoveryUnsupported
+}
+
+// listProcesses is not implemented on this platform
+func listProcesses() (map[uint32]processInfo, error) {
+	return nil, errPortDiscoveryUnsupported
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newFakePortScanner returns a scanner that reports fixed sockets and processes and no connections
+func newFakePortScanner(endpoints []ListeningEndpoint, processes map[uint32]processInfo) *portScanner {
+	return &portScanner{
+		listEndpoints:   func() ([]ListeningEndpoint, error) { return endpoints, nil },
+		listConnections: func() ([]tcpConnection, error) { return nil, nil },
+		listProcesses:   func() (map[uint32]processInfo, error) { return processes, nil },
+	}
+}
+
+// postgresProcessTree is pg_ctl (100) running postgres (200) with a worker (201), and an unrelated process
+var postgresProcessTree = map[uint32]processInfo{
+	100: {parentPID: 4, name: "pg_ctl.exe"},
+	200: {parentPID: 100, name: "postgres.exe"},
+	201: {parentPID: 200, name: "postgres.exe"},
+	300: {parentPID: 4, name: "memurai.exe"},
+}
+
+func TestPortSnapshotEndpointsOf(t *testing.T) {
+	snapshot := &portSnapshot{
+		endpoints: []ListeningEndpoint{
+			{Protocol: ProtocolTCP, Address: "::", Port: 5432, PID: 200},
+			{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432, PID: 200},
+			{Protocol: ProtocolUDP, Address: "127.0.0.1", Port: 50123, PID: 201},
+			{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 6379, PID: 300},
+		},
+		processes: postgresProcessTree,
+	}
+
+	expected := []ListeningEndpoint{
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432, PID: 200},
+		{Protocol: ProtocolTCP, Address: "::", Port: 5432, PID: 200},
+		{Protocol: ProtocolUDP, Address: "127.0.0.1", Port: 50123, PID: 201},
+	}
+	if endpoints := snapshot.endpointsOf(100); !reflect.DeepEqual(endpoints, expected) {
+		t.Errorf("endpointsOf(100) = %+v, expected %+v", endpoints, expected)
+	}
+	if endpoints := snapshot.endpointsOf(0); endpoints != nil {
+		t.Errorf("endpointsOf(0) = %+v, expected none", endpoints)
+	}
+}
+
+func TestPortSnapshotConflicts(t *testing.T) {
+	snapshot := &portSnapshot{
+		endpoints: []ListeningEndpoint{
+			{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432, PID: 200},
+			{Protocol: ProtocolTCP, Address: "127.0.0.1", Port: 5432, PID: 300},
+			{Protocol: ProtocolTCP, Address: "::1", Port: 5432, PID: 300},
+			{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5433, PID: 300},
+		},
+		processes: postgresProcessTree,
+	}
+
+	// The service's own postgres process is not a conflict
+	conflicts := snapshot.conflicts(5432, 100)
+	expected := []PortConflict{
+		{Protocol: ProtocolTCP, Address: "127.0.0.1", Port: 5432, PID: 300, Process: "memurai.exe"},
+		{Protocol: ProtocolTCP, Address: "::1", Port: 5432, PID: 300, Process: "memurai.exe"},
+	}
+	if !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("conflicts(5432, 100) = %+v, expected %+v", conflicts, expected)
+	}
+
+	// A stopped service conflicts with every holder
+	if conflicts := snapshot.conflicts(5432, 0); len(conflicts) != 3 {
+		t.Errorf("Expected 3 conflicts for a stopped service, got %+v", conflicts)
+	}
+
+	if description := describePortConflicts(conflicts); description != "port 5432 is already in use by memurai.exe (PID 300)" {
+		t.Errorf("Unexpected description %q", description)
+	}
+}
+
+func TestPortSnapshotConflictsIgnoresUDPAndUnknownOwners(t *testing.T) {
+	snapshot := &portSnapshot{
+		endpoints: []ListeningEndpoint{
+			{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432, PID: 200},
+			// The owner of this socket could not be read, e.g. for lack of access to /proc/<pid>/fd
+			{Protocol: ProtocolTCP, Address: "::", Port: 5432, PID: 0},
+			// A UDP socket does not keep the service from binding its TCP port
+			{Protocol: ProtocolUDP, Address: "0.0.0.0", Port: 5432, PID: 300},
+		},
+		processes: postgresProcessTree,
+	}
+
+	// While the service runs, a socket of unknown owner is most likely its own
+	if conflicts := snapshot.conflicts(5432, 100); len(conflicts) != 0 {
+		t.Errorf("Expected no conflicts for the running service, got %+v", conflicts)
+	}
+
+	// Before a start every TCP holder is reported, even if its owner is unknown
+	expected := []PortConflict{
+		{Protocol: ProtocolTCP, Address: "::", Port: 5432, PID: 0},
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432, PID: 200, Process: "postgres.exe"},
+	}
+	if conflicts := snapshot.conflicts(5432, 0); !reflect.DeepEqual(conflicts, expected) {
+		t.Errorf("conflicts(5432, 0) = %+v, expected %+v", conflicts, expected)
+	}
+}
+
+func TestCommandLinePort(t *testing.T) {
+	tests := []struct {
+		arguments []string
+		expected  int
+	}{
+		{[]string{"-D", "/var/lib/postgresql/16/main", "-p", "5433"}, 5433},
+		{[]string{"--defaults-file=my.ini", "--port=3307"}, 3307},
+		{[]string{"/etc/redis/redis.conf", "--port", "6380"}, 6380},
+		{[]string{"--port"}, 0},
+		{[]string{"-p", "http"}, 0},
+		{[]string{"--port=70000"}, 0},
+		{[]string{"runservice", "-N", "postgresql-x64-16"}, 0},
+		{nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.arguments, " "), func(t *testing.T) {
+			if port := commandLinePort(tt.arguments); port != tt.expected {
+				t.Errorf("commandLinePort(%q) = %d, expected %d", tt.arguments, port, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpectedServicePort(t *testing.T) {
+	engine := func(name string) *ServiceInstance {
+		return &ServiceInstance{Product: "SQL Server", Name: name, Component: ComponentEngine}
+	}
+
+	tests := []struct {
+		name      string
+		service   Service
+		arguments []string
+		expected  int
+	}{
+		{"default port", Service{Type: TypePostgreSQL}, nil, 5432},
+		{"port from command line", Service{Type: TypeRedis}, []string{"--port", "6380"}, 6380},
+		{"SQL Server default instance", Service{Type: TypeMSSQL, Instance: engine(mssqlDefaultInstance)}, nil, 1433},
+		{"SQL Server named instance", Service{Type: TypeMSSQL, Instance: engine("SQLEXPRESS")}, nil, 0},
+		{"SQL Server Agent", Service{Type: TypeMSSQL, Instance: &ServiceInstance{Name: mssqlDefaultInstance, Component: ComponentAgent}}, nil, 0},
+		{"container", Service{Type: TypeRedis, Backend: BackendDocker}, nil, 0},
+		{"custom type", Service{Type: ServiceType("clickhouse")}, nil, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if port := expectedServicePort(&tt.service, &OSService{Arguments: tt.arguments}); port != tt.expected {
+				t.Errorf("Expected port %d, got %d", tt.expected, port)
+			}
+		})
+	}
+}
+
+func TestDetectServicesDiscoversPorts(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "postgresql-x64-16", Status: StatusRunning, PID: 100},
+		OSService{Name: "Redis", Status: StatusStopped, PID: 300},
+	)
+	detector := NewWindowsServiceDetector(adapter)
+	detector.ports = newFakePortScanner([]ListeningEndpoint{
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 5432, PID: 200},
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 6379, PID: 300},
+	}, postgresProcessTree)
+
+	services, err := detector.DetectServices()
+	if err != nil {
+		t.Fatalf("DetectServices() failed: %v", err)
+	}
+
+	for _, service := range services {
+		switch service.Name {
+		case "postgresql-x64-16":
+			if service.PID != 100 || len(service.Ports) != 1 || service.Ports[0].Port != 5432 || service.ExpectedPort != 5432 {
+				t.Errorf("Unexpected PostgreSQL service: %+v", service)
+			}
+		case "Redis":
+			// A stopped service has no process, even if the adapter reports a stale PID
+			if service.PID != 0 || len(service.Ports) != 0 || service.ExpectedPort != 6379 {
+				t.Errorf("Unexpected Redis service: %+v", service)
+			}
+		}
+	}
+}
+
+func TestCheckPortConflicts(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	adapter.failOn["start Redis"] = &ServiceError{Code: ErrOperationTimeout, Message: "Timed out", Service: "Redis"}
+	sm := createTestServiceManager(t, adapter)
+	sm.ports = newFakePortScanner([]ListeningEndpoint{
+		{Protocol: ProtocolTCP, Address: "0.0.0.0", Port: 6379, PID: 300},
+	}, postgresProcessTree)
+
+	conflicts, err := sm.CheckPortConflicts("Redis")
+	if err != nil {
+		t.Fatalf("CheckPortConflicts() failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].PID != 300 || conflicts[0].Process != "memurai.exe" {
+		t.Fatalf("Unexpected conflicts: %+v", conflicts)
+	}
+
+	// A failed start explains which process holds the port
+	err = sm.WaitForOperation(sm.StartService("Redis"))
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Code != ErrOperationTimeout {
+		t.Fatalf("Expected ErrOperationTimeout, got %v", err)
+	}
+	if !strings.Contains(serviceErr.Message, "port 6379 is already in use by memurai.exe (PID 300)") {
+		t.Errorf("Expected the conflict in the error, got %q", serviceErr.Message)
+	}
+
+	if _, err := sm.CheckPortConflicts("missing"); err == nil {
+		t.Error("Expected an error for an unknown service")
+	}
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	iphlpapi                = windows.NewLazySystemDLL("iphlpapi.dll")
+	procGetExtendedTcpTable = iphlpapi.NewProc("GetExtendedTcpTable")
+	procGetExtendedUdpTable = iphlpapi.NewProc("GetExtendedUdpTable")
+)
+
+const (
+	// tcpTableOwnerPIDListener is TCP_TABLE_OWNER_PID_LISTENER: listening sockets with their owning process
+	tcpTableOwnerPIDListener = 3
+	// tcpTableOwnerPIDConnections is TCP_TABLE_OWNER_PID_CONNECTIONS: connected sockets with their owning process
+	tcpTableOwnerPIDConnections = 4
+	// mibTCPStateEstablished is MIB_TCP_STATE_ESTAB
+	mibTCPStateEstablished = 5
+	// udpTableOwnerPID is UDP_TABLE_OWNER_PID: bound UDP sockets with their owning process
+	udpTableOwnerPID = 1
+)
+
+// ownerPIDTable describes the row layout of a MIB_*ROW_OWNER_PID table
+type ownerPIDTable struct {
+	proc       *windows.LazyProc
+	family     uint32
+	class      uint32
+	protocol   string
+	rowSize    int
+	addrOffset int
+	addrSize   int
+	portOffset int
+	pidOffset  int
+}
+
+// ownerPIDTables are the IPv4 and IPv6 TCP listener and UDP tables
+var ownerPIDTables = []ownerPIDTable{
+	// MIB_TCPROW_OWNER_PID: state, local addr, local port, remote addr, remote port, pid
+	{procGetExtendedTcpTable, windows.AF_INET, tcpTableOwnerPIDListener, ProtocolTCP, 24, 4, 4, 8, 20},
+	// MIB_TCP6ROW_OWNER_PID: local addr[16], scope, local port, remote addr[16], scope, remote port, state, pid
+	{procGetExtendedTcpTable, windows.AF_INET6, tcpTableOwnerPIDListener, ProtocolTCP, 56, 0, 16, 20, 52},
+	// MIB_UDPROW_OWNER_PID: local addr, local port, pid
+	{procGetExtendedUdpTable, windows.AF_INET, udpTableOwnerPID, ProtocolUDP, 12, 0, 4, 4, 8},
+	// MIB_UDP6ROW_OWNER_PID: local addr[16], scope, local port, pid
+	{procGetExtendedUdpTable, windows.AF_INET6, udpTableOwnerPID, ProtocolUDP, 28, 0, 16, 20, 24},
+}
+
+// connectionTable extends an owner PID table with the remote end and state of TCP connections
+type connectionTable struct {
+	ownerPIDTable
+	remoteAddrOffset int
+	remotePortOffset int
+	stateOffset      int
+}
+
+// connectionTables are the IPv4 and IPv6 TCP connection tables, laid out as the listener tables
+var connectionTables = []connectionTable{
+	{ownerPIDTable{procGetExtendedTcpTable, windows.AF_INET, tcpTableOwnerPIDConnections, ProtocolTCP, 24, 4, 4, 8, 20}, 12, 16, 0},
+	{ownerPIDTable{procGetExtendedTcpTable, windows.AF_INET6, tcpTableOwnerPIDConnections, ProtocolTCP, 56, 0, 16, 20, 52}, 24, 44, 48},
+}
+
+// listTCPConnections reads the established TCP connections from the IP Helper API
+func listTCPConnections() ([]tcpConnection, error) {
+	var connections []tcpConnection
+	for _, table := range connectionTables {
+		data, err := table.read()
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, table.parse(data)...)
+	}
+	return connections, nil
+}
+
+// parse decodes the established connections of a table
+func (t connectionTable) parse(data []byte) []tcpConnection {
+	if len(data) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(data))
+
+	var connections []tcpConnection
+	for i := 0; i < count; i++ {
+		offset := 4 + i*t.rowSize
+		if offset+t.rowSize > len(data) {
+			break
+		}
+		row := data[offset : offset+t.rowSize]
+		if binary.LittleEndian.Uint32(row[t.stateOffset:]) != mibTCPStateEstablished {
+			continue
+		}
+
+		remote := net.IP(append([]byte(nil), row[t.remoteAddrOffset:t.remoteAddrOffset+t.addrSize]...))
+		connections = append(connections, tcpConnection{
+			localPort:     int(binary.BigEndian.Uint16(row[t.portOffset:])),
+			remoteAddress: remote.String(),
+			remotePort:    int(binary.BigEndian.Uint16(row[t.remotePortOffset:])),
+			pid:           binary.LittleEndian.Uint32(row[t.pidOffset:]),
+		})
+	}
+	return connections
+}
+
+// listListeningEndpoints reads the listening TCP and bound UDP sockets from the IP Helper API
+func listListeningEndpoints() ([]ListeningEndpoint, error) {
+	var endpoints []ListeningEndpoint
+	for _, table := range ownerPIDTables {
+		data, err := table.read()
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, table.parse(data)...)
+	}
+	return endpoints, nil
+}
+
+// read fetches the table, growing the buffer while the table grows between calls
+func (t ownerPIDTable) read() ([]byte, error) {
+	size := uint32(16 * 1024)
+	for {
+		buffer := make([]byte, size)
+		ret, _, _ := t.proc.Call(
+			uintptr(unsafe.Pointer(&buffer[0])),
+			uintptr(unsafe.Pointer(&size)),
+			0, // Unordered
+			uintptr(t.family),
+			uintptr(t.class),
+			0,
+		)
+		switch windows.Errno(ret) {
+		case windows.ERROR_SUCCESS:
+			return buffer[:size], nil
+		case windows.ERROR_INSUFFICIENT_BUFFER:
+			continue
+		default:
+			return nil, fmt.Errorf("%s failed: %w", t.proc.Name, windows.Errno(ret))
+		}
+	}
+}
+
+// parse decodes the rows of a table: a DWORD row count followed by fixed-size rows
+func (t ownerPIDTable) parse(data []byte) []ListeningEndpoint {
+	if len(data) < 4 {
+		return nil
+	}
+	count := int(binary.LittleEndian.Uint32(data))
+
+	endpoints := make([]ListeningEndpoint, 0, count)
+	for i := 0; i < count; i++ {
+		offset := 4 + i*t.rowSize
+		if offset+t.rowSize > len(data) {
+			break
+		}
+		row := data[offset : offset+t.rowSize]
+
+		// Addresses and ports are stored in network byte order
+		address := net.IP(append([]byte(nil), row[t.addrOffset:t.addrOffset+t.addrSize]...))
+		endpoints = append(endpoints, ListeningEndpoint{
+			Protocol: t.protocol,
+			Address:  address.String(),
+			Port:     int(binary.BigEndian.Uint16(row[t.portOffset:])),
+			PID:      binary.LittleEndian.Uint32(row[t.pidOffset:]),
+		})
+	}
+	return endpoints
+}
+
+// listProcesses reads the process table from a Toolhelp snapshot
+func listProcesses() (map[uint32]processInfo, error) {
+	snapshot, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot processes: %w", err)
+	}
+	defer windows.CloseHandle(snapshot)
+
+	processes := make(map[uint32]processInfo)
+	entry := windows.ProcessEntry32{Size: uint32(unsafe.Sizeof(windows.ProcessEntry32{}))}
+	for err = windows.Process32First(snapshot, &entry); err == nil; err = windows.Process32Next(snapshot, &entry) {
+		processes[entry.ProcessID] = processInfo{
+			parentPID: entry.ParentProcessID,
+			name:      windows.UTF16ToString(entry.ExeFile[:]),
+		}
+	}
+	if err != windows.ERROR_NO_MORE_FILES {
+		return nil, fmt.Errorf("failed to read processes: %w", err)
+	}
+	return processes, nil
+}
@@ -2,19 +2,23 @@ package app
 
 import (
 	"fmt"
-	"syscall"
-	"unsafe"
 )
 
-// PrivilegeManager handles Windows privilege elevation and checking
+// PrivilegeManager handles privilege elevation and checking
 type PrivilegeManager struct {
-	isElevated bool
-	checked    bool
+	isElevated        bool
+	checked           bool
+	elevationOptional bool // The service backends check permissions themselves
 }
 
 // NewPrivilegeManager creates a new privilege manager
 func NewPrivilegeManager() *PrivilegeManager {
-	return &PrivilegeManager{}
+	return &PrivilegeManager{elevationOptional: !elevationRequired}
+}
+
+// elevationRequired reports whether service operations need administrator privileges
+func (pm *PrivilegeManager) elevationRequired() bool {
+	return !pm.elevationOptional
 }
 
 // IsElevated checks if the current process is running with administrator privileges
@@ -28,59 +32,6 @@ func (pm *PrivilegeManager) IsElevated() bool {
 	return pm.isElevated
 }
 
-// checkElevation performs the actual elevation check
-func (pm *PrivilegeManager) checkElevation() bool {
-	// Load required Windows APIs
-	advapi32 := syscall.NewLazyDLL("advapi32.dll")
-	kernel32 := syscall.NewLazyDLL("kernel32.dll")
-	
-	procGetCurrentProcess := kernel32.NewProc("GetCurrentProcess")
-	procOpenProcessToken := advapi32.NewProc("OpenProcessToken")
-	procGetTokenInformation := advapi32.NewProc("GetTokenInformation")
-	
-	// Constants
-	const (
-		TOKEN_QUERY         = 0x0008
-		TokenElevationType  = 18
-		TokenElevationTypeFull = 2
-	)
-	
-	// Get current process handle
-	currentProcess, _, _ := procGetCurrentProcess.Call()
-	
-	// Open process token
-	var token syscall.Handle
-	ret, _, _ := procOpenProcessToken.Call(
-		currentProcess,
-		TOKEN_QUERY,
-		uintptr(unsafe.Pointer(&token)),
-	)
-	
-	if ret == 0 {
-		return false
-	}
-	defer syscall.CloseHandle(token)
-	
-	// Get token elevation type
-	var elevationType uint32
-	var returnLength uint32
-	
-	ret, _, _ = procGetTokenInformation.Call(
-		uintptr(token),
-		TokenElevationType,
-		uintptr(unsafe.Pointer(&elevationType)),
-		unsafe.Sizeof(elevationType),
-		uintptr(unsafe.Pointer(&returnLength)),
-	)
-	
-	if ret == 0 {
-		return false
-	}
-	
-	// Check if we have full elevation
-	return elevationType == TokenElevationTypeFull
-}
-
 // RequireElevation checks if the process is elevated and returns an error if not
 func (pm *PrivilegeManager) RequireElevation() error {
 	if !pm.IsElevated() {
@@ -0,0 +1,15 @@
+//go:build unix
+
+package app
+
+import "os"
+
+// elevationRequired is false on Unix: polkit authorizes each systemd operation, prompting
+// if needed, and the Docker socket admits members of the docker group, so the backends
+// report missing permissions themselves
+const elevationRequired = false
+
+// checkElevation performs the actual elevation check
+func (pm *PrivilegeManager) checkElevation() bool {
+	return os.Geteuid() == 0
+}
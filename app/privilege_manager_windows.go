@@ -0,0 +1,63 @@
+package app
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// elevationRequired is true on Windows, where the Service Control Manager only lets
+// elevated processes start, stop and reconfigure services
+const elevationRequired = true
+
+// checkElevation performs the actual elevation check
+func (pm *PrivilegeManager) checkElevation() bool {
+	// Load required Windows APIs
+	advapi32 := syscall.NewLazyDLL("advapi32.dll")
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	
+	procGetCurrentProcess := kernel32.NewProc("GetCurrentProcess")
+	procOpenProcessToken := advapi32.NewProc("OpenProcessToken")
+	procGetTokenInformation := advapi32.NewProc("GetTokenInformation")
+	
+	// Constants
+	const (
+		TOKEN_QUERY         = 0x0008
+		TokenElevationType  = 18
+		TokenElevationTypeFull = 2
+	)
+	
+	// Get current process handle
+	currentProcess, _, _ := procGetCurrentProcess.Call()
+	
+	// Open process token
+	var token syscall.Handle
+	ret, _, _ := procOpenProcessToken.Call(
+		currentProcess,
+		TOKEN_QUERY,
+		uintptr(unsafe.Pointer(&token)),
+	)
+	
+	if ret == 0 {
+		return false
+	}
+	defer syscall.CloseHandle(token)
+	
+	// Get token elevation type
+	var elevationType uint32
+	var returnLength uint32
+	
+	ret, _, _ = procGetTokenInformation.Call(
+		uintptr(token),
+		TokenElevationType,
+		uintptr(unsafe.Pointer(&elevationType)),
+		unsafe.Sizeof(elevationType),
+		uintptr(unsafe.Pointer(&returnLength)),
+	)
+	
+	if ret == 0 {
+		return false
+	}
+	
+	// Check if we have full elevation
+	return elevationType == TokenElevationTypeFull
+}
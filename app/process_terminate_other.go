@@ -0,0 +1,18 @@
+//go:build !windows
+
+package app
+
+import (
+	"errors"
+	"syscall"
+)
+
+// terminateProcess kills a process immediately with SIGKILL
+func terminateProcess(pid uint32) error {
+	err := syscall.Kill(int(pid), syscall.SIGKILL)
+	if errors.Is(err, syscall.ESRCH) {
+		// The process exited meanwhile
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package app
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// terminateProcess ends a process immediately, as Task Manager's End task does
+func terminateProcess(pid uint32) error {
+	handle, err := windows.OpenProcess(windows.PROCESS_TERMINATE, false, pid)
+	if err != nil {
+		if errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+			// The process exited meanwhile
+			return nil
+		}
+		return err
+	}
+	defer windows.CloseHandle(handle)
+
+	return windows.TerminateProcess(handle, 1)
+}
@@ -0,0 +1,401 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultReadinessTimeout is how long a started service may take to accept connections
+	defaultReadinessTimeout = 60 * time.Second
+	// readinessRetryInterval is the delay between two probe attempts
+	readinessRetryInterval = 500 * time.Millisecond
+	// readinessAttemptTimeout bounds a single connect and protocol exchange
+	readinessAttemptTimeout = 3 * time.Second
+	// readinessHost is probed when no address is configured; services usually listen on IPv4
+	readinessHost = "127.0.0.1"
+	// maxProbeReply limits how much of a reply a probe reads
+	maxProbeReply = 64 * 1024
+)
+
+// defaultServicePorts are the ports each service type listens on out of the box
+var defaultServicePorts = map[ServiceType]int{
+	TypePostgreSQL:    5432,
+	TypeMongoDB:       27017,
+	TypeMySQL:         3306,
+	TypeMariaDB:       3306,
+	TypeMSSQL:         1433,
+	TypeOracle:        1521,
+	TypeRedis:         6379,
+	TypeCassandra:     9042,
+	TypeElasticsearch: 9200,
+	TypeCouchDB:       5984,
+	TypeInfluxDB:      8086,
+	TypeNeo4j:         7687,
+	TypeRabbitMQ:      5672,
+	TypeMemcached:     11211,
+	TypeDB2:           50000,
+	TypeFirebird:      3050,
+}
+
+// ReadinessConfig enables a readiness probe for a service type.
+// Start and restart operations only succeed once the probe passes.
+type ReadinessConfig struct {
+	Enabled        bool   `json:"enabled"`
+	Address        string `json:"address,omitempty"`         // host:port probed instead of the port of each service
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // defaults to 60 seconds
+}
+
+// probeAddress returns the address to probe for a service listening on port: the configured
+// address if there is one, otherwise the port on localhost. The address is empty if the port is 0.
+func (c ReadinessConfig) probeAddress(port int) (string, error) {
+	if c.Address != "" {
+		if _, _, err := net.SplitHostPort(c.Address); err != nil {
+			return "", fmt.Errorf("invalid address %q: %w", c.Address, err)
+		}
+		return c.Address, nil
+	}
+
+	if port == 0 {
+		return "", nil
+	}
+	return net.JoinHostPort(readinessHost, strconv.Itoa(port)), nil
+}
+
+// timeout returns how long to wait for the service to become ready
+func (c ReadinessConfig) timeout() time.Duration {
+	if c.TimeoutSeconds > 0 {
+		return time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return defaultReadinessTimeout
+}
+
+// validateReadinessConfigs checks the timeout and address of every enabled probe
+func validateReadinessConfigs(configs map[ServiceType]ReadinessConfig) error {
+	for serviceType, config := range configs {
+		if config.TimeoutSeconds < 0 {
+			return fmt.Errorf("readiness probe for %q: timeout cannot be negative", serviceType)
+		}
+		if !config.Enabled {
+			continue
+		}
+		if _, err := config.probeAddress(0); err != nil {
+			return fmt.Errorf("readiness probe for %q: %w", serviceType, err)
+		}
+	}
+	return nil
+}
+
+// readinessProbe checks that a connected service speaks its protocol and accepts clients
+type readinessProbe func(conn net.Conn) error
+
+// readinessProbes holds protocol-level probes; other service types only need to accept a TCP connection
+var readinessProbes = map[ServiceType]readinessProbe{
+	TypePostgreSQL: probePostgreSQL,
+	TypeRedis:      probeRedis,
+	TypeMySQL:      probeMySQL,
+	TypeMariaDB:    probeMySQL,
+	TypeMongoDB:    probeMongoDB,
+}
+
+// probeReadiness connects to a service once and runs its protocol probe
+func probeReadiness(ctx context.Context, serviceType ServiceType, address string) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessAttemptTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	probe, exists := readinessProbes[serviceType]
+	if !exists {
+		return nil
+	}
+
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	return probe(conn)
+}
+
+// waitUntilReady probes a service until it is ready or the timeout expires
+func waitUntilReady(ctx context.Context, name string, serviceType ServiceType, address string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		err := probeReadiness(ctx, serviceType, address)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &ServiceError{
+				Code:    ErrNotReady,
+				Message: fmt.Sprintf("Service is running but did not accept connections on %s within %s: %v", address, timeout, err),
+				Service: name,
+			}
+		case <-time.After(readinessRetryInterval):
+		}
+	}
+}
+
+// probePostgreSQL sends a startup message and checks the server does not reject it as starting up.
+// Authentication requests and errors such as unknown roles mean the server is accepting clients.
+func probePostgreSQL(conn net.Conn) error {
+	var params bytes.Buffer
+	binary.Write(&params, binary.BigEndian, int32(196608)) // Protocol version 3.0
+	params.WriteString("user\x00shutdb\x00database\x00postgres\x00\x00")
+
+	startup := make([]byte, 4, 4+params.Len())
+	binary.BigEndian.PutUint32(startup, uint32(4+params.Len()))
+	if _, err := conn.Write(append(startup, params.Bytes()...)); err != nil {
+		return err
+	}
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	switch header[0] {
+	case 'R', 'v': // Authentication request or protocol negotiation
+		return nil
+	case 'E':
+		length := int(binary.BigEndian.Uint32(header[1:])) - 4
+		if length < 0 || length > maxProbeReply {
+			return fmt.Errorf("invalid postgres error response length %d", length)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(conn, body); err != nil {
+			return err
+		}
+
+		fields := make(map[byte]string)
+		for _, field := range bytes.Split(body, []byte{0}) {
+			if len(field) > 1 {
+				fields[field[0]] = string(field[1:])
+			}
+		}
+
+		// 57P03 cannot_connect_now is reported while the server starts up or recovers
+		if fields['C'] == "57P03" {
+			return fmt.Errorf("postgres is not accepting connections yet: %s", fields['M'])
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected postgres response %q", header[0])
+	}
+}
+
+// probeRedis sends PING and expects PONG, or an authentication error from a protected server
+func probeRedis(conn net.Conn) error {
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return err
+	}
+
+	line, err := bufio.NewReader(io.LimitReader(conn, maxProbeReply)).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	line = strings.TrimSpace(line)
+
+	if line == "+PONG" || strings.HasPrefix(line, "-NOAUTH") {
+		return nil
+	}
+	return fmt.Errorf("redis replied %q", line)
+}
+
+// probeMySQL reads the initial handshake packet the server sends to every client
+func probeMySQL(conn net.Conn) error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	length := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if length == 0 || length > maxProbeReply {
+		return fmt.Errorf("invalid mysql packet length %d", length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return err
+	}
+
+	switch payload[0] {
+	case 0x0a: // Protocol version 10 handshake
+		return nil
+	case 0xff:
+		if len(payload) < 3 {
+			return fmt.Errorf("mysql refused the connection")
+		}
+		code := binary.LittleEndian.Uint16(payload[1:3])
+		return fmt.Errorf("mysql refused the connection: %s (%d)", payload[3:], code)
+	default:
+		return fmt.Errorf("unexpected mysql protocol version %d", payload[0])
+	}
+}
+
+const (
+	// mongoOpMsg is the wire protocol opcode of OP_MSG
+	mongoOpMsg = 2013
+	// mongoRequestID identifies the probe's hello command
+	mongoRequestID = 1
+)
+
+// probeMongoDB sends a hello command and checks the reply reports ok: 1
+func probeMongoDB(conn net.Conn) error {
+	if _, err := conn.Write(mongoHelloMessage()); err != nil {
+		return err
+	}
+
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+
+	length := int(int32(binary.LittleEndian.Uint32(header[0:4])))
+	responseTo := int32(binary.LittleEndian.Uint32(header[8:12]))
+	opCode := int32(binary.LittleEndian.Uint32(header[12:16]))
+	if length < 16 || length > maxProbeReply {
+		return fmt.Errorf("invalid mongodb message length %d", length)
+	}
+	if opCode != mongoOpMsg || responseTo != mongoRequestID {
+		return fmt.Errorf("unexpected mongodb reply (opcode %d)", opCode)
+	}
+
+	body := make([]byte, length-16)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return err
+	}
+
+	// Flag bits followed by a kind 0 section holding the reply document
+	if len(body) < 5 || body[4] != 0 {
+		return fmt.Errorf("malformed mongodb reply")
+	}
+	if ok, found := bsonNumber(body[5:], "ok"); !found || ok != 1 {
+		return fmt.Errorf("mongodb hello was not acknowledged")
+	}
+	return nil
+}
+
+// mongoHelloMessage encodes {hello: 1, $db: "admin"} as an OP_MSG
+func mongoHelloMessage() []byte {
+	var doc bytes.Buffer
+	doc.WriteByte(0x10) // int32
+	doc.WriteString("hello\x00")
+	binary.Write(&doc, binary.LittleEndian, int32(1))
+	doc.WriteByte(0x02) // string
+	doc.WriteString("$db\x00")
+	binary.Write(&doc, binary.LittleEndian, int32(len("admin")+1))
+	doc.WriteString("admin\x00")
+	doc.WriteByte(0x00)
+
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.LittleEndian, int32(16+4+1+4+doc.Len()))
+	binary.Write(&msg, binary.LittleEndian, int32(mongoRequestID))
+	binary.Write(&msg, binary.LittleEndian, int32(0))
+	binary.Write(&msg, binary.LittleEndian, int32(mongoOpMsg))
+	binary.Write(&msg, binary.LittleEndian, uint32(0)) // Flag bits
+	msg.WriteByte(0)                                   // Section kind 0: body
+	binary.Write(&msg, binary.LittleEndian, int32(4+doc.Len()))
+	msg.Write(doc.Bytes())
+	return msg.Bytes()
+}
+
+// bsonNumber returns a top-level numeric field of a BSON document
+func bsonNumber(doc []byte, key string) (float64, bool) {
+	if len(doc) < 5 {
+		return 0, false
+	}
+	end := int(int32(binary.LittleEndian.Uint32(doc)))
+	if end < 5 || end > len(doc) {
+		return 0, false
+	}
+
+	for pos := 4; pos < end-1; {
+		elementType := doc[pos]
+		nameEnd := bytes.IndexByte(doc[pos+1:end], 0)
+		if nameEnd < 0 {
+			return 0, false
+		}
+		name := string(doc[pos+1 : pos+1+nameEnd])
+		pos += 2 + nameEnd
+
+		size := bsonValueSize(elementType, doc[pos:end])
+		if size < 0 || pos+size > end {
+			return 0, false
+		}
+
+		if name == key {
+			value := doc[pos : pos+size]
+			switch elementType {
+			case 0x01:
+				return math.Float64frombits(binary.LittleEndian.Uint64(value)), true
+			case 0x10:
+				return float64(int32(binary.LittleEndian.Uint32(value))), true
+			case 0x12:
+				return float64(int64(binary.LittleEndian.Uint64(value))), true
+			default:
+				return 0, false
+			}
+		}
+		pos += size
+	}
+	return 0, false
+}
+
+// bsonValueSize returns the encoded size of a BSON value, or -1 if it cannot be determined
+func bsonValueSize(elementType byte, data []byte) int {
+	lengthPrefixed := func(extra int) int {
+		if len(data) < 4 {
+			return -1
+		}
+		return 4 + int(int32(binary.LittleEndian.Uint32(data))) + extra
+	}
+
+	switch elementType {
+	case 0x06, 0x0a, 0x7f, 0xff: // undefined, null, min key, max key
+		return 0
+	case 0x08: // bool
+		return 1
+	case 0x10: // int32
+		return 4
+	case 0x01, 0x09, 0x11, 0x12: // double, datetime, timestamp, int64
+		return 8
+	case 0x07: // object id
+		return 12
+	case 0x13: // decimal128
+		return 16
+	case 0x02, 0x0d, 0x0e: // string, javascript, symbol
+		return lengthPrefixed(0)
+	case 0x03, 0x04, 0x0f: // document, array, code with scope include their own length
+		return lengthPrefixed(-4)
+	case 0x05: // binary has a subtype byte
+		return lengthPrefixed(1)
+	case 0x0b: // regex: two C strings
+		first := bytes.IndexByte(data, 0)
+		if first < 0 {
+			return -1
+		}
+		second := bytes.IndexByte(data[first+1:], 0)
+		if second < 0 {
+			return -1
+		}
+		return first + second + 2
+	default:
+		return -1
+	}
+}
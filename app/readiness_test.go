@@ -0,0 +1,300 @@
+package app
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startFakeServer accepts connections on a local port and hands each one to handle
+func startFakeServer(t *testing.T, handle func(conn net.Conn)) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// unusedAddress returns a local address nothing is listening on
+func unusedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+	return address
+}
+
+// postgresServer replies to the startup message with a single backend message
+func postgresServer(messageType byte, body []byte) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		io.CopyN(io.Discard, conn, int64(binary.BigEndian.Uint32(header))-4)
+
+		reply := []byte{messageType, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(reply[1:], uint32(4+len(body)))
+		conn.Write(append(reply, body...))
+	}
+}
+
+// postgresError encodes the fields of an ErrorResponse
+func postgresError(code, message string) []byte {
+	return []byte("SFATAL\x00C" + code + "\x00M" + message + "\x00\x00")
+}
+
+// redisServer answers PING with a fixed reply
+func redisServer(reply string) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+			return
+		}
+		conn.Write([]byte(reply + "\r\n"))
+	}
+}
+
+// mysqlServer greets every client with a single packet
+func mysqlServer(payload []byte) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		header := []byte{byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), 0}
+		conn.Write(append(header, payload...))
+	}
+}
+
+// mongoServer answers a hello command with a reply document containing ok
+func mongoServer(ok float64) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		header := make([]byte, 16)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		io.CopyN(io.Discard, conn, int64(binary.LittleEndian.Uint32(header))-16)
+		requestID := binary.LittleEndian.Uint32(header[4:8])
+
+		var doc bytes.Buffer
+		doc.WriteByte(0x08) // Fields before ok must be skipped correctly
+		doc.WriteString("isWritablePrimary\x00")
+		doc.WriteByte(1)
+		doc.WriteByte(0x02)
+		doc.WriteString("msg\x00")
+		binary.Write(&doc, binary.LittleEndian, int32(len("isdbgrid")+1))
+		doc.WriteString("isdbgrid\x00")
+		doc.WriteByte(0x01)
+		doc.WriteString("ok\x00")
+		binary.Write(&doc, binary.LittleEndian, math.Float64bits(ok))
+		doc.WriteByte(0)
+
+		var reply bytes.Buffer
+		binary.Write(&reply, binary.LittleEndian, int32(16+4+1+4+doc.Len()))
+		binary.Write(&reply, binary.LittleEndian, int32(7))
+		binary.Write(&reply, binary.LittleEndian, requestID)
+		binary.Write(&reply, binary.LittleEndian, int32(mongoOpMsg))
+		binary.Write(&reply, binary.LittleEndian, uint32(0))
+		reply.WriteByte(0)
+		binary.Write(&reply, binary.LittleEndian, int32(4+doc.Len()))
+		reply.Write(doc.Bytes())
+		conn.Write(reply.Bytes())
+	}
+}
+
+func TestReadinessProbes(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType ServiceType
+		server      func(conn net.Conn)
+		ready       bool
+	}{
+		{"postgres authentication request", TypePostgreSQL, postgresServer('R', []byte{0, 0, 0, 10}), true},
+		{"postgres unknown role", TypePostgreSQL, postgresServer('E', postgresError("28000", "role \"shutdb\" does not exist")), true},
+		{"postgres starting up", TypePostgreSQL, postgresServer('E', postgresError("57P03", "the database system is starting up")), false},
+		{"redis pong", TypeRedis, redisServer("+PONG"), true},
+		{"redis requires auth", TypeRedis, redisServer("-NOAUTH Authentication required."), true},
+		{"redis loading", TypeRedis, redisServer("-LOADING Redis is loading the dataset in memory"), false},
+		{"mysql handshake", TypeMySQL, mysqlServer(append([]byte{0x0a}, "8.4.0\x00"...)), true},
+		{"mariadb handshake", TypeMariaDB, mysqlServer(append([]byte{0x0a}, "11.4.2-MariaDB\x00"...)), true},
+		{"mysql error packet", TypeMySQL, mysqlServer(append([]byte{0xff, 0x10, 0x04}, "Too many connections"...)), false},
+		{"mongodb hello", TypeMongoDB, mongoServer(1), true},
+		{"mongodb hello failed", TypeMongoDB, mongoServer(0), false},
+		{"mssql tcp connect", TypeMSSQL, func(conn net.Conn) {}, true},
+		{"postgres closes connection", TypePostgreSQL, func(conn net.Conn) {}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address := startFakeServer(t, tt.server)
+
+			err := probeReadiness(context.Background(), tt.serviceType, address)
+			if tt.ready && err != nil {
+				t.Errorf("Expected service to be ready, got %v", err)
+			}
+			if !tt.ready && err == nil {
+				t.Error("Expected probe to fail")
+			}
+		})
+	}
+}
+
+func TestProbeReadinessConnectionRefused(t *testing.T) {
+	if err := probeReadiness(context.Background(), TypeMSSQL, unusedAddress(t)); err == nil {
+		t.Error("Expected probe to fail when nothing is listening")
+	}
+}
+
+func TestWaitUntilReadyRetries(t *testing.T) {
+	var attempts int32
+	address := startFakeServer(t, func(conn net.Conn) {
+		// Refuse the first two clients as if the server were still recovering
+		if atomic.AddInt32(&attempts, 1) <= 2 {
+			redisServer("-LOADING Redis is loading the dataset in memory")(conn)
+			return
+		}
+		redisServer("+PONG")(conn)
+	})
+
+	if err := waitUntilReady(context.Background(), "Redis", TypeRedis, address, 10*time.Second); err != nil {
+		t.Fatalf("waitUntilReady() failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestWaitUntilReadyTimeout(t *testing.T) {
+	address := unusedAddress(t)
+
+	err := waitUntilReady(context.Background(), "Redis", TypeRedis, address, time.Second)
+	serviceErr, ok := err.(*ServiceError)
+	if !ok {
+		t.Fatalf("Expected *ServiceError, got %v", err)
+	}
+	if serviceErr.Code != ErrNotReady {
+		t.Errorf("Expected ErrNotReady, got %d", serviceErr.Code)
+	}
+	if !strings.Contains(serviceErr.Message, address) {
+		t.Errorf("Expected the probed address in %q", serviceErr.Message)
+	}
+}
+
+func TestReadinessConfigProbeAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ReadinessConfig
+		port     int
+		expected string
+		wantErr  bool
+	}{
+		{"service port", ReadinessConfig{Enabled: true}, 5433, "127.0.0.1:5433", false},
+		{"custom address", ReadinessConfig{Enabled: true, Address: "localhost:5434"}, 5433, "localhost:5434", false},
+		{"missing port", ReadinessConfig{Enabled: true, Address: "localhost"}, 5433, "", true},
+		{"unknown port", ReadinessConfig{Enabled: true}, 0, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			address, err := tt.config.probeAddress(tt.port)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("probeAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if address != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, address)
+			}
+		})
+	}
+
+	if err := validateReadinessConfigs(map[ServiceType]ReadinessConfig{TypeRedis: {Enabled: true, Address: "6380"}}); err == nil {
+		t.Error("Expected validation to reject an invalid address")
+	}
+	if err := validateReadinessConfigs(map[ServiceType]ReadinessConfig{TypeRedis: {Enabled: false, Address: "6380"}}); err != nil {
+		t.Errorf("Disabled probes should not be validated: %v", err)
+	}
+}
+
+func TestServiceManagerStartWaitsForReadiness(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	sm := createTestServiceManager(t, adapter)
+	sm.cache.SetAll([]Service{{Name: "Redis", Type: TypeRedis, Status: StatusStopped}})
+
+	// Nothing accepts connections, so the start reports that the service is not ready
+	readiness := ReadinessConfig{Enabled: true, Address: unusedAddress(t), TimeoutSeconds: 1}
+	if err := sm.configManager.SetReadinessConfig(TypeRedis, readiness); err != nil {
+		t.Fatalf("SetReadinessConfig() failed: %v", err)
+	}
+
+	err := sm.WaitForOperation(sm.StartService("Redis"))
+	if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrNotReady {
+		t.Fatalf("Expected ErrNotReady, got %v", err)
+	}
+
+	// Once the service answers, restarts succeed
+	readiness.Address = startFakeServer(t, redisServer("+PONG"))
+	if err := sm.configManager.SetReadinessConfig(TypeRedis, readiness); err != nil {
+		t.Fatalf("SetReadinessConfig() failed: %v", err)
+	}
+	if err := sm.WaitForOperation(sm.RestartService("Redis")); err != nil {
+		t.Errorf("RestartService() failed: %v", err)
+	}
+}
+
+func TestServiceManagerReadinessProbesServicePort(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "Redis", Status: StatusStopped},
+		OSService{Name: "Redis6380", Status: StatusStopped},
+		OSService{Name: "cache", Status: StatusStopped},
+	)
+	sm := createTestServiceManager(t, adapter)
+
+	portOf := func(address string) int {
+		_, portValue, _ := net.SplitHostPort(address)
+		port, _ := strconv.Atoi(portValue)
+		return port
+	}
+	// Only the second instance and the container answer; the first one listens nowhere
+	second := startFakeServer(t, redisServer("+PONG"))
+	container := startFakeServer(t, redisServer("+PONG"))
+	sm.cache.SetAll([]Service{
+		{Name: "Redis", Type: TypeRedis, Status: StatusStopped, ExpectedPort: portOf(unusedAddress(t))},
+		{Name: "Redis6380", Type: TypeRedis, Status: StatusStopped, ExpectedPort: portOf(second)},
+		{Name: "cache", Type: TypeRedis, Status: StatusStopped, Backend: BackendDocker, PublishedPort: portOf(container)},
+	})
+	if err := sm.configManager.SetReadinessConfig(TypeRedis, ReadinessConfig{Enabled: true, TimeoutSeconds: 1}); err != nil {
+		t.Fatalf("SetReadinessConfig() failed: %v", err)
+	}
+
+	if err := sm.WaitForOperation(sm.StartService("Redis6380")); err != nil {
+		t.Errorf("Expected the instance to be probed on its own port, got %v", err)
+	}
+	if err := sm.WaitForOperation(sm.StartService("cache")); err != nil {
+		t.Errorf("Expected the container to be probed on its published port, got %v", err)
+	}
+	err := sm.WaitForOperation(sm.StartService("Redis"))
+	if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrNotReady {
+		t.Errorf("Expected ErrNotReady for the instance nothing answers for, got %v", err)
+	}
+}
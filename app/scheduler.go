@@ -0,0 +1,404 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// schedulerTickInterval is how often the scheduler looks for due runs
+	schedulerTickInterval = 30 * time.Second
+	// scheduleMissedAfter is how late a run may be noticed before it counts as missed, e.g.
+	// because the computer was asleep when it was due
+	scheduleMissedAfter = 2 * time.Minute
+	// maxNextRuns bounds how many upcoming runs NextRuns returns
+	maxNextRuns = 100
+)
+
+// ScheduleAction is the operation a schedule rule performs on its targets
+type ScheduleAction string
+
+const (
+	ScheduleStart   ScheduleAction = "start"
+	ScheduleStop    ScheduleAction = "stop"
+	ScheduleRestart ScheduleAction = "restart"
+	ScheduleEnable  ScheduleAction = "enable"
+	ScheduleDisable ScheduleAction = "disable"
+)
+
+// CatchUpPolicy decides what happens to runs that were due while the computer was asleep
+type CatchUpPolicy string
+
+const (
+	CatchUpSkip    CatchUpPolicy = "skip"     // Missed runs are dropped (default)
+	CatchUpRunOnce CatchUpPolicy = "run_once" // The most recent missed run is made up for once
+)
+
+// ScheduleRule runs an action on a service, or on every service of a type or category, at
+// the times of a cron expression, e.g. stopping SQL Server Agent at "0 19 * * mon-fri".
+// Exactly one of Service, Type and Category names the targets.
+type ScheduleRule struct {
+	Name                 string          `json:"name"`
+	Cron                 string          `json:"cron"` // minute hour day-of-month month day-of-week, in local time
+	Action               ScheduleAction  `json:"action"`
+	Service              string          `json:"service,omitempty"`
+	Type                 ServiceType     `json:"type,omitempty"`
+	Category             ServiceCategory `json:"category,omitempty"`
+	CatchUp              CatchUpPolicy   `json:"catch_up,omitempty"`
+	CatchUpWindowMinutes int             `json:"catch_up_window_minutes,omitempty"` // With run_once, runs missed by longer are skipped; 0 catches up on any
+	Disabled             bool            `json:"disabled,omitempty"`
+}
+
+// target describes the services a rule applies to
+func (r ScheduleRule) target() string {
+	switch {
+	case r.Service != "":
+		return r.Service
+	case r.Type != "":
+		return fmt.Sprintf("all %s services", r.Type)
+	default:
+		return fmt.Sprintf("all %s services", r.Category)
+	}
+}
+
+// catchesUp reports whether a run that was missed by delay is made up for
+func (r ScheduleRule) catchesUp(delay time.Duration) bool {
+	if r.CatchUp != CatchUpRunOnce {
+		return false
+	}
+	return r.CatchUpWindowMinutes == 0 || delay <= time.Duration(r.CatchUpWindowMinutes)*time.Minute
+}
+
+// validateScheduleRules checks that rule names are unique and every rule has a valid cron
+// expression, action, target and catch-up policy
+func validateScheduleRules(rules []ScheduleRule) error {
+	names := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("schedule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("duplicate schedule %q", rule.Name)
+		}
+		names[rule.Name] = true
+
+		if _, err := parseCronSchedule(rule.Cron); err != nil {
+			return fmt.Errorf("schedule %q: %w", rule.Name, err)
+		}
+		switch rule.Action {
+		case ScheduleStart, ScheduleStop, ScheduleRestart, ScheduleEnable, ScheduleDisable:
+		default:
+			return fmt.Errorf("schedule %q: unknown action %q", rule.Name, rule.Action)
+		}
+
+		targets := 0
+		for _, target := range []string{rule.Service, string(rule.Type), string(rule.Category)} {
+			if target != "" {
+				targets++
+			}
+		}
+		if targets != 1 {
+			return fmt.Errorf("schedule %q must name exactly one of a service, a type or a category", rule.Name)
+		}
+
+		switch rule.CatchUp {
+		case "", CatchUpSkip, CatchUpRunOnce:
+		default:
+			return fmt.Errorf("schedule %q: unknown catch-up policy %q", rule.Name, rule.CatchUp)
+		}
+		if rule.CatchUpWindowMinutes < 0 {
+			return fmt.Errorf("schedule %q: catch-up window cannot be negative", rule.Name)
+		}
+	}
+	return nil
+}
+
+// ScheduleRun is the outcome of the most recent due run of a schedule rule
+type ScheduleRun struct {
+	ScheduledAt time.Time            `json:"ScheduledAt"`
+	Missed      bool                 `json:"Missed"`  // The run was due while ShutDB could not run it, e.g. during sleep
+	Skipped     bool                 `json:"Skipped"` // The missed run was not made up for
+	Success     bool                 `json:"Success"`
+	Results     []GroupServiceResult `json:"Results"` // Outcome per target service
+}
+
+// ScheduleInfo is a schedule rule with its next and last run, as listed in the UI
+type ScheduleInfo struct {
+	Rule    ScheduleRule `json:"Rule"`
+	NextRun *time.Time   `json:"NextRun,omitempty"` // Not set for disabled rules
+	LastRun *ScheduleRun `json:"LastRun,omitempty"`
+}
+
+// scheduler runs the configured schedule rules while the application runs
+type scheduler struct {
+	manager   *ServiceManager
+	now       func() time.Time // Replaced in tests to advance the clock
+	mu        sync.Mutex
+	lastCheck time.Time              // Runs due up to this time have been handled
+	lastRuns  map[string]ScheduleRun // Most recent run by rule name
+	executing map[string]bool        // Rules whose previous run has not finished yet
+	running   sync.WaitGroup
+}
+
+// newScheduler creates a scheduler for the services of a manager
+func newScheduler(sm *ServiceManager) *scheduler {
+	return &scheduler{
+		manager:   sm,
+		now:       wallClock,
+		lastRuns:  make(map[string]ScheduleRun),
+		executing: make(map[string]bool),
+	}
+}
+
+// wallClock returns the current time without its monotonic reading. The monotonic clock may
+// stand still while the computer sleeps, which would hide the runs that were missed meanwhile.
+func wallClock() time.Time {
+	return time.Now().Round(0)
+}
+
+// Run looks for due runs until the context is cancelled. Runs due before it started are not made up for.
+func (s *scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.lastCheck = s.now()
+	s.mu.Unlock()
+
+	ticker := time.NewTicker(schedulerTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick()
+		}
+	}
+}
+
+// tick runs every rule that became due since the previous tick. A rule that became due several
+// times, e.g. while the computer slept, runs at most once, for its most recent due time.
+func (s *scheduler) tick() {
+	now := s.now()
+	s.mu.Lock()
+	since := s.lastCheck
+	s.lastCheck = now
+	s.mu.Unlock()
+
+	if since.IsZero() || s.manager.configManager == nil {
+		return
+	}
+
+	for _, rule := range s.manager.configManager.GetSchedules() {
+		if rule.Disabled {
+			continue
+		}
+		schedule, err := parseCronSchedule(rule.Cron)
+		if err != nil {
+			continue
+		}
+
+		due := latestRun(schedule, since, now)
+		if due.IsZero() {
+			continue
+		}
+
+		missed := now.Sub(due) > scheduleMissedAfter
+		if missed && !rule.catchesUp(now.Sub(due)) {
+			log.Printf("Skipping schedule %s missed at %s", rule.Name, due.Format(time.RFC3339))
+			s.record(rule.Name, ScheduleRun{ScheduledAt: due, Missed: true, Skipped: true, Success: true})
+			continue
+		}
+
+		s.mu.Lock()
+		if s.executing[rule.Name] {
+			s.mu.Unlock()
+			log.Printf("Skipping schedule %s due at %s, its previous run has not finished", rule.Name, due.Format(time.RFC3339))
+			continue
+		}
+		s.executing[rule.Name] = true
+		s.mu.Unlock()
+
+		s.running.Add(1)
+		go s.execute(rule, due, missed)
+	}
+}
+
+// latestRun returns the last time in (since, until] at which a schedule runs, or the zero time
+func latestRun(schedule *cronSchedule, since, until time.Time) time.Time {
+	var latest time.Time
+	for next := schedule.next(since); !next.IsZero() && !next.After(until); next = schedule.next(next) {
+		latest = next
+	}
+	return latest
+}
+
+// execute performs the action of a rule on each of its targets in turn
+func (s *scheduler) execute(rule ScheduleRule, due time.Time, missed bool) {
+	defer s.running.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.executing, rule.Name)
+		s.mu.Unlock()
+	}()
+
+	run := ScheduleRun{ScheduledAt: due, Missed: missed, Success: true, Results: []GroupServiceResult{}}
+	names, err := s.manager.scheduleTargets(rule)
+	if err != nil {
+		log.Printf("Schedule %s could not find %s: %v", rule.Name, rule.target(), err)
+		run.Success = false
+	}
+
+	control := s.manager.control(InitiatorScheduler)
+	for _, name := range names {
+		result := control.applyScheduleAction(rule.Action, name)
+		if !result.Success {
+			run.Success = false
+			log.Printf("Schedule %s failed to %s %s: %s", rule.Name, rule.Action, name, result.Error)
+		}
+		run.Results = append(run.Results, result)
+	}
+	s.record(rule.Name, run)
+}
+
+// record remembers the most recent run of a rule
+func (s *scheduler) record(name string, run ScheduleRun) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRuns[name] = run
+}
+
+// lastRun returns the most recent run of a rule, if it ran since ShutDB started
+func (s *scheduler) lastRun(name string) (ScheduleRun, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run, exists := s.lastRuns[name]
+	return run, exists
+}
+
+// scheduleTargets returns the services a rule applies to in name order
+func (sm *ServiceManager) scheduleTargets(rule ScheduleRule) ([]string, error) {
+	if rule.Service != "" {
+		return []string{rule.Service}, nil
+	}
+
+	services, err := sm.GetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, service := range services {
+		if (rule.Type != "" && service.Type == rule.Type) || (rule.Category != "" && service.Category == rule.Category) {
+			names = append(names, service.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// applyScheduleAction performs a scheduled action on a service. Starting a running service,
+// stopping a stopped one, restarting one that is not running and disabling one whose backend
+// cannot disable services, such as a container, are skipped.
+func (c *ServiceControl) applyScheduleAction(action ScheduleAction, name string) GroupServiceResult {
+	switch action {
+	case ScheduleStart:
+		return c.startGroupMember(name)
+	case ScheduleStop:
+		return c.stopGroupMember(name)
+	case ScheduleRestart:
+		status, err := c.manager.adapter.GetServiceStatus(name)
+		if err != nil {
+			return newGroupServiceResult(name, err)
+		}
+		if status != StatusRunning {
+			return GroupServiceResult{Service: name, Success: true, Skipped: true}
+		}
+		return newGroupServiceResult(name, c.RestartService(name))
+	case ScheduleEnable:
+		return newGroupServiceResult(name, c.EnableService(name))
+	case ScheduleDisable:
+		if service, found := c.manager.findService(name); found && !service.supportsStartupType(StartupDisabled) {
+			return GroupServiceResult{Service: name, Success: true, Skipped: true}
+		}
+		return newGroupServiceResult(name, c.DisableService(name))
+	default:
+		return newGroupServiceResult(name, &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Unknown schedule action: %s", action),
+			Service: name,
+		})
+	}
+}
+
+// ListSchedules returns the configured schedule rules with their next and most recent runs
+func (sm *ServiceManager) ListSchedules() []ScheduleInfo {
+	if sm.configManager == nil {
+		return []ScheduleInfo{}
+	}
+
+	now := wallClock()
+	if sm.scheduler != nil {
+		now = sm.scheduler.now()
+	}
+
+	rules := sm.configManager.GetSchedules()
+	schedules := make([]ScheduleInfo, 0, len(rules))
+	for _, rule := range rules {
+		info := ScheduleInfo{Rule: rule}
+		if schedule, err := parseCronSchedule(rule.Cron); err == nil && !rule.Disabled {
+			if next := schedule.next(now); !next.IsZero() {
+				info.NextRun = &next
+			}
+		}
+		if sm.scheduler != nil {
+			if run, exists := sm.scheduler.lastRun(rule.Name); exists {
+				info.LastRun = &run
+			}
+		}
+		schedules = append(schedules, info)
+	}
+	return schedules
+}
+
+// NextRuns returns the next count times at which a schedule rule runs, at most 100
+func (sm *ServiceManager) NextRuns(name string, count int) ([]time.Time, error) {
+	rule, exists := ScheduleRule{}, false
+	if sm.configManager != nil {
+		rule, exists = sm.configManager.GetSchedule(name)
+	}
+	if !exists {
+		return nil, &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("Schedule not found: %s", name),
+		}
+	}
+
+	schedule, err := parseCronSchedule(rule.Cron)
+	if err != nil {
+		return nil, &ServiceError{Code: ErrInvalidState, Message: err.Error()}
+	}
+
+	if count > maxNextRuns {
+		count = maxNextRuns
+	}
+	runs := []time.Time{}
+	if rule.Disabled {
+		return runs, nil
+	}
+
+	next := wallClock()
+	if sm.scheduler != nil {
+		next = sm.scheduler.now()
+	}
+	for len(runs) < count {
+		if next = schedule.next(next); next.IsZero() {
+			break
+		}
+		runs = append(runs, next)
+	}
+	return runs, nil
+}
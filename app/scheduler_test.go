@@ -0,0 +1,206 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// createSchedulerManager returns a manager with the given schedule rules whose scheduler last
+// looked for due runs at lastCheck
+func createSchedulerManager(t *testing.T, lastCheck time.Time, rules ...ScheduleRule) (*ServiceManager, *fakeServiceAdapter) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "MSSQLSERVER", Status: StatusRunning},
+		OSService{Name: "SQLSERVERAGENT", Status: StatusRunning},
+		OSService{Name: "RabbitMQ", Status: StatusRunning},
+		OSService{Name: "Redis", Status: StatusRunning},
+	)
+	sm := createTestServiceManager(t, adapter)
+	for _, rule := range rules {
+		if err := sm.configManager.SetSchedule(rule); err != nil {
+			t.Fatalf("SetSchedule() failed: %v", err)
+		}
+	}
+	sm.scheduler.lastCheck = lastCheck
+	return sm, adapter
+}
+
+// tickAt runs the scheduler at the given time and waits for the runs it started
+func tickAt(sm *ServiceManager, now time.Time) {
+	sm.scheduler.now = func() time.Time { return now }
+	sm.scheduler.tick()
+	sm.scheduler.running.Wait()
+}
+
+var agentStop = ScheduleRule{Name: "agent-stop", Cron: "0 19 * * mon-fri", Action: ScheduleStop, Service: "SQLSERVERAGENT"}
+
+func TestSchedulerRunsDueRule(t *testing.T) {
+	friday := time.Date(2026, 10, 16, 18, 59, 40, 0, time.Local)
+	sm, adapter := createSchedulerManager(t, friday, agentStop)
+
+	tickAt(sm, friday.Add(10*time.Second))
+	if status, _ := adapter.GetServiceStatus("SQLSERVERAGENT"); status != StatusRunning {
+		t.Fatalf("Expected the rule not to run before it is due, got %s", status)
+	}
+
+	tickAt(sm, friday.Add(40*time.Second))
+	if status, _ := adapter.GetServiceStatus("SQLSERVERAGENT"); status != StatusStopped {
+		t.Fatalf("Expected the agent to be stopped at 19:00, got %s", status)
+	}
+
+	history, err := sm.GetHistory(AuditFilter{Initiator: InitiatorScheduler})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	if len(history) != 1 || history[0].Operation != "stop" || history[0].Service != "SQLSERVERAGENT" {
+		t.Errorf("Expected the scheduled stop to be audited, got %+v", history)
+	}
+
+	schedules := sm.ListSchedules()
+	if len(schedules) != 1 || schedules[0].LastRun == nil || !schedules[0].LastRun.Success || schedules[0].LastRun.Missed {
+		t.Fatalf("Expected the run to be listed, got %+v", schedules)
+	}
+	monday := time.Date(2026, 10, 19, 19, 0, 0, 0, time.Local)
+	if next := schedules[0].NextRun; next == nil || !next.Equal(monday) {
+		t.Errorf("Expected the next run on Monday, got %v", next)
+	}
+}
+
+func TestSchedulerTargetsTypesAndCategories(t *testing.T) {
+	friday := time.Date(2026, 10, 16, 18, 59, 40, 0, time.Local)
+	sm, adapter := createSchedulerManager(t, friday,
+		ScheduleRule{Name: "sql-stop", Cron: "0 19 * * *", Action: ScheduleStop, Category: CategorySQL},
+		ScheduleRule{Name: "rabbitmq-restart", Cron: "0 19 * * *", Action: ScheduleRestart, Type: TypeRabbitMQ},
+	)
+
+	tickAt(sm, friday.Add(30*time.Second))
+
+	calls := adapter.callLog()
+	for _, expected := range []string{"stop MSSQLSERVER", "stop SQLSERVERAGENT", "restart RabbitMQ"} {
+		if !strings.Contains(strings.Join(calls, ","), expected) {
+			t.Errorf("Expected %q, got %v", expected, calls)
+		}
+	}
+	if status, _ := adapter.GetServiceStatus("Redis"); status != StatusRunning {
+		t.Errorf("Expected services outside the targets to be left alone, got %s", status)
+	}
+}
+
+func TestSchedulerSkipsServicesThatCannotBeDisabled(t *testing.T) {
+	friday := time.Date(2026, 10, 16, 18, 59, 40, 0, time.Local)
+	rule := ScheduleRule{Name: "sql-disable", Cron: "0 19 * * *", Action: ScheduleDisable, Category: CategorySQL}
+	sm, adapter := createSchedulerManager(t, friday, rule)
+	adapter.services["SQLSERVERAGENT"].StartupTypes = dockerStartupTypes
+
+	tickAt(sm, friday.Add(30*time.Second))
+
+	if startupType, _ := adapter.GetStartupType("MSSQLSERVER"); startupType != StartupDisabled {
+		t.Errorf("Expected MSSQLSERVER to be disabled, got %s", startupType)
+	}
+	if calls := strings.Join(adapter.callLog(), ","); strings.Contains(calls, "startup-type SQLSERVERAGENT") {
+		t.Errorf("Expected no startup type change for SQLSERVERAGENT, got %s", calls)
+	}
+
+	schedules := sm.ListSchedules()
+	if len(schedules) != 1 || schedules[0].LastRun == nil || !schedules[0].LastRun.Success {
+		t.Fatalf("Expected the run to succeed, got %+v", schedules)
+	}
+	for _, result := range schedules[0].LastRun.Results {
+		if expected := result.Service == "SQLSERVERAGENT"; result.Skipped != expected {
+			t.Errorf("Service %s: expected Skipped %v, got %+v", result.Service, expected, result)
+		}
+	}
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	nightly := ScheduleRule{Name: "rabbitmq-restart", Cron: "0 3 * * *", Action: ScheduleRestart, Service: "RabbitMQ"}
+	// The computer went to sleep at 01:00 and slept through the 03:00 run
+	asleep := time.Date(2026, 10, 17, 1, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		catchUp  CatchUpPolicy
+		window   int
+		resumed  time.Time
+		restarts int
+	}{
+		{"skip by default", "", 0, asleep.Add(4 * time.Hour), 0},
+		{"run once", CatchUpRunOnce, 0, asleep.Add(4 * time.Hour), 1},
+		{"run once within the window", CatchUpRunOnce, 180, asleep.Add(4 * time.Hour), 1},
+		{"run once after the window", CatchUpRunOnce, 60, asleep.Add(4 * time.Hour), 0},
+		{"run once after several missed runs", CatchUpRunOnce, 0, asleep.Add(72 * time.Hour), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := nightly
+			rule.CatchUp, rule.CatchUpWindowMinutes = tt.catchUp, tt.window
+			sm, adapter := createSchedulerManager(t, asleep, rule)
+
+			tickAt(sm, tt.resumed)
+
+			if restarts := strings.Count(strings.Join(adapter.callLog(), ","), "restart RabbitMQ"); restarts != tt.restarts {
+				t.Errorf("Expected %d restarts, got %d", tt.restarts, restarts)
+			}
+			run, exists := sm.scheduler.lastRun("rabbitmq-restart")
+			if !exists || !run.Missed || run.Skipped != (tt.restarts == 0) {
+				t.Errorf("Expected the run to be recorded as missed, got %+v", run)
+			}
+		})
+	}
+}
+
+func TestSchedulerNextRuns(t *testing.T) {
+	friday := time.Date(2026, 10, 16, 19, 0, 10, 0, time.Local)
+	sm, _ := createSchedulerManager(t, friday, agentStop)
+	sm.scheduler.now = func() time.Time { return friday }
+
+	runs, err := sm.NextRuns("agent-stop", 3)
+	if err != nil {
+		t.Fatalf("NextRuns() failed: %v", err)
+	}
+	expected := []time.Time{
+		time.Date(2026, 10, 19, 19, 0, 0, 0, time.Local),
+		time.Date(2026, 10, 20, 19, 0, 0, 0, time.Local),
+		time.Date(2026, 10, 21, 19, 0, 0, 0, time.Local),
+	}
+	if len(runs) != len(expected) {
+		t.Fatalf("Expected %d runs, got %v", len(expected), runs)
+	}
+	for i := range expected {
+		if !runs[i].Equal(expected[i]) {
+			t.Errorf("Run %d: expected %s, got %s", i, expected[i], runs[i])
+		}
+	}
+
+	if _, err := sm.NextRuns("missing", 3); err == nil {
+		t.Error("Expected an error for an unknown schedule")
+	}
+}
+
+func TestScheduleRuleValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []ScheduleRule
+	}{
+		{"missing name", []ScheduleRule{{Cron: "0 19 * * *", Action: ScheduleStop, Service: "SQLSERVERAGENT"}}},
+		{"duplicate name", []ScheduleRule{agentStop, agentStop}},
+		{"invalid cron", []ScheduleRule{{Name: "agent", Cron: "at 19:00", Action: ScheduleStop, Service: "SQLSERVERAGENT"}}},
+		{"unknown action", []ScheduleRule{{Name: "agent", Cron: "0 19 * * *", Action: "pause", Service: "SQLSERVERAGENT"}}},
+		{"no target", []ScheduleRule{{Name: "agent", Cron: "0 19 * * *", Action: ScheduleStop}}},
+		{"two targets", []ScheduleRule{{Name: "agent", Cron: "0 19 * * *", Action: ScheduleStop, Service: "SQLSERVERAGENT", Type: TypeMSSQL}}},
+		{"unknown catch-up", []ScheduleRule{{Name: "agent", Cron: "0 19 * * *", Action: ScheduleStop, Service: "SQLSERVERAGENT", CatchUp: "always"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateScheduleRules(tt.rules); err == nil {
+				t.Error("Expected the rules to be rejected")
+			}
+		})
+	}
+
+	if err := validateScheduleRules([]ScheduleRule{agentStop}); err != nil {
+		t.Errorf("Expected a valid rule to pass, got %v", err)
+	}
+}
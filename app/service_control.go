@@ -0,0 +1,170 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// Initiator identifies where a control operation was requested
+type Initiator string
+
+const (
+	InitiatorGUI       Initiator = "gui"
+	InitiatorTray      Initiator = "tray"
+	InitiatorHotkey    Initiator = "hotkey"
+	InitiatorCLI       Initiator = "cli"
+	InitiatorAPI       Initiator = "api"
+	InitiatorWatchdog  Initiator = "watchdog"
+	InitiatorIdle      Initiator = "idle"
+	InitiatorProxy     Initiator = "proxy"
+	InitiatorScheduler Initiator = "scheduler"
+)
+
+// ServiceControl performs control operations on behalf of an initiator,
+// so that every operation is attributed in the audit log
+type ServiceControl struct {
+	manager   *ServiceManager
+	initiator Initiator
+	ctx       context.Context // Operations are canceled when it ends
+}
+
+// controlOperation performs an operation on a service, giving up once ctx ends
+type controlOperation func(ctx context.Context, name string) error
+
+// control returns the operations available to an initiator
+func (sm *ServiceManager) control(initiator Initiator) *ServiceControl {
+	return &ServiceControl{manager: sm, initiator: initiator, ctx: sm.baseContext()}
+}
+
+// withContext returns the same operations bound to ctx, e.g. the context of an API request
+func (c *ServiceControl) withContext(ctx context.Context) *ServiceControl {
+	return &ServiceControl{manager: c.manager, initiator: c.initiator, ctx: ctx}
+}
+
+// StartService starts a database service
+func (c *ServiceControl) StartService(name string) error {
+	return c.run(c.ctx, "start", name, c.manager.startService)
+}
+
+// StopService stops a database service after the running services that depend on it
+func (c *ServiceControl) StopService(name string) error {
+	return c.run(c.ctx, "stop", name, c.stopWithDependents)
+}
+
+// RestartService restarts a database service. Running services that depend on it are
+// stopped first and started again afterwards.
+func (c *ServiceControl) RestartService(name string) error {
+	return c.run(c.ctx, "restart", name, c.restartWithDependents)
+}
+
+// PauseService pauses a running database service
+func (c *ServiceControl) PauseService(name string) error {
+	return c.run(c.ctx, "pause", name, c.manager.pauseService)
+}
+
+// ContinueService resumes a paused database service
+func (c *ServiceControl) ContinueService(name string) error {
+	return c.run(c.ctx, "continue", name, c.manager.continueService)
+}
+
+// EnableService enables a disabled database service, restoring its previous startup type
+func (c *ServiceControl) EnableService(name string) error {
+	return c.run(c.ctx, "enable", name, withoutContext(c.manager.enableService))
+}
+
+// DisableService disables a database service
+func (c *ServiceControl) DisableService(name string) error {
+	return c.run(c.ctx, "disable", name, withoutContext(c.manager.disableService))
+}
+
+// SetStartupType changes the startup type of a database service
+func (c *ServiceControl) SetStartupType(name string, startupType StartupType) error {
+	return c.run(c.ctx, "startup-type", name, withoutContext(func(name string) error {
+		return c.manager.setStartupType(name, startupType)
+	}))
+}
+
+// withoutContext adapts an operation that completes immediately and cannot be canceled
+func withoutContext(op func(name string) error) controlOperation {
+	return func(_ context.Context, name string) error {
+		return op(name)
+	}
+}
+
+// SetServiceControlState enables or disables ShutDB's service control
+func (c *ServiceControl) SetServiceControlState(enabled bool) error {
+	operation := "disable_control"
+	if enabled {
+		operation = "enable_control"
+	}
+
+	started := time.Now()
+	err := c.manager.setServiceControlState(enabled)
+	c.audit(AuditEntry{Operation: operation}, started, err)
+	return err
+}
+
+// launch begins an operation in the background and returns its ID right away
+func (c *ServiceControl) launch(operation, name string, op controlOperation) string {
+	tracked, ctx := c.manager.operations.begin(c.ctx, operation, name, c.initiator)
+	go c.execute(ctx, tracked, op)
+	return tracked.ID
+}
+
+// run performs an operation and returns its error. The operation is tracked, so it can be
+// canceled while it runs, and ends when ctx does.
+func (c *ServiceControl) run(ctx context.Context, operation, name string, op controlOperation) error {
+	tracked, ctx := c.manager.operations.begin(ctx, operation, name, c.initiator)
+	return c.execute(ctx, tracked, op)
+}
+
+// execute performs a tracked operation and records its outcome, duration and status change
+func (c *ServiceControl) execute(ctx context.Context, tracked *trackedOperation, op controlOperation) error {
+	sm := c.manager
+	operation, name := tracked.Operation.Operation, tracked.Service
+	entry := AuditEntry{Operation: operation, Service: name, OperationID: tracked.ID}
+	if status, err := sm.adapter.GetServiceStatus(name); err == nil {
+		entry.PriorStatus = status
+	}
+
+	started := time.Now()
+	err := op(ctx, name)
+	sm.metrics.ObserveOperation(operation, name, time.Since(started), err)
+
+	if status, statusErr := sm.adapter.GetServiceStatus(name); statusErr == nil {
+		entry.ResultingStatus = status
+	}
+	c.audit(entry, started, err)
+
+	finished := sm.operations.finish(tracked, err)
+	sm.emitEvent(OperationEventName, finished)
+
+	return err
+}
+
+// audit completes an entry with the initiator, timing and outcome, and appends it to the audit log
+func (c *ServiceControl) audit(entry AuditEntry, started time.Time, err error) {
+	if c.manager.auditLog == nil {
+		return
+	}
+
+	entry.Timestamp = started
+	entry.Initiator = c.initiator
+	entry.DurationMs = time.Since(started).Milliseconds()
+	entry.Success = err == nil
+	if err != nil {
+		entry.Error = err.Error()
+
+		var serviceErr *ServiceError
+		if errors.As(err, &serviceErr) {
+			code := serviceErr.Code
+			entry.ErrorCode = &code
+		}
+	}
+
+	if appendErr := c.manager.auditLog.Append(entry); appendErr != nil {
+		log.Printf("Warning: %v", appendErr)
+	}
+}
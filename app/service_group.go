@@ -0,0 +1,306 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ServiceGroup is a named stack of services that are controlled together, e.g. "billing-stack".
+// Members are either listed in Services, which are started one after another in declared
+// order, or arranged in Tiers, whose services are started in parallel before the next tier.
+// Groups are always stopped in the reverse order.
+type ServiceGroup struct {
+	Name     string     `json:"name"`
+	Services []string   `json:"services,omitempty"`
+	Tiers    [][]string `json:"tiers,omitempty"`
+}
+
+// Stages returns the group's members as tiers in start order
+func (g ServiceGroup) Stages() [][]string {
+	if len(g.Tiers) > 0 {
+		return g.Tiers
+	}
+
+	stages := make([][]string, 0, len(g.Services))
+	for _, name := range g.Services {
+		stages = append(stages, []string{name})
+	}
+	return stages
+}
+
+// Members returns every member of the group in start order
+func (g ServiceGroup) Members() []string {
+	var members []string
+	for _, tier := range g.Stages() {
+		members = append(members, tier...)
+	}
+	return members
+}
+
+// copyServiceGroups returns a deep copy so callers cannot modify the stored configuration
+func copyServiceGroups(groups []ServiceGroup) []ServiceGroup {
+	if groups == nil {
+		return nil
+	}
+
+	copied := make([]ServiceGroup, len(groups))
+	for i, group := range groups {
+		copied[i] = ServiceGroup{
+			Name:     group.Name,
+			Services: append([]string(nil), group.Services...),
+		}
+		if group.Tiers != nil {
+			copied[i].Tiers = make([][]string, len(group.Tiers))
+			for j, tier := range group.Tiers {
+				copied[i].Tiers[j] = append([]string(nil), tier...)
+			}
+		}
+	}
+	return copied
+}
+
+// validateServiceGroups checks group names are unique and every group has a well-formed member list
+func validateServiceGroups(groups []ServiceGroup) error {
+	names := make(map[string]bool, len(groups))
+	for i, group := range groups {
+		if group.Name == "" {
+			return fmt.Errorf("service group %d has no name", i+1)
+		}
+		if names[group.Name] {
+			return fmt.Errorf("duplicate service group %q", group.Name)
+		}
+		names[group.Name] = true
+
+		if len(group.Services) > 0 && len(group.Tiers) > 0 {
+			return fmt.Errorf("service group %q must list either services or tiers, not both", group.Name)
+		}
+		if len(group.Services) == 0 && len(group.Tiers) == 0 {
+			return fmt.Errorf("service group %q has no services", group.Name)
+		}
+
+		members := make(map[string]bool)
+		for j, tier := range group.Stages() {
+			if len(tier) == 0 {
+				return fmt.Errorf("service group %q: tier %d is empty", group.Name, j+1)
+			}
+			for _, member := range tier {
+				if member == "" {
+					return fmt.Errorf("service group %q contains an empty service name", group.Name)
+				}
+				if members[member] {
+					return fmt.Errorf("service group %q lists service %q more than once", group.Name, member)
+				}
+				members[member] = true
+			}
+		}
+	}
+	return nil
+}
+
+// GroupServiceResult is the outcome of a group operation for a single member
+type GroupServiceResult struct {
+	Service string     `json:"Service"`
+	Success bool       `json:"Success"`
+	Skipped bool       `json:"Skipped"` // The service was already in the requested state or cannot enter it
+	Error   string     `json:"Error,omitempty"`
+	Code    *ErrorCode `json:"Code,omitempty"`
+}
+
+// GroupOperationResult is the outcome of a group operation for every member
+type GroupOperationResult struct {
+	Group     string               `json:"Group"`
+	Operation string               `json:"Operation"`
+	Success   bool                 `json:"Success"`
+	Results   []GroupServiceResult `json:"Results"`
+}
+
+// newGroupServiceResult converts the error of a member operation into a result
+func newGroupServiceResult(name string, err error) GroupServiceResult {
+	result := GroupServiceResult{Service: name, Success: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+		if serviceErr, ok := err.(*ServiceError); ok {
+			code := serviceErr.Code
+			result.Code = &code
+		}
+	}
+	return result
+}
+
+// GetServiceGroups returns the configured service groups
+func (sm *ServiceManager) GetServiceGroups() []ServiceGroup {
+	if sm.configManager == nil {
+		return []ServiceGroup{}
+	}
+	return sm.configManager.GetServiceGroups()
+}
+
+// StartGroup starts every member of a group on behalf of the GUI
+func (sm *ServiceManager) StartGroup(name string) (*GroupOperationResult, error) {
+	return sm.control(InitiatorGUI).StartGroup(name)
+}
+
+// StopGroup stops every member of a group on behalf of the GUI
+func (sm *ServiceManager) StopGroup(name string) (*GroupOperationResult, error) {
+	return sm.control(InitiatorGUI).StopGroup(name)
+}
+
+// RestartGroup restarts every member of a group on behalf of the GUI
+func (sm *ServiceManager) RestartGroup(name string) (*GroupOperationResult, error) {
+	return sm.control(InitiatorGUI).RestartGroup(name)
+}
+
+// StartGroup starts every member of a group, tier by tier.
+// A failing member does not abort the operation; its failure is reported in the result.
+func (c *ServiceControl) StartGroup(name string) (*GroupOperationResult, error) {
+	group, err := c.manager.prepareGroupOperation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	results := runGroupStages(group.Stages(), c.startGroupMember)
+	return newGroupOperationResult(group, "start", group.Members(), results), nil
+}
+
+// StopGroup stops every member of a group in reverse tier order.
+// A failing member does not abort the operation; its failure is reported in the result.
+func (c *ServiceControl) StopGroup(name string) (*GroupOperationResult, error) {
+	group, err := c.manager.prepareGroupOperation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stages := reverseStages(group.Stages())
+	results := runGroupStages(stages, c.stopGroupMember)
+
+	var order []string
+	for _, tier := range stages {
+		order = append(order, tier...)
+	}
+	return newGroupOperationResult(group, "stop", order, results), nil
+}
+
+// RestartGroup stops the group in reverse tier order and then starts it again in tier order,
+// so dependents never run against a dependency that is being restarted underneath them
+func (c *ServiceControl) RestartGroup(name string) (*GroupOperationResult, error) {
+	group, err := c.manager.prepareGroupOperation(name)
+	if err != nil {
+		return nil, err
+	}
+
+	stopped := runGroupStages(reverseStages(group.Stages()), c.stopGroupMember)
+
+	// Members that could not be stopped are still running the old instance,
+	// so report the stop failure rather than a skipped start
+	results := runGroupStages(group.Stages(), func(member string) GroupServiceResult {
+		if result := stopped[member]; !result.Success {
+			return result
+		}
+		result := c.startGroupMember(member)
+		result.Skipped = false
+		return result
+	})
+
+	return newGroupOperationResult(group, "restart", group.Members(), results), nil
+}
+
+// prepareGroupOperation looks up a group and checks that service operations are allowed
+func (sm *ServiceManager) prepareGroupOperation(name string) (ServiceGroup, error) {
+	if err := sm.RequireElevationForOperation(); err != nil {
+		return ServiceGroup{}, err
+	}
+
+	group, exists := ServiceGroup{}, false
+	if sm.configManager != nil {
+		group, exists = sm.configManager.GetServiceGroup(name)
+	}
+	if !exists {
+		return ServiceGroup{}, &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("Service group not found: %s", name),
+			Service: name,
+		}
+	}
+
+	return group, nil
+}
+
+// runGroupStages runs an operation tier by tier, running the members of a tier in parallel
+func runGroupStages(stages [][]string, op func(name string) GroupServiceResult) map[string]GroupServiceResult {
+	results := make(map[string]GroupServiceResult)
+
+	for _, tier := range stages {
+		tierResults := make([]GroupServiceResult, len(tier))
+
+		var wg sync.WaitGroup
+		for i, member := range tier {
+			wg.Add(1)
+			go func(i int, member string) {
+				defer wg.Done()
+				tierResults[i] = op(member)
+			}(i, member)
+		}
+		wg.Wait()
+
+		for _, result := range tierResults {
+			results[result.Service] = result
+		}
+	}
+
+	return results
+}
+
+// startGroupMember starts a member unless it is already running
+func (c *ServiceControl) startGroupMember(name string) GroupServiceResult {
+	status, err := c.manager.adapter.GetServiceStatus(name)
+	if err != nil {
+		return newGroupServiceResult(name, err)
+	}
+	if status == StatusRunning || status == StatusStarting {
+		return GroupServiceResult{Service: name, Success: true, Skipped: true}
+	}
+
+	return newGroupServiceResult(name, c.StartService(name))
+}
+
+// stopGroupMember stops a member unless it is already stopped
+func (c *ServiceControl) stopGroupMember(name string) GroupServiceResult {
+	status, err := c.manager.adapter.GetServiceStatus(name)
+	if err != nil {
+		return newGroupServiceResult(name, err)
+	}
+	if status == StatusStopped || status == StatusStopping {
+		return GroupServiceResult{Service: name, Success: true, Skipped: true}
+	}
+
+	return newGroupServiceResult(name, c.StopService(name))
+}
+
+// newGroupOperationResult collects member results in the order they were processed
+func newGroupOperationResult(group ServiceGroup, operation string, order []string, results map[string]GroupServiceResult) *GroupOperationResult {
+	result := &GroupOperationResult{
+		Group:     group.Name,
+		Operation: operation,
+		Success:   true,
+		Results:   make([]GroupServiceResult, 0, len(order)),
+	}
+
+	for _, member := range order {
+		memberResult := results[member]
+		if !memberResult.Success {
+			result.Success = false
+		}
+		result.Results = append(result.Results, memberResult)
+	}
+
+	return result
+}
+
+// reverseStages returns the tiers in reverse order, keeping the members of each tier unchanged
+func reverseStages(stages [][]string) [][]string {
+	reversed := make([][]string, len(stages))
+	for i, tier := range stages {
+		reversed[len(stages)-1-i] = tier
+	}
+	return reversed
+}
@@ -0,0 +1,234 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func createTestGroupManager(t *testing.T, group ServiceGroup) (*ServiceManager, *fakeServiceAdapter) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "postgresql-x64-16", Status: StatusStopped},
+		OSService{Name: "Redis", Status: StatusStopped},
+		OSService{Name: "RabbitMQ", Status: StatusStopped},
+		OSService{Name: "MongoDB", Status: StatusRunning},
+	)
+	sm := createTestServiceManager(t, adapter)
+
+	if err := sm.configManager.SetServiceGroup(group); err != nil {
+		t.Fatalf("SetServiceGroup() failed: %v", err)
+	}
+	return sm, adapter
+}
+
+// indexOf returns the position of a call in the adapter's call log
+func indexOf(calls []string, call string) int {
+	for i, c := range calls {
+		if c == call {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestStartGroupDeclaredOrder(t *testing.T) {
+	sm, adapter := createTestGroupManager(t, ServiceGroup{
+		Name:     "billing-stack",
+		Services: []string{"postgresql-x64-16", "Redis", "MongoDB"},
+	})
+
+	result, err := sm.StartGroup("billing-stack")
+	if err != nil {
+		t.Fatalf("StartGroup() failed: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected group start to succeed, got %+v", result)
+	}
+
+	expectedCalls := []string{"start postgresql-x64-16", "start Redis"}
+	if calls := adapter.callLog(); strings.Join(calls, ",") != strings.Join(expectedCalls, ",") {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+
+	if len(result.Results) != 3 {
+		t.Fatalf("Expected 3 results, got %+v", result.Results)
+	}
+	if last := result.Results[2]; last.Service != "MongoDB" || !last.Success || !last.Skipped {
+		t.Errorf("Running member should be skipped, got %+v", last)
+	}
+}
+
+func TestStartGroupTiers(t *testing.T) {
+	sm, adapter := createTestGroupManager(t, ServiceGroup{
+		Name:  "billing-stack",
+		Tiers: [][]string{{"postgresql-x64-16"}, {"Redis", "RabbitMQ"}},
+	})
+
+	if _, err := sm.StartGroup("billing-stack"); err != nil {
+		t.Fatalf("StartGroup() failed: %v", err)
+	}
+
+	calls := adapter.callLog()
+	database := indexOf(calls, "start postgresql-x64-16")
+	for _, call := range []string{"start Redis", "start RabbitMQ"} {
+		if i := indexOf(calls, call); i < 0 || i < database {
+			t.Errorf("Expected %q after the first tier, got %v", call, calls)
+		}
+	}
+}
+
+func TestStopGroupReverseOrder(t *testing.T) {
+	sm, adapter := createTestGroupManager(t, ServiceGroup{
+		Name:  "billing-stack",
+		Tiers: [][]string{{"postgresql-x64-16"}, {"Redis", "RabbitMQ"}},
+	})
+	for _, name := range []string{"postgresql-x64-16", "Redis", "RabbitMQ"} {
+		adapter.setStatus(name, StatusRunning)
+	}
+
+	result, err := sm.StopGroup("billing-stack")
+	if err != nil {
+		t.Fatalf("StopGroup() failed: %v", err)
+	}
+
+	calls := adapter.callLog()
+	database := indexOf(calls, "stop postgresql-x64-16")
+	if database != len(calls)-1 {
+		t.Errorf("Expected the first tier to stop last, got %v", calls)
+	}
+	if result.Results[len(result.Results)-1].Service != "postgresql-x64-16" {
+		t.Errorf("Expected results in stop order, got %+v", result.Results)
+	}
+}
+
+func TestGroupOperationReportsFailures(t *testing.T) {
+	sm, adapter := createTestGroupManager(t, ServiceGroup{
+		Name:     "billing-stack",
+		Services: []string{"postgresql-x64-16", "Redis", "RabbitMQ"},
+	})
+	adapter.failOn["start Redis"] = &ServiceError{Code: ErrOperationTimeout, Message: "Timed out", Service: "Redis"}
+
+	result, err := sm.StartGroup("billing-stack")
+	if err != nil {
+		t.Fatalf("StartGroup() failed: %v", err)
+	}
+	if result.Success {
+		t.Error("Group start should report failure")
+	}
+
+	// The remaining members are still started
+	if indexOf(adapter.callLog(), "start RabbitMQ") < 0 {
+		t.Errorf("Expected RabbitMQ to be started after Redis failed, got %v", adapter.callLog())
+	}
+
+	for _, member := range result.Results {
+		switch member.Service {
+		case "Redis":
+			if member.Success || member.Code == nil || *member.Code != ErrOperationTimeout {
+				t.Errorf("Expected Redis to fail with ErrOperationTimeout, got %+v", member)
+			}
+		default:
+			if !member.Success {
+				t.Errorf("Expected %s to succeed, got %+v", member.Service, member)
+			}
+		}
+	}
+}
+
+func TestRestartGroup(t *testing.T) {
+	sm, adapter := createTestGroupManager(t, ServiceGroup{
+		Name:     "billing-stack",
+		Services: []string{"postgresql-x64-16", "Redis"},
+	})
+	adapter.setStatus("postgresql-x64-16", StatusRunning)
+	adapter.setStatus("Redis", StatusRunning)
+
+	result, err := sm.RestartGroup("billing-stack")
+	if err != nil {
+		t.Fatalf("RestartGroup() failed: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Expected restart to succeed, got %+v", result)
+	}
+
+	expectedCalls := []string{"stop Redis", "stop postgresql-x64-16", "start postgresql-x64-16", "start Redis"}
+	if calls := adapter.callLog(); strings.Join(calls, ",") != strings.Join(expectedCalls, ",") {
+		t.Errorf("Expected calls %v, got %v", expectedCalls, calls)
+	}
+}
+
+func TestGroupNotFound(t *testing.T) {
+	sm, _ := createTestGroupManager(t, ServiceGroup{Name: "billing-stack", Services: []string{"Redis"}})
+
+	_, err := sm.StartGroup("missing-stack")
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Code != ErrServiceNotFound {
+		t.Errorf("Expected ErrServiceNotFound, got %v", err)
+	}
+}
+
+func TestValidateServiceGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		groups  []ServiceGroup
+		wantErr bool
+	}{
+		{"services", []ServiceGroup{{Name: "a", Services: []string{"Redis"}}}, false},
+		{"tiers", []ServiceGroup{{Name: "a", Tiers: [][]string{{"MySQL"}, {"Redis", "RabbitMQ"}}}}, false},
+		{"missing name", []ServiceGroup{{Services: []string{"Redis"}}}, true},
+		{"duplicate name", []ServiceGroup{{Name: "a", Services: []string{"Redis"}}, {Name: "a", Services: []string{"MySQL"}}}, true},
+		{"no members", []ServiceGroup{{Name: "a"}}, true},
+		{"services and tiers", []ServiceGroup{{Name: "a", Services: []string{"Redis"}, Tiers: [][]string{{"MySQL"}}}}, true},
+		{"empty tier", []ServiceGroup{{Name: "a", Tiers: [][]string{{"MySQL"}, {}}}}, true},
+		{"duplicate member", []ServiceGroup{{Name: "a", Tiers: [][]string{{"Redis"}, {"Redis"}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateServiceGroups(tt.groups)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateServiceGroups() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigManagerServiceGroups(t *testing.T) {
+	cm, _ := createTestConfigManager(t)
+
+	group := ServiceGroup{Name: "billing-stack", Services: []string{"postgresql-x64-16", "Redis"}}
+	if err := cm.SetServiceGroup(group); err != nil {
+		t.Fatalf("SetServiceGroup() failed: %v", err)
+	}
+
+	// Invalid groups are rejected and leave the configuration untouched
+	if err := cm.SetServiceGroup(ServiceGroup{Name: "broken"}); err == nil {
+		t.Error("Expected an error for a group without services")
+	}
+
+	// Reload from disk
+	reloaded := &ConfigManager{configPath: cm.configPath}
+	if err := reloaded.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig() failed: %v", err)
+	}
+	groups := reloaded.GetServiceGroups()
+	if len(groups) != 1 || fmt.Sprint(groups[0].Services) != fmt.Sprint(group.Services) {
+		t.Fatalf("Expected persisted group %+v, got %+v", group, groups)
+	}
+
+	// Returned groups are copies
+	groups[0].Services[0] = "changed"
+	if stored, _ := reloaded.GetServiceGroup("billing-stack"); stored.Services[0] != "postgresql-x64-16" {
+		t.Error("Modifying returned groups should not change the configuration")
+	}
+
+	if err := reloaded.DeleteServiceGroup("billing-stack"); err != nil {
+		t.Fatalf("DeleteServiceGroup() failed: %v", err)
+	}
+	if len(reloaded.GetServiceGroups()) != 0 {
+		t.Error("Expected no groups after delete")
+	}
+	if err := reloaded.DeleteServiceGroup("billing-stack"); err == nil {
+		t.Error("Expected an error deleting a missing group")
+	}
+}
@@ -2,7 +2,16 @@ package app
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // ServiceManager orchestrates service operations
@@ -11,31 +20,100 @@ type ServiceManager struct {
 	detector         ServiceDetector
 	adapter          OSServiceAdapter
 	cache            *ServiceCache
+	statusWatcher    *StatusWatcher
+	metrics          *ServiceMetrics
+	auditLog         *AuditLog
+	ports            *portScanner
+	operations       *operationTracker
+	watchdog         *crashWatchdog
+	idleMonitor      *idleMonitor
+	proxies          *activationProxies
+	scheduler        *scheduler
+	terminateProcess func(pid uint32) error // Kills a process during a force stop
 	configManager    *ConfigManager
 	privilegeManager *PrivilegeManager
 	elevationChecked bool
+	statusListeners  map[int]func(ServiceStatusEvent)
+	nextListenerID   int
+	listenersMu      sync.Mutex
+	ctxMu            sync.RWMutex // Guards ctx, which is set and cleared by the Wails lifecycle
 }
 
 // NewServiceManager creates a new ServiceManager instance with dependency injection
 func NewServiceManager(configManager *ConfigManager) *ServiceManager {
 	// Initialize dependencies
-	adapter := NewWindowsServiceAdapter()
+	adapter := newServiceAdapter()
 	detector := NewWindowsServiceDetector(adapter)
 	cache := NewServiceCache(60 * time.Second) // Increased to 60 seconds to reduce memory churn
 	privilegeManager := NewPrivilegeManager()
 
-	return &ServiceManager{
+	sm := &ServiceManager{
 		detector:         detector,
 		adapter:          adapter,
 		cache:            cache,
 		configManager:    configManager,
 		privilegeManager: privilegeManager,
+		metrics:          NewServiceMetrics(),
+		ports:            detector.ports,
+		operations:       newOperationTracker(),
+		terminateProcess: terminateProcess,
+	}
+	sm.statusWatcher = NewStatusWatcher(adapter, sm.handleStatusChange)
+	sm.watchdog = newCrashWatchdog(sm)
+	sm.idleMonitor = newIdleMonitor(sm)
+	sm.proxies = newActivationProxies(sm)
+	sm.scheduler = newScheduler(sm)
+
+	// Keep the audit log next to config.json
+	if configManager != nil {
+		sm.auditLog = NewAuditLog(filepath.Dir(configManager.GetConfigPath()))
+	}
+
+	// An invalid rule file must not prevent startup; the built-in patterns still apply
+	if err := sm.ReloadDetectionRules(); err != nil {
+		log.Printf("Warning: %v", err)
 	}
+
+	return sm
+}
+
+// ReloadDetectionRules re-reads the user-defined detection rules and clears the service cache
+// so the next listing uses them. If the file is invalid the previous rules stay in effect.
+func (sm *ServiceManager) ReloadDetectionRules() error {
+	detector, ok := sm.detector.(*WindowsServiceDetector)
+	if !ok || sm.configManager == nil {
+		return nil
+	}
+
+	rules, err := LoadDetectionRules(sm.configManager.GetDetectionRulesPath())
+	if err != nil {
+		return err
+	}
+	if err := detector.SetDetectionRules(rules); err != nil {
+		return err
+	}
+
+	sm.cache.Clear()
+	return nil
+}
+
+// newServiceAdapter combines the native service backend with containers when Docker is configured
+func newServiceAdapter() OSServiceAdapter {
+	native := ServiceBackend{Name: BackendNative, Adapter: newPlatformServiceAdapter()}
+
+	var secondary []ServiceBackend
+	if dockerAdapter, err := NewDockerAdapter(""); err == nil {
+		secondary = append(secondary, ServiceBackend{Name: BackendDocker, Adapter: dockerAdapter})
+	}
+
+	return NewCompositeServiceAdapter(native, secondary...)
 }
 
 // OnStartup is called when the app starts
 func (sm *ServiceManager) OnStartup(ctx context.Context) {
+	sm.ctxMu.Lock()
 	sm.ctx = ctx
+	sm.ctxMu.Unlock()
 
 	// Check elevation status once at startup
 	sm.checkElevationStatus()
@@ -45,6 +123,105 @@ func (sm *ServiceManager) OnStartup(ctx context.Context) {
 
 	// Start periodic cache cleanup to prevent memory leaks
 	go sm.startCacheCleanup(ctx)
+
+	// Watch for status changes made outside the app
+	go sm.statusWatcher.Run(ctx)
+
+	// Stop idle-stop services nobody is connected to
+	go sm.idleMonitor.Run(ctx)
+
+	// Listen on the public ports of services started on demand
+	sm.proxies.Start(ctx)
+
+	// Run schedule rules for as long as the app runs
+	go sm.scheduler.Run(ctx)
+}
+
+// baseContext returns the application context that operations run in, or a background
+// context before startup
+func (sm *ServiceManager) baseContext() context.Context {
+	sm.ctxMu.RLock()
+	defer sm.ctxMu.RUnlock()
+	if sm.ctx == nil {
+		return context.Background()
+	}
+	return sm.ctx
+}
+
+// emitEvent sends an event to the frontend while the app is running
+func (sm *ServiceManager) emitEvent(name string, data interface{}) {
+	sm.ctxMu.RLock()
+	ctx := sm.ctx
+	sm.ctxMu.RUnlock()
+	if ctx != nil {
+		runtime.EventsEmit(ctx, name, data)
+	}
+}
+
+// handleStatusChange updates the cached service in place and notifies the frontend
+func (sm *ServiceManager) handleStatusChange(event ServiceStatusEvent) {
+	sm.cache.Update(event.Name, func(service *Service) {
+		service.Status = event.NewStatus
+		// The process is gone or about to be; the next refresh finds the new one
+		if !event.NewStatus.hasProcess() {
+			service.PID = 0
+			service.Ports = nil
+		}
+	})
+	sm.metrics.ObserveStatusChange(event)
+
+	sm.emitEvent(ServiceStatusEventName, event)
+
+	// Restart keep-alive services that crashed
+	if sm.watchdog != nil {
+		sm.watchdog.observe(event)
+	}
+	// Give started services their grace period before they can be stopped for being idle
+	if sm.idleMonitor != nil {
+		sm.idleMonitor.observe(event)
+	}
+
+	sm.listenersMu.Lock()
+	listeners := make([]func(ServiceStatusEvent), 0, len(sm.statusListeners))
+	for _, listener := range sm.statusListeners {
+		listeners = append(listeners, listener)
+	}
+	sm.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
+
+// addStatusListener registers a callback for service status changes and returns a function that removes it
+func (sm *ServiceManager) addStatusListener(listener func(ServiceStatusEvent)) func() {
+	sm.listenersMu.Lock()
+	defer sm.listenersMu.Unlock()
+
+	if sm.statusListeners == nil {
+		sm.statusListeners = make(map[int]func(ServiceStatusEvent))
+	}
+	id := sm.nextListenerID
+	sm.nextListenerID++
+	sm.statusListeners[id] = listener
+
+	return func() {
+		sm.listenersMu.Lock()
+		defer sm.listenersMu.Unlock()
+		delete(sm.statusListeners, id)
+	}
+}
+
+// refreshStartupType updates the cached startup type after it was changed
+func (sm *ServiceManager) refreshStartupType(name string) {
+	startupType, err := sm.adapter.GetStartupType(name)
+	if err != nil {
+		return
+	}
+
+	sm.cache.Update(name, func(service *Service) {
+		service.StartupType = startupType
+	})
 }
 
 // startCacheCleanup runs periodic cache cleanup to optimize memory usage
@@ -94,7 +271,9 @@ func (sm *ServiceManager) OnShutdown(ctx context.Context) {
 	}
 
 	// Clear context reference
+	sm.ctxMu.Lock()
 	sm.ctx = nil
+	sm.ctxMu.Unlock()
 }
 
 // IsServiceControlEnabled returns whether service control functionality is enabled
@@ -157,12 +336,25 @@ func (sm *ServiceManager) GetPrivilegeInfo() map[string]interface{} {
 	return map[string]interface{}{
 		"isElevated":         sm.IsElevated(),
 		"statusMessage":      sm.GetElevationStatus(),
-		"canControlServices": sm.IsElevated() && sm.IsServiceControlEnabled(),
+		"canControlServices": (sm.IsElevated() || !sm.privilegeManager.elevationRequired()) && sm.IsServiceControlEnabled(),
+	}
+}
+
+// GetHistory returns audited control operations matching the filter, most recent first
+func (sm *ServiceManager) GetHistory(filter AuditFilter) ([]AuditEntry, error) {
+	if sm.auditLog == nil {
+		return []AuditEntry{}, nil
 	}
+	return sm.auditLog.GetHistory(filter)
 }
 
-// SetServiceControlState sets the service control state
+// SetServiceControlState sets the service control state on behalf of the GUI
 func (sm *ServiceManager) SetServiceControlState(enabled bool) error {
+	return sm.control(InitiatorGUI).SetServiceControlState(enabled)
+}
+
+// setServiceControlState persists the service control state
+func (sm *ServiceManager) setServiceControlState(enabled bool) error {
 	if sm.configManager == nil {
 		return &ServiceError{
 			Code:    ErrInvalidState,
@@ -191,7 +383,8 @@ func (sm *ServiceManager) RequireElevationForOperation() error {
 		}
 	}
 
-	if !sm.privilegeManager.IsElevated() {
+	// Without a requirement, the backends reject what the user may not do, e.g. through polkit
+	if sm.privilegeManager.elevationRequired() && !sm.privilegeManager.IsElevated() {
 		return &ServiceError{
 			Code:    ErrPermissionDenied,
 			Message: "Administrator privileges are required for service operations. Please restart the application as administrator.",
@@ -221,8 +414,8 @@ func (sm *ServiceManager) GetServices() ([]Service, error) {
 	// If it fails due to permissions, we'll return a helpful error
 	services, err := sm.detector.DetectServices()
 	if err != nil {
-		// Check if this is a permission error
-		if serviceErr, ok := err.(*ServiceError); ok && serviceErr.Code == ErrPermissionDenied {
+		// Check if this is a permission error that elevation would solve
+		if serviceErr, ok := err.(*ServiceError); ok && serviceErr.Code == ErrPermissionDenied && sm.privilegeManager.elevationRequired() {
 			// Return a more helpful error message
 			return nil, &ServiceError{
 				Code:    ErrPermissionDenied,
@@ -232,15 +425,52 @@ func (sm *ServiceManager) GetServices() ([]Service, error) {
 		return nil, err
 	}
 
-	// Update cache with fresh results
+	// Update cache with fresh results and report changes since the last refresh
 	sm.cache.SetAll(services)
+	sm.statusWatcher.SetServices(services)
 
 	// Return services to frontend
 	return services, nil
 }
 
-// StartService starts a database service
-func (sm *ServiceManager) StartService(name string) error {
+// writeMetrics writes operation metrics and the state of all detected services in the Prometheus text format
+func (sm *ServiceManager) writeMetrics(w io.Writer) error {
+	// Operation counters are still useful while service listing is unavailable
+	services, err := sm.GetServices()
+	if err != nil {
+		services = nil
+	}
+	return sm.metrics.WriteTo(w, services)
+}
+
+// GetService returns a single detected database service
+func (sm *ServiceManager) GetService(name string) (*Service, error) {
+	services, err := sm.GetServices()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range services {
+		if services[i].Name == name {
+			return &services[i], nil
+		}
+	}
+
+	return nil, &ServiceError{
+		Code:    ErrServiceNotFound,
+		Message: fmt.Sprintf("Service not found: %s", name),
+		Service: name,
+	}
+}
+
+// StartService starts a database service on behalf of the GUI. It returns the ID of the
+// operation without waiting for it; WaitForOperation reports its outcome.
+func (sm *ServiceManager) StartService(name string) string {
+	return sm.control(InitiatorGUI).launch("start", name, sm.startService)
+}
+
+// startService performs the start operation
+func (sm *ServiceManager) startService(ctx context.Context, name string) error {
 	// Check elevation before attempting service operations
 	if err := sm.RequireElevationForOperation(); err != nil {
 		return err
@@ -269,20 +499,58 @@ func (sm *ServiceManager) StartService(name string) error {
 		}
 	}
 
+	if status.hasProcess() {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Service is %s, continue it instead", status),
+			Service: name,
+		}
+	}
+
 	// Call adapter to start the service
-	err = sm.adapter.StartService(name)
+	err = sm.withOperationTimeout(ctx, name, func(ctx context.Context) error {
+		return sm.adapter.StartService(ctx, name)
+	})
+	if err != nil {
+		return sm.explainStartFailure(name, err)
+	}
+
+	// Update the cached status in place and notify the frontend
+	sm.statusWatcher.Refresh(name)
+
+	// Only report success once the service accepts connections
+	return sm.explainStartFailure(name, sm.waitForReadiness(ctx, name))
+}
+
+// StopService stops a database service on behalf of the GUI. It returns the ID of the
+// operation without waiting for it; WaitForOperation reports its outcome.
+func (sm *ServiceManager) StopService(name string) string {
+	control := sm.control(InitiatorGUI)
+	return control.launch("stop", name, control.stopWithDependents)
+}
+
+// stopService performs the stop operation
+func (sm *ServiceManager) stopService(ctx context.Context, name string) error {
+	if err := sm.validateStop(name); err != nil {
+		return err
+	}
+
+	// Call adapter to stop the service
+	err := sm.withOperationTimeout(ctx, name, func(ctx context.Context) error {
+		return sm.adapter.StopService(ctx, name)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Invalidate cache after successful operation
-	sm.cache.Clear()
+	// Update the cached status in place and notify the frontend
+	sm.statusWatcher.Refresh(name)
 
 	return nil
 }
 
-// StopService stops a database service
-func (sm *ServiceManager) StopService(name string) error {
+// validateStop checks that a service may be stopped
+func (sm *ServiceManager) validateStop(name string) error {
 	// Check elevation before attempting service operations
 	if err := sm.RequireElevationForOperation(); err != nil {
 		return err
@@ -311,37 +579,235 @@ func (sm *ServiceManager) StopService(name string) error {
 		}
 	}
 
-	// Call adapter to stop the service
-	err = sm.adapter.StopService(name)
+	return nil
+}
+
+// RestartService restarts a database service on behalf of the GUI. It returns the ID of the
+// operation without waiting for it; WaitForOperation reports its outcome.
+func (sm *ServiceManager) RestartService(name string) string {
+	control := sm.control(InitiatorGUI)
+	return control.launch("restart", name, control.restartWithDependents)
+}
+
+// restartService performs the restart operation
+func (sm *ServiceManager) restartService(ctx context.Context, name string) error {
+	// Check elevation before attempting service operations
+	if err := sm.RequireElevationForOperation(); err != nil {
+		return err
+	}
+
+	// Call adapter to restart the service
+	err := sm.withOperationTimeout(ctx, name, func(ctx context.Context) error {
+		return sm.adapter.RestartService(ctx, name)
+	})
 	if err != nil {
+		return sm.explainStartFailure(name, err)
+	}
+
+	// Update the cached status in place and notify the frontend
+	sm.statusWatcher.Refresh(name)
+
+	// Only report success once the service accepts connections
+	return sm.explainStartFailure(name, sm.waitForReadiness(ctx, name))
+}
+
+// PauseService pauses a database service on behalf of the GUI. It returns the ID of the
+// operation without waiting for it; WaitForOperation reports its outcome.
+func (sm *ServiceManager) PauseService(name string) string {
+	return sm.control(InitiatorGUI).launch("pause", name, sm.pauseService)
+}
+
+// pauseService performs the pause operation
+func (sm *ServiceManager) pauseService(ctx context.Context, name string) error {
+	if err := sm.validatePauseOrContinue(name, "pause", StatusRunning); err != nil {
 		return err
 	}
 
-	// Invalidate cache after successful operation
-	sm.cache.Clear()
+	err := sm.withOperationTimeout(ctx, name, func(ctx context.Context) error {
+		return sm.adapter.PauseService(ctx, name)
+	})
+	if err != nil {
+		return err
+	}
 
+	sm.statusWatcher.Refresh(name)
 	return nil
 }
 
-// RestartService restarts a database service
-func (sm *ServiceManager) RestartService(name string) error {
-	// Check elevation before attempting service operations
+// ContinueService resumes a paused database service on behalf of the GUI. It returns the ID
+// of the operation without waiting for it; WaitForOperation reports its outcome.
+func (sm *ServiceManager) ContinueService(name string) string {
+	return sm.control(InitiatorGUI).launch("continue", name, sm.continueService)
+}
+
+// continueService performs the continue operation
+func (sm *ServiceManager) continueService(ctx context.Context, name string) error {
+	if err := sm.validatePauseOrContinue(name, "continue", StatusPaused); err != nil {
+		return err
+	}
+
+	err := sm.withOperationTimeout(ctx, name, func(ctx context.Context) error {
+		return sm.adapter.ContinueService(ctx, name)
+	})
+	if err != nil {
+		return err
+	}
+
+	sm.statusWatcher.Refresh(name)
+
+	// Only report success once the service accepts connections again
+	return sm.waitForReadiness(ctx, name)
+}
+
+// validatePauseOrContinue checks that a service is in the status an operation requires.
+// Whether the service accepts pause and continue requests at all is checked by the adapter,
+// as Windows services only announce it while they are running.
+func (sm *ServiceManager) validatePauseOrContinue(name, action string, required ServiceStatus) error {
 	if err := sm.RequireElevationForOperation(); err != nil {
 		return err
 	}
 
-	// Call adapter to restart the service
-	err := sm.adapter.RestartService(name)
+	status, err := sm.adapter.GetServiceStatus(name)
 	if err != nil {
 		return err
 	}
 
-	// Invalidate cache after successful operation
-	sm.cache.Clear()
+	if status != required {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Service is %s, cannot %s it", status, action),
+			Service: name,
+		}
+	}
 
 	return nil
 }
 
+// waitForReadiness waits until a started service passes the readiness probe configured for its type
+func (sm *ServiceManager) waitForReadiness(ctx context.Context, name string) error {
+	if sm.configManager == nil {
+		return nil
+	}
+
+	service, found := sm.findService(name)
+	if !found {
+		return nil
+	}
+
+	readiness, enabled := sm.configManager.GetReadinessConfig(service.Type)
+	if !enabled {
+		return nil
+	}
+
+	// Behind an activation proxy the public address is ShutDB's own listener
+	address := ""
+	if proxy, proxied := sm.configManager.GetActivationProxy(name); proxied {
+		address = proxy.BackendAddress
+	} else {
+		// Containers are reached through the host port their standard port is published on
+		port := service.ExpectedPort
+		if port == 0 {
+			port = service.PublishedPort
+		}
+
+		var err error
+		if address, err = readiness.probeAddress(port); err != nil {
+			return &ServiceError{
+				Code:    ErrSystemError,
+				Message: fmt.Sprintf("Invalid readiness probe: %v", err),
+				Service: name,
+			}
+		}
+	}
+	if address == "" {
+		log.Printf("Skipping the readiness probe of %s: the port it listens on is unknown", name)
+		return nil
+	}
+
+	return waitUntilReady(ctx, name, service.Type, address, readiness.timeout())
+}
+
+// CheckPortConflicts reports the other processes that hold the TCP port a service listens on.
+// Run before a start, it explains why the service would fail to bind its port.
+func (sm *ServiceManager) CheckPortConflicts(name string) ([]PortConflict, error) {
+	service, err := sm.GetService(name)
+	if err != nil {
+		return nil, err
+	}
+
+	port := sm.expectedPort(service)
+	if port == 0 || sm.ports == nil {
+		return []PortConflict{}, nil
+	}
+
+	snapshot, err := sm.ports.snapshot()
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to check port %d: %v", port, err),
+			Service: name,
+		}
+	}
+	return snapshot.conflicts(port, service.PID), nil
+}
+
+// expectedPort returns the port a service should listen on: the port of its activation proxy's
+// backend if it has one, otherwise the port detected from its command line or type
+func (sm *ServiceManager) expectedPort(service *Service) int {
+	if sm.configManager != nil {
+		// A proxied service listens on its backend address; the public port is held by ShutDB
+		if proxy, proxied := sm.configManager.GetActivationProxy(service.Name); proxied {
+			if _, portValue, err := net.SplitHostPort(proxy.BackendAddress); err == nil {
+				if port, err := strconv.Atoi(portValue); err == nil {
+					return port
+				}
+			}
+		}
+	}
+	return service.ExpectedPort
+}
+
+// explainStartFailure adds the processes holding a service's port to the error of a failed start
+func (sm *ServiceManager) explainStartFailure(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	conflicts, checkErr := sm.CheckPortConflicts(name)
+	if checkErr != nil || len(conflicts) == 0 {
+		return err
+	}
+
+	return withErrorDetail(err, name, describePortConflicts(conflicts))
+}
+
+// findService returns a detected service, detecting services if it is not cached
+func (sm *ServiceManager) findService(name string) (*Service, bool) {
+	if service, exists := sm.cache.Get(name); exists {
+		return service, true
+	}
+
+	services, err := sm.detector.DetectServices()
+	if err != nil {
+		return nil, false
+	}
+	for i := range services {
+		if services[i].Name == name {
+			return &services[i], true
+		}
+	}
+	return nil, false
+}
+
+// serviceType returns the detected type of a service
+func (sm *ServiceManager) serviceType(name string) (ServiceType, bool) {
+	service, found := sm.findService(name)
+	if !found {
+		return "", false
+	}
+	return service.Type, true
+}
+
 // GetServiceStatus returns the current status of a service
 func (sm *ServiceManager) GetServiceStatus(name string) (string, error) {
 	// Check if service control is enabled
@@ -359,50 +825,123 @@ func (sm *ServiceManager) GetServiceStatus(name string) (string, error) {
 		return string(StatusStopped), err
 	}
 
-	// Update cache with latest status
-	if cachedService, exists := sm.cache.Get(name); exists {
-		cachedService.Status = status
-		sm.cache.Set(name, cachedService)
-	}
+	// Update cache with latest status, notifying the frontend if it changed
+	sm.statusWatcher.Observe(name, status)
 
 	// Return status to frontend
 	return string(status), nil
 }
 
-// EnableService enables a database service (sets startup type to manual)
+// EnableService enables a disabled database service on behalf of the GUI, restoring the
+// startup type it had before it was disabled
 func (sm *ServiceManager) EnableService(name string) error {
+	return sm.control(InitiatorGUI).EnableService(name)
+}
+
+// enableService performs the enable operation. Services disabled outside ShutDB become manual,
+// and services that are not disabled keep their startup type.
+func (sm *ServiceManager) enableService(name string) error {
 	// Check elevation before attempting service operations
 	if err := sm.RequireElevationForOperation(); err != nil {
 		return err
 	}
 
-	// Call adapter to enable the service
-	err := sm.adapter.EnableService(name)
+	current, err := sm.adapter.GetStartupType(name)
 	if err != nil {
 		return err
 	}
+	if current != StartupDisabled {
+		return nil
+	}
 
-	// Invalidate cache after successful operation
-	sm.cache.Clear()
+	startupType := StartupManual
+	if sm.configManager != nil {
+		if previous, exists := sm.configManager.GetPreviousStartupType(name); exists {
+			startupType = previous
+		}
+	}
 
-	return nil
+	return sm.setStartupType(name, startupType)
 }
 
-// DisableService disables a database service (sets startup type to disabled)
+// DisableService disables a database service (sets startup type to disabled) on behalf of the GUI
 func (sm *ServiceManager) DisableService(name string) error {
+	return sm.control(InitiatorGUI).DisableService(name)
+}
+
+// disableService performs the disable operation
+func (sm *ServiceManager) disableService(name string) error {
+	return sm.setStartupType(name, StartupDisabled)
+}
+
+// SetStartupType changes the startup type of a database service on behalf of the GUI
+func (sm *ServiceManager) SetStartupType(name string, startupType StartupType) error {
+	return sm.control(InitiatorGUI).SetStartupType(name, startupType)
+}
+
+// setStartupType performs the startup type change. Disabling a service remembers its
+// previous startup type so that enabling it restores it.
+func (sm *ServiceManager) setStartupType(name string, startupType StartupType) error {
 	// Check elevation before attempting service operations
 	if err := sm.RequireElevationForOperation(); err != nil {
 		return err
 	}
 
-	// Call adapter to disable the service
-	err := sm.adapter.DisableService(name)
-	if err != nil {
+	if !startupType.settable() {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Unknown startup type %q, expected automatic, automatic_delayed, manual or disabled", startupType),
+			Service: name,
+		}
+	}
+	if service, found := sm.findService(name); found && !service.supportsStartupType(startupType) {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("Startup type %s is not supported by %s", startupType, name),
+			Service: name,
+		}
+	}
+
+	// Read the type to restore before disabling replaces it
+	var previous StartupType
+	if startupType == StartupDisabled {
+		current, err := sm.adapter.GetStartupType(name)
+		if err != nil {
+			return err
+		}
+		previous = current
+	}
+
+	// Call adapter to change the startup type
+	if err := sm.adapter.SetStartupType(name, startupType); err != nil {
 		return err
 	}
 
-	// Invalidate cache after successful operation
-	sm.cache.Clear()
+	if startupType == StartupDisabled {
+		sm.rememberStartupType(name, previous)
+	} else if sm.configManager != nil {
+		// A startup type chosen explicitly replaces the remembered one
+		if err := sm.configManager.DeletePreviousStartupType(name); err != nil {
+			log.Printf("Failed to forget the previous startup type of %s: %v", name, err)
+		}
+	}
+
+	// Update the cached startup type in place
+	sm.refreshStartupType(name)
 
 	return nil
 }
+
+// rememberStartupType stores the startup type a service had before it was disabled. A service
+// that was already disabled keeps the type remembered earlier, and boot and system startup
+// are not remembered as they cannot be restored.
+func (sm *ServiceManager) rememberStartupType(name string, previous StartupType) {
+	if sm.configManager == nil || previous == StartupDisabled || !previous.settable() {
+		return
+	}
+
+	// The service is disabled by now, so a failure only means enabling it falls back to manual
+	if err := sm.configManager.SetPreviousStartupType(name, previous); err != nil {
+		log.Printf("Failed to remember the previous startup type of %s: %v", name, err)
+	}
+}
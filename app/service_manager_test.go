@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// createTestServiceManager wires a ServiceManager to a fake adapter and a temporary config,
+// running with administrator privileges
+func createTestServiceManager(t *testing.T, adapter *fakeServiceAdapter) *ServiceManager {
+	configManager, _ := createTestConfigManager(t)
+
+	// Tests never inspect the sockets of the machine they run on
+	detector := NewWindowsServiceDetector(adapter)
+	detector.ports = newFakePortScanner(nil, nil)
+
+	sm := &ServiceManager{
+		detector:         detector,
+		adapter:          adapter,
+		cache:            NewServiceCache(60 * time.Second),
+		configManager:    configManager,
+		privilegeManager: &PrivilegeManager{isElevated: true, checked: true},
+		metrics:          NewServiceMetrics(),
+		auditLog:         NewAuditLog(filepath.Dir(configManager.GetConfigPath())),
+		ports:            detector.ports,
+		operations:       newOperationTracker(),
+		terminateProcess: func(pid uint32) error {
+			// Tests never kill processes of the machine they run on
+			return fmt.Errorf("unexpected termination of process %d", pid)
+		},
+	}
+	sm.statusWatcher = NewStatusWatcher(adapter, sm.handleStatusChange)
+	sm.watchdog = newCrashWatchdog(sm)
+	sm.idleMonitor = newIdleMonitor(sm)
+	sm.proxies = newActivationProxies(sm)
+	sm.scheduler = newScheduler(sm)
+	return sm
+}
+
+func TestServiceManagerOperationsRequireElevation(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	sm := createTestServiceManager(t, adapter)
+	sm.privilegeManager = &PrivilegeManager{isElevated: false, checked: true}
+
+	err := sm.WaitForOperation(sm.StartService("Redis"))
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Code != ErrPermissionDenied {
+		t.Fatalf("Expected ErrPermissionDenied, got %v", err)
+	}
+	if calls := adapter.callLog(); len(calls) != 0 {
+		t.Errorf("Adapter should not be called without elevation, got %v", calls)
+	}
+}
+
+func TestServiceManagerOperationsWithoutElevationRequirement(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "redis-server", Status: StatusStopped})
+	adapter.failOn["stop redis-server"] = &ServiceError{Code: ErrPermissionDenied, Message: "Permission denied while trying to stop", Service: "redis-server"}
+	sm := createTestServiceManager(t, adapter)
+	// As on Linux, where polkit decides per operation
+	sm.privilegeManager = &PrivilegeManager{isElevated: false, checked: true, elevationOptional: true}
+
+	if err := sm.WaitForOperation(sm.StartService("redis-server")); err != nil {
+		t.Fatalf("Expected the start to reach the adapter, got %v", err)
+	}
+
+	err := sm.WaitForOperation(sm.StopService("redis-server"))
+	serviceErr, ok := err.(*ServiceError)
+	if !ok || serviceErr.Code != ErrPermissionDenied {
+		t.Fatalf("Expected the adapter's ErrPermissionDenied, got %v", err)
+	}
+	if strings.Contains(serviceErr.Message, "administrator") {
+		t.Errorf("Expected no advice to run as administrator, got %q", serviceErr.Message)
+	}
+}
+
+func TestServiceManagerStateValidation(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "MSSQLSERVER", Status: StatusRunning},
+		OSService{Name: "Redis", Status: StatusStopped},
+	)
+	sm := createTestServiceManager(t, adapter)
+
+	tests := []struct {
+		name     string
+		op       func() error
+		expected ErrorCode
+	}{
+		{"start running service", func() error { return sm.WaitForOperation(sm.StartService("MSSQLSERVER")) }, ErrInvalidState},
+		{"stop stopped service", func() error { return sm.WaitForOperation(sm.StopService("Redis")) }, ErrInvalidState},
+		{"start missing service", func() error { return sm.WaitForOperation(sm.StartService("missing")) }, ErrServiceNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceErr, ok := tt.op().(*ServiceError)
+			if !ok {
+				t.Fatal("Expected *ServiceError")
+			}
+			if serviceErr.Code != tt.expected {
+				t.Errorf("Expected error code %d, got %d", tt.expected, serviceErr.Code)
+			}
+		})
+	}
+}
+
+func TestServiceManagerPauseContinue(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "MSSQLSERVER", Status: StatusRunning, CanPause: true},
+		OSService{Name: "Redis", Status: StatusRunning},
+	)
+	sm := createTestServiceManager(t, adapter)
+
+	if err := sm.WaitForOperation(sm.PauseService("MSSQLSERVER")); err != nil {
+		t.Fatalf("PauseService() failed: %v", err)
+	}
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusPaused {
+		t.Fatalf("Expected MSSQLSERVER to be paused, got %s", status)
+	}
+
+	tests := []struct {
+		name string
+		op   func() error
+	}{
+		{"pause paused service", func() error { return sm.WaitForOperation(sm.PauseService("MSSQLSERVER")) }},
+		{"start paused service", func() error { return sm.WaitForOperation(sm.StartService("MSSQLSERVER")) }},
+		{"continue running service", func() error { return sm.WaitForOperation(sm.ContinueService("Redis")) }},
+		{"pause service without pause support", func() error { return sm.WaitForOperation(sm.PauseService("Redis")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serviceErr, ok := tt.op().(*ServiceError)
+			if !ok || serviceErr.Code != ErrInvalidState {
+				t.Errorf("Expected ErrInvalidState, got %v", serviceErr)
+			}
+		})
+	}
+
+	if err := sm.WaitForOperation(sm.ContinueService("MSSQLSERVER")); err != nil {
+		t.Fatalf("ContinueService() failed: %v", err)
+	}
+	if status, _ := adapter.GetServiceStatus("MSSQLSERVER"); status != StatusRunning {
+		t.Errorf("Expected MSSQLSERVER to be running, got %s", status)
+	}
+}
+
+func TestServiceManagerStartupType(t *testing.T) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "postgresql-x64-16", Status: StatusStopped},
+		OSService{Name: "Redis", Status: StatusStopped},
+		OSService{Name: "MongoDB", Status: StatusStopped, StartupTypes: dockerStartupTypes},
+	)
+	adapter.startup["postgresql-x64-16"] = StartupAutomaticDelayed
+	sm := createTestServiceManager(t, adapter)
+
+	// Disabling remembers the delayed start and enabling restores it
+	if err := sm.DisableService("postgresql-x64-16"); err != nil {
+		t.Fatalf("DisableService() failed: %v", err)
+	}
+	if previous, _ := sm.configManager.GetPreviousStartupType("postgresql-x64-16"); previous != StartupAutomaticDelayed {
+		t.Errorf("Expected automatic_delayed to be remembered, got %q", previous)
+	}
+
+	// Disabling again keeps the remembered type
+	if err := sm.DisableService("postgresql-x64-16"); err != nil {
+		t.Fatalf("DisableService() failed: %v", err)
+	}
+	if err := sm.EnableService("postgresql-x64-16"); err != nil {
+		t.Fatalf("EnableService() failed: %v", err)
+	}
+	if startupType := adapter.startup["postgresql-x64-16"]; startupType != StartupAutomaticDelayed {
+		t.Errorf("Expected automatic_delayed to be restored, got %s", startupType)
+	}
+	if _, exists := sm.configManager.GetPreviousStartupType("postgresql-x64-16"); exists {
+		t.Error("Expected the remembered startup type to be forgotten once restored")
+	}
+
+	// Enabling a service that is not disabled keeps its startup type
+	if err := sm.SetStartupType("Redis", StartupAutomatic); err != nil {
+		t.Fatalf("SetStartupType() failed: %v", err)
+	}
+	if err := sm.EnableService("Redis"); err != nil {
+		t.Fatalf("EnableService() failed: %v", err)
+	}
+	if startupType := adapter.startup["Redis"]; startupType != StartupAutomatic {
+		t.Errorf("Expected Redis to stay automatic, got %s", startupType)
+	}
+
+	// A service disabled outside ShutDB becomes manual
+	adapter.startup["Redis"] = StartupDisabled
+	if err := sm.EnableService("Redis"); err != nil {
+		t.Fatalf("EnableService() failed: %v", err)
+	}
+	if startupType := adapter.startup["Redis"]; startupType != StartupManual {
+		t.Errorf("Expected Redis to become manual, got %s", startupType)
+	}
+
+	// A rejected disable leaves nothing remembered
+	adapter.failOn["startup-type Redis"] = &ServiceError{Code: ErrSystemError, Message: "Restart policy rejected", Service: "Redis"}
+	if err := sm.DisableService("Redis"); err == nil {
+		t.Fatal("Expected DisableService() to fail")
+	}
+	if previous, exists := sm.configManager.GetPreviousStartupType("Redis"); exists {
+		t.Errorf("Expected no remembered startup type after a failed disable, got %q", previous)
+	}
+	delete(adapter.failOn, "startup-type Redis")
+
+	for _, startupType := range []StartupType{StartupBoot, "sometimes"} {
+		err := sm.SetStartupType("Redis", startupType)
+		if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrInvalidState {
+			t.Errorf("SetStartupType(%q): expected ErrInvalidState, got %v", startupType, err)
+		}
+	}
+
+	// Startup types the service's backend cannot set are rejected before reaching it
+	for _, startupType := range []StartupType{StartupDisabled, StartupAutomaticDelayed} {
+		err := sm.SetStartupType("MongoDB", startupType)
+		if serviceErr, ok := err.(*ServiceError); !ok || serviceErr.Code != ErrInvalidState {
+			t.Errorf("SetStartupType(%q): expected ErrInvalidState, got %v", startupType, err)
+		}
+	}
+	if calls := strings.Join(adapter.callLog(), ","); strings.Contains(calls, "startup-type MongoDB") {
+		t.Errorf("Expected no startup type change for MongoDB, got %s", calls)
+	}
+	if err := sm.SetStartupType("MongoDB", StartupAutomatic); err != nil {
+		t.Errorf("SetStartupType() failed for a supported startup type: %v", err)
+	}
+}
+
+func TestServiceManagerShutdownDuringOperations(t *testing.T) {
+	adapter := newFakeServiceAdapter(OSService{Name: "Redis", Status: StatusStopped})
+	sm := createTestServiceManager(t, adapter)
+
+	// Operations and status changes read the app context that shutdown clears
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 20; i++ {
+			sm.StartService("Redis")
+			sm.StopService("Redis")
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		sm.OnShutdown(context.Background())
+	}
+	<-done
+}
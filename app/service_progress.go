@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultWaitHint is assumed for services that report no wait hint while pending
+	defaultWaitHint = 30 * time.Second
+	// minProgressPollInterval and maxProgressPollInterval bound the time between status queries
+	minProgressPollInterval = 500 * time.Millisecond
+	maxProgressPollInterval = 10 * time.Second
+)
+
+// serviceProgress is the state of a service as reported while it changes state
+type serviceProgress struct {
+	Status     ServiceStatus
+	CheckPoint uint32        // Incremented by the service as it makes progress
+	WaitHint   time.Duration // Time the service expects to need until its next check point, 0 if unknown
+}
+
+// progressPollInterval returns how long to wait before querying a pending service again:
+// a tenth of its wait hint as recommended for the Windows SCM, but no less than half a
+// second and no more than ten seconds
+func progressPollInterval(waitHint time.Duration) time.Duration {
+	interval := waitHint / 10
+	if interval < minProgressPollInterval {
+		return minProgressPollInterval
+	}
+	if interval > maxProgressPollInterval {
+		return maxProgressPollInterval
+	}
+	return interval
+}
+
+// waitForProgress polls a service until it reaches the target status. It fails when the service
+// leaves the pending status for any other status, when its check point does not advance within
+// its wait hint, or when ctx ends. Every advance of the check point counts as progress and gives
+// the service another wait hint, so a slow but progressing service only runs into the deadline
+// of ctx. Cancelling only stops the wait; the transition itself carries on.
+func waitForProgress(ctx context.Context, name, action string, target, pending ServiceStatus, query func() (serviceProgress, error)) error {
+	var lastCheckPoint uint32
+	lastProgress := time.Now()
+
+	for {
+		progress, err := query()
+		if err != nil {
+			return err
+		}
+
+		switch progress.Status {
+		case target:
+			return nil
+		case pending:
+		default:
+			return &ServiceError{
+				Code:    ErrSystemError,
+				Message: fmt.Sprintf("Service failed to %s: it is %s", action, progress.Status),
+				Service: name,
+			}
+		}
+
+		waitHint := progress.WaitHint
+		if waitHint <= 0 {
+			waitHint = defaultWaitHint
+		}
+		if progress.CheckPoint != lastCheckPoint {
+			lastCheckPoint, lastProgress = progress.CheckPoint, time.Now()
+		} else if time.Since(lastProgress) > waitHint {
+			return &ServiceError{
+				Code:    ErrOperationTimeout,
+				Message: fmt.Sprintf("Service %s operation timed out: no progress past check point %d for %s", action, progress.CheckPoint, waitHint),
+				Service: name,
+			}
+		}
+
+		timer := time.NewTimer(progressPollInterval(waitHint))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return contextError(ctx, name)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProgressPollInterval(t *testing.T) {
+	tests := []struct {
+		waitHint time.Duration
+		expected time.Duration
+	}{
+		{0, minProgressPollInterval},
+		{2 * time.Second, minProgressPollInterval},
+		{20 * time.Second, 2 * time.Second},
+		{5 * time.Minute, maxProgressPollInterval},
+	}
+
+	for _, tt := range tests {
+		if interval := progressPollInterval(tt.waitHint); interval != tt.expected {
+			t.Errorf("progressPollInterval(%s) = %s, expected %s", tt.waitHint, interval, tt.expected)
+		}
+	}
+}
+
+// progressSequence returns a query that reports the given states one after another,
+// repeating the last one
+func progressSequence(states ...serviceProgress) func() (serviceProgress, error) {
+	i := 0
+	return func() (serviceProgress, error) {
+		state := states[i]
+		if i < len(states)-1 {
+			i++
+		}
+		return state, nil
+	}
+}
+
+func TestWaitForProgress(t *testing.T) {
+	fastHint := 10 * time.Millisecond
+	tests := []struct {
+		name     string
+		states   []serviceProgress
+		expected *ErrorCode
+	}{
+		{"already running", []serviceProgress{{Status: StatusRunning}}, nil},
+		{"advancing check point", []serviceProgress{
+			{Status: StatusStarting, CheckPoint: 1, WaitHint: fastHint},
+			{Status: StatusStarting, CheckPoint: 2, WaitHint: fastHint},
+			{Status: StatusRunning},
+		}, nil},
+		{"stopped while starting", []serviceProgress{
+			{Status: StatusStarting, CheckPoint: 1, WaitHint: fastHint},
+			{Status: StatusStopped},
+		}, codePtr(ErrSystemError)},
+		{"stalled check point", []serviceProgress{
+			{Status: StatusStarting, CheckPoint: 1, WaitHint: fastHint},
+		}, codePtr(ErrOperationTimeout)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Operations always run with a timeout; the check point is followed under it
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+			err := waitForProgress(ctx, "Redis", "start", StatusRunning, StatusStarting, progressSequence(tt.states...))
+			if tt.expected == nil {
+				if err != nil {
+					t.Errorf("Expected success, got %v", err)
+				}
+				return
+			}
+			var serviceErr *ServiceError
+			if !errors.As(err, &serviceErr) || serviceErr.Code != *tt.expected {
+				t.Errorf("Expected error code %v, got %v", *tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestWaitForProgressCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	query := progressSequence(serviceProgress{Status: StatusStopping, CheckPoint: 1, WaitHint: time.Minute})
+	err := waitForProgress(ctx, "Redis", "stop", StatusStopped, StatusStopping, query)
+
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrOperationCanceled {
+		t.Errorf("Expected ErrOperationCanceled, got %v", err)
+	}
+}
+
+func TestWaitForProgressOutlastsWaitHint(t *testing.T) {
+	// Each check point arrives within the wait hint, but all of them together take longer
+	hint := 600 * time.Millisecond
+	var states []serviceProgress
+	for checkPoint := uint32(1); checkPoint <= 4; checkPoint++ {
+		states = append(states, serviceProgress{Status: StatusStopping, CheckPoint: checkPoint, WaitHint: hint})
+	}
+	states = append(states, serviceProgress{Status: StatusStopped})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	started := time.Now()
+	if err := waitForProgress(ctx, "MSSQLSERVER", "stop", StatusStopped, StatusStopping, progressSequence(states...)); err != nil {
+		t.Fatalf("Expected a progressing service to be waited for, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed <= hint {
+		t.Errorf("Expected the wait to outlast a single wait hint, took %s", elapsed)
+	}
+
+	// The deadline still bounds a service that keeps reporting progress
+	checkPoint := uint32(0)
+	advancing := func() (serviceProgress, error) {
+		checkPoint++
+		return serviceProgress{Status: StatusStopping, CheckPoint: checkPoint, WaitHint: hint}, nil
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 1200*time.Millisecond)
+	defer cancel()
+	err := waitForProgress(ctx, "MSSQLSERVER", "stop", StatusStopped, StatusStopping, advancing)
+	var serviceErr *ServiceError
+	if !errors.As(err, &serviceErr) || serviceErr.Code != ErrOperationTimeout {
+		t.Errorf("Expected the deadline to end the wait, got %v", err)
+	}
+}
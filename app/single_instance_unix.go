@@ -0,0 +1,78 @@
+//go:build unix
+
+package app
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	// Lock file name used for ShutDB single instance enforcement
+	SHUTDB_LOCK_FILE = "shutdb.lock"
+)
+
+// SingleInstanceManager handles single instance enforcement
+type SingleInstanceManager struct {
+	lockFile *os.File
+	appTitle string
+}
+
+// NewSingleInstanceManager creates a new single instance manager
+func NewSingleInstanceManager(appTitle string) *SingleInstanceManager {
+	return &SingleInstanceManager{
+		appTitle: appTitle,
+	}
+}
+
+// lockPath returns the per-user path of the lock file
+func (sim *SingleInstanceManager) lockPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, SHUTDB_LOCK_FILE)
+}
+
+// TryAcquireLock attempts to acquire the singleton lock
+// Returns true if successful (first instance), false if another instance exists
+func (sim *SingleInstanceManager) TryAcquireLock() bool {
+	file, err := os.OpenFile(sim.lockPath(), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		log.Printf("Failed to open lock file: %v", err)
+		return false
+	}
+
+	// The kernel releases flock locks automatically if the process dies
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		log.Printf("Another instance of %s is already running", sim.appTitle)
+		return false
+	}
+
+	sim.lockFile = file
+	log.Printf("Successfully acquired singleton lock for %s", sim.appTitle)
+	return true
+}
+
+// ReleaseLock releases the singleton lock file
+func (sim *SingleInstanceManager) ReleaseLock() {
+	if sim.lockFile != nil {
+		syscall.Flock(int(sim.lockFile.Fd()), syscall.LOCK_UN)
+		sim.lockFile.Close()
+		sim.lockFile = nil
+		log.Printf("Released singleton lock")
+	}
+}
+
+// IsLocked returns true if the singleton lock is currently held
+func (sim *SingleInstanceManager) IsLocked() bool {
+	return sim.lockFile != nil
+}
+
+// GetMutexName returns the lock file path used for singleton enforcement
+func (sim *SingleInstanceManager) GetMutexName() string {
+	return sim.lockPath()
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package app
+
+// newStatusNotifier returns nil because only the Windows SCM notifies us of
+// status changes; other platforms rely on polling
+func newStatusNotifier(wake chan<- struct{}) statusNotifier {
+	return nil
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"sync"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	// scmNotifyCallback is shared by all subscriptions; Windows limits how many
+	// Go callbacks a process may create, so it is only created once
+	scmNotifyCallback = syscall.NewCallback(onSCMStatusChange)
+
+	scmNotifyMu      sync.Mutex
+	scmNotifyWakers  = make(map[uintptr]chan<- struct{})
+	scmNotifyNextKey uintptr
+)
+
+// onSCMStatusChange runs on a thread pool thread whenever a subscribed service changes status
+func onSCMStatusChange(notify uint32, context uintptr) uintptr {
+	scmNotifyMu.Lock()
+	wake := scmNotifyWakers[context]
+	scmNotifyMu.Unlock()
+
+	if wake != nil {
+		select {
+		case wake <- struct{}{}:
+		default:
+			// A poll is already pending
+		}
+	}
+	return 0
+}
+
+// scmSubscription is a status change subscription on an open service handle
+type scmSubscription struct {
+	service      windows.Handle
+	subscription uintptr
+}
+
+// scmStatusNotifier uses SubscribeServiceChangeNotifications to wake the watcher
+type scmStatusNotifier struct {
+	scm           windows.Handle
+	key           uintptr
+	subscriptions map[string]scmSubscription
+}
+
+// newStatusNotifier returns an SCM-backed notifier, or nil if notifications are unavailable
+func newStatusNotifier(wake chan<- struct{}) statusNotifier {
+	// SubscribeServiceChangeNotifications requires Windows 8 or later
+	if err := windows.NewLazySystemDLL("sechost.dll").NewProc("SubscribeServiceChangeNotifications").Find(); err != nil {
+		return nil
+	}
+
+	// Connect with minimal rights so notifications also work without elevation
+	scm, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
+	if err != nil {
+		return nil
+	}
+
+	scmNotifyMu.Lock()
+	scmNotifyNextKey++
+	key := scmNotifyNextKey
+	scmNotifyWakers[key] = wake
+	scmNotifyMu.Unlock()
+
+	return &scmStatusNotifier{
+		scm:           scm,
+		key:           key,
+		subscriptions: make(map[string]scmSubscription),
+	}
+}
+
+// Watch subscribes to the given services and drops subscriptions for services no longer watched
+func (n *scmStatusNotifier) Watch(names []string) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+		if _, exists := n.subscriptions[name]; exists {
+			continue
+		}
+
+		namePtr, err := windows.UTF16PtrFromString(name)
+		if err != nil {
+			continue
+		}
+
+		service, err := windows.OpenService(n.scm, namePtr, windows.SERVICE_QUERY_STATUS)
+		if err != nil {
+			// Services we can't open are still covered by polling
+			continue
+		}
+
+		var subscription uintptr
+		err = windows.SubscribeServiceChangeNotifications(service, windows.SC_EVENT_STATUS_CHANGE, scmNotifyCallback, n.key, &subscription)
+		if err != nil {
+			windows.CloseServiceHandle(service)
+			continue
+		}
+
+		n.subscriptions[name] = scmSubscription{service: service, subscription: subscription}
+	}
+
+	for name, sub := range n.subscriptions {
+		if !wanted[name] {
+			n.unsubscribe(sub)
+			delete(n.subscriptions, name)
+		}
+	}
+}
+
+// unsubscribe removes a subscription before closing its service handle
+func (n *scmStatusNotifier) unsubscribe(sub scmSubscription) {
+	windows.UnsubscribeServiceChangeNotifications(sub.subscription)
+	windows.CloseServiceHandle(sub.service)
+}
+
+// Close removes all subscriptions and disconnects from the SCM
+func (n *scmStatusNotifier) Close() {
+	for name, sub := range n.subscriptions {
+		n.unsubscribe(sub)
+		delete(n.subscriptions, name)
+	}
+
+	scmNotifyMu.Lock()
+	delete(scmNotifyWakers, n.key)
+	scmNotifyMu.Unlock()
+
+	windows.CloseServiceHandle(n.scm)
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// ServiceStatusEventName is the Wails event emitted when a service changes status
+	ServiceStatusEventName = "service:status"
+
+	// statusPollInterval is used when the OS cannot notify us of status changes
+	statusPollInterval = 5 * time.Second
+	// statusNotifiedPollInterval is a safety net when OS notifications are active
+	statusNotifiedPollInterval = 30 * time.Second
+)
+
+// ServiceStatusEvent describes a single service status transition
+type ServiceStatusEvent struct {
+	Name      string        `json:"Name"`
+	OldStatus ServiceStatus `json:"OldStatus"`
+	NewStatus ServiceStatus `json:"NewStatus"`
+}
+
+// statusNotifier subscribes to OS status change notifications for native services
+// and wakes the watcher whenever one of them changes
+type statusNotifier interface {
+	Watch(names []string)
+	Close()
+}
+
+// StatusWatcher tracks the status of detected services and reports transitions.
+// It re-checks services whenever the OS notifies it of a change, and falls back
+// to diffing periodic status queries.
+type StatusWatcher struct {
+	adapter  OSServiceAdapter
+	onChange func(ServiceStatusEvent)
+	wake     chan struct{}
+	notifier statusNotifier
+	known    map[string]ServiceStatus
+	native   map[string]bool
+	mu       sync.Mutex
+}
+
+// NewStatusWatcher creates a watcher that reports transitions through onChange
+func NewStatusWatcher(adapter OSServiceAdapter, onChange func(ServiceStatusEvent)) *StatusWatcher {
+	return &StatusWatcher{
+		adapter:  adapter,
+		onChange: onChange,
+		wake:     make(chan struct{}, 1),
+		known:    make(map[string]ServiceStatus),
+		native:   make(map[string]bool),
+	}
+}
+
+// Run watches for status changes until the context is cancelled
+func (w *StatusWatcher) Run(ctx context.Context) {
+	interval := statusPollInterval
+
+	w.mu.Lock()
+	w.notifier = newStatusNotifier(w.wake)
+	if w.notifier != nil {
+		interval = statusNotifiedPollInterval
+		w.notifier.Watch(w.nativeNamesLocked())
+	}
+	w.mu.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	defer func() {
+		w.mu.Lock()
+		if w.notifier != nil {
+			w.notifier.Close()
+			w.notifier = nil
+		}
+		w.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Poll()
+		case <-w.wake:
+			w.Poll()
+		}
+	}
+}
+
+// SetServices replaces the watched services with freshly detected ones and
+// reports any transitions since they were last seen
+func (w *StatusWatcher) SetServices(services []Service) {
+	var events []ServiceStatusEvent
+
+	w.mu.Lock()
+	known := make(map[string]ServiceStatus, len(services))
+	native := make(map[string]bool, len(services))
+	for i := range services {
+		service := &services[i]
+		if old, exists := w.known[service.Name]; exists && old != service.Status {
+			events = append(events, ServiceStatusEvent{Name: service.Name, OldStatus: old, NewStatus: service.Status})
+		}
+		known[service.Name] = service.Status
+		if service.Backend == "" || service.Backend == BackendNative {
+			native[service.Name] = true
+		}
+	}
+	w.known = known
+	w.native = native
+
+	if w.notifier != nil {
+		w.notifier.Watch(w.nativeNamesLocked())
+	}
+	w.mu.Unlock()
+
+	w.report(events)
+}
+
+// Refresh queries a single service immediately, e.g. after ShutDB changed it
+func (w *StatusWatcher) Refresh(name string) {
+	status, err := w.adapter.GetServiceStatus(name)
+	if err != nil {
+		return
+	}
+	w.Observe(name, status)
+}
+
+// Observe records a status obtained elsewhere and reports it if it changed
+func (w *StatusWatcher) Observe(name string, status ServiceStatus) {
+	w.report(w.update(map[string]ServiceStatus{name: status}, true))
+}
+
+// Poll queries every watched service and reports transitions
+func (w *StatusWatcher) Poll() {
+	w.mu.Lock()
+	names := make([]string, 0, len(w.known))
+	for name := range w.known {
+		names = append(names, name)
+	}
+	w.mu.Unlock()
+
+	current := make(map[string]ServiceStatus, len(names))
+	for _, name := range names {
+		status, err := w.adapter.GetServiceStatus(name)
+		if err != nil {
+			// Transient query failures should not be reported as transitions
+			continue
+		}
+		current[name] = status
+	}
+
+	w.report(w.update(current, false))
+}
+
+// Status returns the last observed status of a service
+func (w *StatusWatcher) Status(name string) (ServiceStatus, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	status, exists := w.known[name]
+	return status, exists
+}
+
+// update records the observed statuses and returns the resulting transitions.
+// Unknown services are only added when addUnknown is set, so a poll racing with
+// SetServices cannot resurrect services that are no longer detected.
+func (w *StatusWatcher) update(current map[string]ServiceStatus, addUnknown bool) []ServiceStatusEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var events []ServiceStatusEvent
+	for name, status := range current {
+		old, exists := w.known[name]
+		if !exists && !addUnknown {
+			continue
+		}
+		if exists && old != status {
+			events = append(events, ServiceStatusEvent{Name: name, OldStatus: old, NewStatus: status})
+		}
+		w.known[name] = status
+	}
+	return events
+}
+
+// report delivers transitions to the change callback outside the lock
+func (w *StatusWatcher) report(events []ServiceStatusEvent) {
+	if w.onChange == nil {
+		return
+	}
+	for _, event := range events {
+		w.onChange(event)
+	}
+}
+
+// nativeNamesLocked returns the watched services owned by the native backend
+func (w *StatusWatcher) nativeNamesLocked() []string {
+	names := make([]string, 0, len(w.native))
+	for name := range w.native {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,151 @@
+package app
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventRecorder collects status events delivered by a StatusWatcher
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []ServiceStatusEvent
+}
+
+func (r *eventRecorder) record(event ServiceStatusEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) snapshot() []ServiceStatusEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ServiceStatusEvent(nil), r.events...)
+}
+
+func createTestStatusWatcher() (*StatusWatcher, *fakeServiceAdapter, *eventRecorder) {
+	adapter := newFakeServiceAdapter(
+		OSService{Name: "MSSQLSERVER", Status: StatusRunning},
+		OSService{Name: "Redis", Status: StatusStopped},
+	)
+	recorder := &eventRecorder{}
+	watcher := NewStatusWatcher(adapter, recorder.record)
+	watcher.SetServices([]Service{
+		{Name: "MSSQLSERVER", Status: StatusRunning, Backend: BackendNative},
+		{Name: "Redis", Status: StatusStopped, Backend: BackendNative},
+	})
+	return watcher, adapter, recorder
+}
+
+func TestStatusWatcherPollReportsChanges(t *testing.T) {
+	watcher, adapter, recorder := createTestStatusWatcher()
+
+	// Nothing changed yet
+	watcher.Poll()
+	if events := recorder.snapshot(); len(events) != 0 {
+		t.Fatalf("Expected no events, got %+v", events)
+	}
+
+	// Simulate `net stop MSSQLSERVER` outside the app
+	adapter.setStatus("MSSQLSERVER", StatusStopped)
+	watcher.Poll()
+
+	events := recorder.snapshot()
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %+v", events)
+	}
+
+	expected := ServiceStatusEvent{Name: "MSSQLSERVER", OldStatus: StatusRunning, NewStatus: StatusStopped}
+	if events[0] != expected {
+		t.Errorf("Expected %+v, got %+v", expected, events[0])
+	}
+
+	// The same state should not be reported twice
+	watcher.Poll()
+	if events := recorder.snapshot(); len(events) != 1 {
+		t.Errorf("Expected no additional events, got %+v", events)
+	}
+}
+
+func TestStatusWatcherSetServicesReportsChanges(t *testing.T) {
+	watcher, _, recorder := createTestStatusWatcher()
+
+	watcher.SetServices([]Service{
+		{Name: "MSSQLSERVER", Status: StatusRunning},
+		{Name: "Redis", Status: StatusRunning},
+		{Name: "MongoDB", Status: StatusRunning},
+	})
+
+	events := recorder.snapshot()
+	if len(events) != 1 || events[0].Name != "Redis" || events[0].NewStatus != StatusRunning {
+		t.Fatalf("Expected a single Redis transition, got %+v", events)
+	}
+
+	if _, exists := watcher.Status("MongoDB"); !exists {
+		t.Error("Newly detected services should be watched")
+	}
+}
+
+func TestStatusWatcherRefresh(t *testing.T) {
+	watcher, adapter, recorder := createTestStatusWatcher()
+
+	adapter.setStatus("Redis", StatusRunning)
+	watcher.Refresh("Redis")
+
+	events := recorder.snapshot()
+	if len(events) != 1 || events[0].Name != "Redis" || events[0].OldStatus != StatusStopped {
+		t.Fatalf("Expected a Redis transition from stopped, got %+v", events)
+	}
+
+	status, _ := watcher.Status("Redis")
+	if status != StatusRunning {
+		t.Errorf("Expected watcher to record running, got %s", status)
+	}
+}
+
+func TestStatusWatcherRunStopsWithContext(t *testing.T) {
+	watcher, _, _ := createTestStatusWatcher()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run() did not return after the context was cancelled")
+	}
+}
+
+func TestServiceCacheUpdateKeepsTTL(t *testing.T) {
+	cache := NewServiceCache(time.Minute)
+	cache.SetAll([]Service{{Name: "Redis", Status: StatusStopped}})
+
+	cache.mu.RLock()
+	lastUpdate := cache.lastUpdate
+	cache.mu.RUnlock()
+
+	if !cache.Update("Redis", func(service *Service) { service.Status = StatusRunning }) {
+		t.Fatal("Update() should report that Redis is cached")
+	}
+	if cache.Update("missing", func(service *Service) {}) {
+		t.Error("Update() should report that missing services are not cached")
+	}
+
+	service, _ := cache.Get("Redis")
+	if service.Status != StatusRunning {
+		t.Errorf("Expected cached status running, got %s", service.Status)
+	}
+
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	if !cache.lastUpdate.Equal(lastUpdate) {
+		t.Error("Update() should not extend the cache TTL")
+	}
+}
@@ -0,0 +1,585 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	systemdBusName        = "org.freedesktop.systemd1"
+	systemdObjectPath     = dbus.ObjectPath("/org/freedesktop/systemd1")
+	systemdManagerIface   = "org.freedesktop.systemd1.Manager"
+	systemdUnitIface      = "org.freedesktop.systemd1.Unit"
+	systemdServiceIface   = "org.freedesktop.systemd1.Service"
+	systemdJobIface       = "org.freedesktop.systemd1.Job"
+	systemdJobModeReplace = "replace"
+)
+
+// Unit properties holding hard dependencies. A unit is stopped together with the units it
+// Requires, Requisite or BindsTo, so these are the relations a stop cascades along.
+var (
+	systemdDependencyProperties = []string{"Requires", "Requisite", "BindsTo"}
+	systemdDependentProperties  = []string{"RequiredBy", "RequisiteOf", "BoundBy"}
+)
+
+// systemdUnitStatus mirrors the struct returned by Manager.ListUnitsByPatterns
+type systemdUnitStatus struct {
+	Name        string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Followed    string
+	Path        dbus.ObjectPath
+	JobID       uint32
+	JobType     string
+	JobPath     dbus.ObjectPath
+}
+
+// systemdUnitFile mirrors the struct returned by Manager.ListUnitFilesByPatterns
+type systemdUnitFile struct {
+	Path  string
+	State string
+}
+
+// SystemdServiceAdapter implements OSServiceAdapter for Linux using systemd over D-Bus
+type SystemdServiceAdapter struct {
+	// No persistent connection - we'll connect per operation
+}
+
+// NewSystemdServiceAdapter creates a new systemd service adapter
+func NewSystemdServiceAdapter() *SystemdServiceAdapter {
+	return &SystemdServiceAdapter{}
+}
+
+// newPlatformServiceAdapter returns the service adapter for the current OS
+func newPlatformServiceAdapter() OSServiceAdapter {
+	return NewSystemdServiceAdapter()
+}
+
+// connectSystemd establishes a private connection to the system bus
+func (s *SystemdServiceAdapter) connectSystemd() (*dbus.Conn, dbus.BusObject, error) {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		return nil, nil, &ServiceError{
+			Code:    ErrPermissionDenied,
+			Message: fmt.Sprintf("Failed to connect to systemd over D-Bus: %v", err),
+		}
+	}
+	return conn, conn.Object(systemdBusName, systemdObjectPath), nil
+}
+
+// callManager invokes a systemd Manager method, allowing polkit to prompt for authorization
+func (s *SystemdServiceAdapter) callManager(manager dbus.BusObject, method string, args ...interface{}) *dbus.Call {
+	return manager.Call(systemdManagerIface+"."+method, dbus.FlagAllowInteractiveAuthorization, args...)
+}
+
+// mapDBusError converts a D-Bus error into a ServiceError
+func mapDBusError(err error, name, action string) error {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) {
+		switch dbusErr.Name {
+		case "org.freedesktop.systemd1.NoSuchUnit", "org.freedesktop.systemd1.LoadFailed":
+			return &ServiceError{
+				Code:    ErrServiceNotFound,
+				Message: fmt.Sprintf("Service not found: %s", name),
+				Service: name,
+			}
+		case "org.freedesktop.DBus.Error.AccessDenied",
+			"org.freedesktop.DBus.Error.InteractiveAuthorizationRequired":
+			return &ServiceError{
+				Code:    ErrPermissionDenied,
+				Message: fmt.Sprintf("Permission denied while trying to %s", action),
+				Service: name,
+			}
+		}
+	}
+
+	return &ServiceError{
+		Code:    ErrSystemError,
+		Message: fmt.Sprintf("Failed to %s: %v", action, err),
+		Service: name,
+	}
+}
+
+// ListServices retrieves all systemd service units, including installed units that are not loaded
+func (s *SystemdServiceAdapter) ListServices() ([]OSService, error) {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var units []systemdUnitStatus
+	err = s.callManager(manager, "ListUnitsByPatterns", []string{}, []string{"*.service"}).Store(&units)
+	if err != nil {
+		return nil, &ServiceError{
+			Code:    ErrSystemError,
+			Message: fmt.Sprintf("Failed to list services: %v", err),
+		}
+	}
+
+	services := make([]OSService, 0, len(units))
+	seen := make(map[string]bool, len(units))
+
+	for i := range units {
+		unit := &units[i]
+		if unit.LoadState == "not-found" {
+			continue
+		}
+
+		displayName := unit.Name
+		if unit.Description != "" {
+			displayName = unit.Description
+		}
+
+		service := OSService{
+			Name:         unit.Name,
+			DisplayName:  displayName,
+			Status:       mapSystemdStateToStatus(unit.ActiveState, unit.SubState),
+			StartupTypes: systemdStartupTypes,
+		}
+		s.readServiceProcess(conn, unit.Path, &service)
+
+		services = append(services, service)
+		seen[unit.Name] = true
+	}
+
+	// Units that are installed but currently unloaded are not reported by
+	// ListUnitsByPatterns, so merge them in from the unit file list
+	var unitFiles []systemdUnitFile
+	err = s.callManager(manager, "ListUnitFilesByPatterns", []string{}, []string{"*.service"}).Store(&unitFiles)
+	if err != nil {
+		// Loaded units are still useful on their own
+		return services, nil
+	}
+
+	for _, unitFile := range unitFiles {
+		name := unitFile.Path[strings.LastIndex(unitFile.Path, "/")+1:]
+
+		// Skip templates such as postgresql@.service - only instances can be controlled
+		if seen[name] || strings.HasSuffix(name, "@.service") {
+			continue
+		}
+
+		services = append(services, OSService{
+			Name:         name,
+			DisplayName:  name,
+			Status:       StatusStopped,
+			StartupTypes: systemdStartupTypes,
+		})
+		seen[name] = true
+	}
+
+	return services, nil
+}
+
+// readServiceProcess fills in the executable, arguments, account and main PID of a loaded unit.
+// Failures are ignored since the service is still usable without them.
+func (s *SystemdServiceAdapter) readServiceProcess(conn *dbus.Conn, unitPath dbus.ObjectPath, service *OSService) {
+	if unitPath == "" {
+		return
+	}
+
+	var properties map[string]dbus.Variant
+	err := conn.Object(systemdBusName, unitPath).Call("org.freedesktop.DBus.Properties.GetAll", 0, systemdServiceIface).Store(&properties)
+	if err != nil {
+		return
+	}
+
+	service.BinaryPath, service.Arguments = parseSystemdExecStart(properties["ExecStart"].Value())
+	if pid, ok := properties["MainPID"].Value().(uint32); ok {
+		service.PID = pid
+	}
+	if user, ok := properties["User"].Value().(string); ok {
+		service.Account = user
+	}
+	if service.Account == "" {
+		service.Account = "root"
+	}
+}
+
+// parseSystemdExecStart extracts the first command of an ExecStart property, which has the
+// D-Bus signature a(sasbttttuii): path, argv (including argv[0]), ignore errors and timestamps
+func parseSystemdExecStart(value interface{}) (string, []string) {
+	commands, ok := value.([][]interface{})
+	if !ok || len(commands) == 0 || len(commands[0]) < 2 {
+		return "", nil
+	}
+
+	path, _ := commands[0][0].(string)
+	argv, _ := commands[0][1].([]string)
+	if len(argv) > 0 {
+		argv = argv[1:]
+	}
+	return path, argv
+}
+
+// getUnitState loads a unit and returns its ActiveState and SubState
+func (s *SystemdServiceAdapter) getUnitState(conn *dbus.Conn, manager dbus.BusObject, name string) (string, string, error) {
+	var unitPath dbus.ObjectPath
+	if err := s.callManager(manager, "LoadUnit", name).Store(&unitPath); err != nil {
+		return "", "", mapDBusError(err, name, "load service")
+	}
+
+	unit := conn.Object(systemdBusName, unitPath)
+
+	loadState, err := unit.GetProperty(systemdUnitIface + ".LoadState")
+	if err != nil {
+		return "", "", mapDBusError(err, name, "query service status")
+	}
+	if state, _ := loadState.Value().(string); state == "not-found" {
+		return "", "", &ServiceError{
+			Code:    ErrServiceNotFound,
+			Message: fmt.Sprintf("Service not found: %s", name),
+			Service: name,
+		}
+	}
+
+	activeState, err := unit.GetProperty(systemdUnitIface + ".ActiveState")
+	if err != nil {
+		return "", "", mapDBusError(err, name, "query service status")
+	}
+
+	subState, err := unit.GetProperty(systemdUnitIface + ".SubState")
+	if err != nil {
+		return "", "", mapDBusError(err, name, "query service status")
+	}
+
+	active, _ := activeState.Value().(string)
+	sub, _ := subState.Value().(string)
+	return active, sub, nil
+}
+
+// GetServiceStatus retrieves the current status of a specific unit
+func (s *SystemdServiceAdapter) GetServiceStatus(name string) (ServiceStatus, error) {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return StatusStopped, err
+	}
+	defer conn.Close()
+
+	activeState, subState, err := s.getUnitState(conn, manager, name)
+	if err != nil {
+		return StatusStopped, err
+	}
+
+	return mapSystemdStateToStatus(activeState, subState), nil
+}
+
+// mapSystemdStateToStatus converts a unit's ActiveState/SubState pair to our ServiceStatus enum
+func mapSystemdStateToStatus(activeState, subState string) ServiceStatus {
+	switch activeState {
+	case "active":
+		return StatusRunning
+	case "reloading", "refreshing":
+		return StatusRestarting
+	case "activating":
+		if subState == "auto-restart" {
+			return StatusRestarting
+		}
+		return StatusStarting
+	case "deactivating":
+		return StatusStopping
+	case "inactive", "failed", "maintenance":
+		return StatusStopped
+	default:
+		return StatusStopped
+	}
+}
+
+// mapUnitFileStateToStartupType converts a UnitFileState to our StartupType enum.
+// Enabled units start at boot, masked units cannot be started at all, and
+// everything else can only be started on demand.
+func mapUnitFileStateToStartupType(unitFileState string) StartupType {
+	switch unitFileState {
+	case "enabled", "enabled-runtime", "linked", "linked-runtime", "alias", "indirect", "generated":
+		return StartupAutomatic
+	case "masked", "masked-runtime":
+		return StartupDisabled
+	default:
+		return StartupManual
+	}
+}
+
+// runJob queues a unit job and waits for systemd to report its result. If ctx ends first,
+// the job is canceled unless systemd has already started executing it. Without a deadline
+// the wait is bounded by the unit's own TimeoutStartSec/TimeoutStopSec.
+func (s *SystemdServiceAdapter) runJob(ctx context.Context, conn *dbus.Conn, manager dbus.BusObject, method, name, action string) error {
+	// Subscribe before queuing the job so the JobRemoved signal cannot be missed
+	if err := s.callManager(manager, "Subscribe").Err; err != nil {
+		return mapDBusError(err, name, action)
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(systemdObjectPath),
+		dbus.WithMatchInterface(systemdManagerIface),
+		dbus.WithMatchMember("JobRemoved"),
+	); err != nil {
+		return mapDBusError(err, name, action)
+	}
+
+	signals := make(chan *dbus.Signal, 16)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	var jobPath dbus.ObjectPath
+	if err := s.callManager(manager, method, name, systemdJobModeReplace).Store(&jobPath); err != nil {
+		return mapDBusError(err, name, action)
+	}
+
+	for {
+		select {
+		case signal := <-signals:
+			// JobRemoved carries (id uint32, job path, unit name, result)
+			if signal.Name != systemdManagerIface+".JobRemoved" || len(signal.Body) < 4 {
+				continue
+			}
+			if path, _ := signal.Body[1].(dbus.ObjectPath); path != jobPath {
+				continue
+			}
+
+			result, _ := signal.Body[3].(string)
+			switch result {
+			case "done":
+				return nil
+			case "timeout":
+				return &ServiceError{
+					Code:    ErrOperationTimeout,
+					Message: fmt.Sprintf("systemd timed out while trying to %s", action),
+					Service: name,
+				}
+			default:
+				return &ServiceError{
+					Code:    ErrSystemError,
+					Message: fmt.Sprintf("Failed to %s: job %s", action, result),
+					Service: name,
+				}
+			}
+		case <-ctx.Done():
+			// Fails harmlessly if the job already finished
+			conn.Object(systemdBusName, jobPath).Call(systemdJobIface+".Cancel", 0)
+			return contextError(ctx, name)
+		}
+	}
+}
+
+// StartService starts a unit and waits for the job to complete
+func (s *SystemdServiceAdapter) StartService(ctx context.Context, name string) error {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	activeState, _, err := s.getUnitState(conn, manager, name)
+	if err != nil {
+		return err
+	}
+
+	// Validate state - can't start if already running or starting
+	if activeState == "active" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already running",
+			Service: name,
+		}
+	}
+
+	if activeState == "activating" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already starting",
+			Service: name,
+		}
+	}
+
+	return s.runJob(ctx, conn, manager, "StartUnit", name, "start service")
+}
+
+// StopService stops a unit and waits for the job to complete
+func (s *SystemdServiceAdapter) StopService(ctx context.Context, name string) error {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	activeState, _, err := s.getUnitState(conn, manager, name)
+	if err != nil {
+		return err
+	}
+
+	// Validate state - can't stop if already stopped or stopping
+	if activeState == "inactive" || activeState == "failed" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already stopped",
+			Service: name,
+		}
+	}
+
+	if activeState == "deactivating" {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: "Service is already stopping",
+			Service: name,
+		}
+	}
+
+	return s.runJob(ctx, conn, manager, "StopUnit", name, "stop service")
+}
+
+// RestartService restarts a unit, starting it if it is not running
+func (s *SystemdServiceAdapter) RestartService(ctx context.Context, name string) error {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, _, err := s.getUnitState(conn, manager, name); err != nil {
+		return err
+	}
+
+	return s.runJob(ctx, conn, manager, "RestartUnit", name, "restart service")
+}
+
+// PauseService is not supported: systemd units have no paused state
+func (s *SystemdServiceAdapter) PauseService(ctx context.Context, name string) error {
+	return &ServiceError{
+		Code:    ErrInvalidState,
+		Message: "systemd units cannot be paused",
+		Service: name,
+	}
+}
+
+// ContinueService is not supported: systemd units have no paused state
+func (s *SystemdServiceAdapter) ContinueService(ctx context.Context, name string) error {
+	return &ServiceError{
+		Code:    ErrInvalidState,
+		Message: "systemd units cannot be paused",
+		Service: name,
+	}
+}
+
+// GetStartupType retrieves the startup type of a unit from its UnitFileState
+func (s *SystemdServiceAdapter) GetStartupType(name string) (StartupType, error) {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return StartupDisabled, err
+	}
+	defer conn.Close()
+
+	var unitFileState string
+	if err := s.callManager(manager, "GetUnitFileState", name).Store(&unitFileState); err != nil {
+		return StartupDisabled, mapDBusError(err, name, "get service configuration")
+	}
+
+	return mapUnitFileStateToStartupType(unitFileState), nil
+}
+
+// systemdStartupTypes are the startup types a unit file state can represent
+var systemdStartupTypes = []StartupType{StartupAutomatic, StartupManual, StartupDisabled}
+
+// SetStartupType changes whether a unit starts at boot. Automatic enables the unit, manual
+// leaves it startable on demand only, and disabled also masks it so that it cannot be started.
+// systemd orders units by their dependencies, so there is no delayed automatic start.
+func (s *SystemdServiceAdapter) SetStartupType(name string, startupType StartupType) error {
+	if startupType != StartupAutomatic && startupType != StartupManual && startupType != StartupDisabled {
+		return &ServiceError{
+			Code:    ErrInvalidState,
+			Message: fmt.Sprintf("systemd units cannot use the %s startup type", startupType),
+			Service: name,
+		}
+	}
+
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	units := []string{name}
+	action := fmt.Sprintf("set startup type to %s", startupType)
+
+	if startupType == StartupDisabled {
+		if err := s.callManager(manager, "DisableUnitFiles", units, false).Err; err != nil {
+			return mapDBusError(err, name, action)
+		}
+		if err := s.callManager(manager, "MaskUnitFiles", units, false, true).Err; err != nil {
+			return mapDBusError(err, name, action)
+		}
+	} else {
+		if err := s.callManager(manager, "UnmaskUnitFiles", units, false).Err; err != nil {
+			return mapDBusError(err, name, action)
+		}
+		method := "DisableUnitFiles"
+		args := []interface{}{units, false}
+		if startupType == StartupAutomatic {
+			method, args = "EnableUnitFiles", []interface{}{units, false, true}
+		}
+		if err := s.callManager(manager, method, args...).Err; err != nil {
+			return mapDBusError(err, name, action)
+		}
+	}
+
+	if err := s.callManager(manager, "Reload").Err; err != nil {
+		return mapDBusError(err, name, "reload systemd configuration")
+	}
+
+	return nil
+}
+
+// GetDependencies returns the service units a unit requires
+func (s *SystemdServiceAdapter) GetDependencies(name string) ([]string, error) {
+	return s.unitRelations(name, systemdDependencyProperties)
+}
+
+// GetDependents returns the service units that require a unit
+func (s *SystemdServiceAdapter) GetDependents(name string) ([]string, error) {
+	return s.unitRelations(name, systemdDependentProperties)
+}
+
+// unitRelations loads a unit and returns the service units listed in the given dependency properties
+func (s *SystemdServiceAdapter) unitRelations(name string, properties []string) ([]string, error) {
+	conn, manager, err := s.connectSystemd()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var unitPath dbus.ObjectPath
+	if err := s.callManager(manager, "LoadUnit", name).Store(&unitPath); err != nil {
+		return nil, mapDBusError(err, name, "load service")
+	}
+	unit := conn.Object(systemdBusName, unitPath)
+
+	var units []string
+	for _, property := range properties {
+		value, err := unit.GetProperty(systemdUnitIface + "." + property)
+		if err != nil {
+			return nil, mapDBusError(err, name, "query service dependencies")
+		}
+		names, _ := value.Value().([]string)
+		units = append(units, names...)
+	}
+	return filterServiceUnits(units), nil
+}
+
+// filterServiceUnits keeps each .service unit once, dropping targets, sockets, mounts and other unit types
+func filterServiceUnits(units []string) []string {
+	services := make([]string, 0, len(units))
+	seen := make(map[string]bool, len(units))
+	for _, unit := range units {
+		if strings.HasSuffix(unit, ".service") && !seen[unit] {
+			seen[unit] = true
+			services = append(services, unit)
+		}
+	}
+	return services
+}
@@ -0,0 +1,101 @@
+package app
+
+import "testing"
+
+func TestMapSystemdStateToStatus(t *testing.T) {
+	tests := []struct {
+		activeState string
+		subState    string
+		expected    ServiceStatus
+	}{
+		{"active", "running", StatusRunning},
+		{"active", "exited", StatusRunning},
+		{"reloading", "reload", StatusRestarting},
+		{"activating", "start-pre", StatusStarting},
+		{"activating", "auto-restart", StatusRestarting},
+		{"deactivating", "stop-sigterm", StatusStopping},
+		{"inactive", "dead", StatusStopped},
+		{"failed", "failed", StatusStopped},
+		{"unknown-state", "", StatusStopped},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.activeState+"/"+tt.subState, func(t *testing.T) {
+			status := mapSystemdStateToStatus(tt.activeState, tt.subState)
+			if status != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, status)
+			}
+		})
+	}
+}
+
+func TestMapUnitFileStateToStartupType(t *testing.T) {
+	tests := []struct {
+		unitFileState string
+		expected      StartupType
+	}{
+		{"enabled", StartupAutomatic},
+		{"enabled-runtime", StartupAutomatic},
+		{"linked", StartupAutomatic},
+		{"alias", StartupAutomatic},
+		{"disabled", StartupManual},
+		{"static", StartupManual},
+		{"transient", StartupManual},
+		{"masked", StartupDisabled},
+		{"masked-runtime", StartupDisabled},
+		{"", StartupManual},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.unitFileState, func(t *testing.T) {
+			startupType := mapUnitFileStateToStartupType(tt.unitFileState)
+			if startupType != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, startupType)
+			}
+		})
+	}
+}
+
+func TestParseSystemdExecStart(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		binary string
+		args   []string
+	}{
+		{"postgres", [][]interface{}{{"/usr/lib/postgresql/16/bin/postgres", []string{"/usr/lib/postgresql/16/bin/postgres", "-D", "/var/lib/postgresql/16/main"}, false}},
+			"/usr/lib/postgresql/16/bin/postgres", []string{"-D", "/var/lib/postgresql/16/main"}},
+		{"no arguments", [][]interface{}{{"/usr/bin/redis-server", []string{"/usr/bin/redis-server"}, false}}, "/usr/bin/redis-server", []string{}},
+		{"empty", [][]interface{}{}, "", nil},
+		{"unexpected type", "not a command", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			binary, args := parseSystemdExecStart(tt.value)
+			if binary != tt.binary || len(args) != len(tt.args) {
+				t.Fatalf("Expected %q %q, got %q %q", tt.binary, tt.args, binary, args)
+			}
+			for i := range args {
+				if args[i] != tt.args[i] {
+					t.Errorf("Argument %d: expected %q, got %q", i, tt.args[i], args[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFilterServiceUnits(t *testing.T) {
+	units := []string{"postgresql@16-main.service", "sysinit.target", "postgresql.socket", "-.mount", "postgresql@16-main.service", "pgbouncer.service"}
+	expected := []string{"postgresql@16-main.service", "pgbouncer.service"}
+
+	services := filterServiceUnits(units)
+	if len(services) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, services)
+	}
+	for i := range expected {
+		if services[i] != expected[i] {
+			t.Errorf("Unit %d: expected %q, got %q", i, expected[i], services[i])
+		}
+	}
+}
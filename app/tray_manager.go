@@ -45,10 +45,44 @@ func (tm *TrayManager) OnStartup(ctx context.Context) error {
 		return err
 	}
 
+	// Report crashes of keep-alive services and their restarts
+	if tm.serviceManager != nil && tm.serviceManager.watchdog != nil {
+		tm.serviceManager.watchdog.setNotifier(tm.notifyWatchdog)
+	}
+	// Report services stopped for being idle
+	if tm.serviceManager != nil && tm.serviceManager.idleMonitor != nil {
+		tm.serviceManager.idleMonitor.setNotifier(tm.notifyIdleStop)
+	}
+
 	tm.isInitialized = true
 	return nil
 }
 
+// notifyWatchdog shows a crash or restart of a keep-alive service if tray notifications are enabled
+func (tm *TrayManager) notifyWatchdog(title, message string) {
+	tm.showNotification(runtime.WarningDialog, title, message)
+}
+
+// notifyIdleStop shows that an idle service was stopped if tray notifications are enabled
+func (tm *TrayManager) notifyIdleStop(title, message string) {
+	tm.showNotification(runtime.InfoDialog, title, message)
+}
+
+// showNotification shows a message about something ShutDB did on its own if tray notifications are enabled
+func (tm *TrayManager) showNotification(dialogType runtime.DialogType, title, message string) {
+	if tm.ctx == nil || !tm.configManager.GetTrayNotifications() {
+		return
+	}
+
+	// The dialog blocks until dismissed, which must not hold up the watchdog or idle monitor
+	ctx := tm.ctx
+	go runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+		Type:    dialogType,
+		Title:   title,
+		Message: message,
+	})
+}
+
 // OnShutdown handles cleanup when the application shuts down
 func (tm *TrayManager) OnShutdown(ctx context.Context) {
 	// Clean up tray icon and system resources
@@ -153,7 +187,7 @@ func (tm *TrayManager) handleSystrayToggleService(menuItem *systray.MenuItem) {
 	newState := !currentState
 
 	// Update the configuration
-	if err := tm.configManager.SetServiceState(newState); err != nil {
+	if err := tm.serviceManager.control(InitiatorTray).SetServiceControlState(newState); err != nil {
 		// Show error notification using Wails runtime
 		if tm.ctx != nil {
 			runtime.MessageDialog(tm.ctx, runtime.MessageDialogOptions{
@@ -228,7 +262,7 @@ func (tm *TrayManager) HandleToggleService(data *menu.CallbackData) {
 	newState := !currentState
 
 	// Update the configuration
-	if err := tm.configManager.SetServiceState(newState); err != nil {
+	if err := tm.serviceManager.control(InitiatorTray).SetServiceControlState(newState); err != nil {
 		// Show error notification using Wails runtime
 		runtime.MessageDialog(tm.ctx, runtime.MessageDialogOptions{
 			Type:    runtime.ErrorDialog,
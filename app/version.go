@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// versionCommandTimeout bounds how long a binary may take to print its version
+const versionCommandTimeout = 3 * time.Second
+
+// versionPattern finds the first dotted version number in --version output, e.g. "16.2" in
+// "postgres (PostgreSQL) 16.2" or "7.2.4" in "Redis server v=7.2.4 sha=00000000:0"
+var versionPattern = regexp.MustCompile(`(?:^|[^\w.])v?(\d+\.\d+(?:\.\d+)*)`)
+
+// versionCommandExecutables are the database binaries that are known to print their version
+// and exit when run with --version. Other binaries are never executed.
+var versionCommandExecutables = map[string]bool{
+	"postgres":     true,
+	"pg_ctl":       true,
+	"mysqld":       true,
+	"mariadbd":     true,
+	"mongod":       true,
+	"mongos":       true,
+	"redis-server": true,
+	"memcached":    true,
+	"influxd":      true,
+}
+
+// binaryVersionEntry is a cached version together with the file state it was read from
+type binaryVersionEntry struct {
+	modTime time.Time
+	size    int64
+	version string
+}
+
+// binaryVersionCache looks up product versions of service binaries and remembers them
+// until the binary changes, so detection does not re-run binaries on every refresh
+type binaryVersionCache struct {
+	mu      sync.Mutex
+	entries map[string]binaryVersionEntry
+}
+
+// newBinaryVersionCache creates an empty version cache
+func newBinaryVersionCache() *binaryVersionCache {
+	return &binaryVersionCache{entries: make(map[string]binaryVersionEntry)}
+}
+
+// Version returns the product version of a binary from its version resource or, for known
+// database binaries, its --version output. It returns "" if the version cannot be determined.
+func (c *binaryVersionCache) Version(path string) string {
+	if path == "" {
+		return ""
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	c.mu.Lock()
+	entry, exists := c.entries[path]
+	c.mu.Unlock()
+	if exists && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return entry.version
+	}
+
+	version, err := binaryVersionResource(path)
+	if err != nil || version == "" {
+		version = versionFromCommand(path)
+	}
+
+	c.mu.Lock()
+	c.entries[path] = binaryVersionEntry{modTime: info.ModTime(), size: info.Size(), version: version}
+	c.mu.Unlock()
+
+	return version
+}
+
+// versionFromCommand runs a known database binary with --version and parses its output
+func versionFromCommand(path string) string {
+	if !versionCommandExecutables[executableName(path)] {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), versionCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, "--version")
+	hideCommandWindow(cmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil && len(output) == 0 {
+		return ""
+	}
+	return parseVersionOutput(string(output))
+}
+
+// parseVersionOutput returns the first version number in the output of --version
+func parseVersionOutput(output string) string {
+	if match := versionPattern.FindStringSubmatch(output); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// trimVersion drops trailing zero components of a four-part resource version,
+// keeping at least major.minor, e.g. "16.2.0.0" becomes "16.2"
+func trimVersion(version string) string {
+	parts := strings.Split(version, ".")
+	for len(parts) > 2 && parts[len(parts)-1] == "0" {
+		parts = parts[:len(parts)-1]
+	}
+	return strings.Join(parts, ".")
+}
+
+// executableName returns the lowercase file name of a binary without ".exe",
+// e.g. "redis-server" for C:\Redis\redis-server.exe
+func executableName(path string) string {
+	// Windows paths are handled on every platform so that names are parsed consistently
+	name := strings.ToLower(path[strings.LastIndexAny(path, `/\`)+1:])
+	return strings.TrimSuffix(name, ".exe")
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+package app
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// binaryVersionResource is not available outside Windows; versions come from --version instead
+func binaryVersionResource(path string) (string, error) {
+	return "", errors.New("version resources are only available on Windows")
+}
+
+// hideCommandWindow is only needed on Windows
+func hideCommandWindow(cmd *exec.Cmd) {}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"postgres (PostgreSQL) 16.2", "16.2"},
+		{"pg_ctl (PostgreSQL) 15.6 (Ubuntu 15.6-1.pgdg22.04+1)", "15.6"},
+		{"/usr/sbin/mysqld  Ver 8.0.36 for Linux on x86_64 (MySQL Community Server - GPL)", "8.0.36"},
+		{"mariadbd  Ver 10.11.6-MariaDB-0+deb12u1 for debian-linux-gnu on x86_64 (Debian 12)", "10.11.6"},
+		{"Redis server v=7.2.4 sha=00000000:0 malloc=jemalloc-5.3.0 bits=64 build=3b2fb9b1c3eb7b4c", "7.2.4"},
+		{"db version v7.0.5\nBuild Info: {...}", "7.0.5"},
+		{"memcached 1.6.21", "1.6.21"},
+		{"InfluxDB v2.7.5 (git: 09a9607fd9) build_date: 2024-01-05T17:22:05Z", "2.7.5"},
+		{"usage: server [options]", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			if version := parseVersionOutput(tt.output); version != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, version)
+			}
+		})
+	}
+}
+
+func TestTrimVersion(t *testing.T) {
+	tests := map[string]string{
+		"16.2.0.0":     "16.2",
+		"16.0.1000.6":  "16.0.1000.6",
+		"8.0.36.0":     "8.0.36",
+		"7.0.0.0":      "7.0",
+		"10.11.6.1000": "10.11.6.1000",
+	}
+
+	for version, expected := range tests {
+		if trimmed := trimVersion(version); trimmed != expected {
+			t.Errorf("trimVersion(%q): expected %q, got %q", version, expected, trimmed)
+		}
+	}
+}
+
+func TestExecutableName(t *testing.T) {
+	tests := map[string]string{
+		`C:\Program Files\PostgreSQL\16\bin\pg_ctl.exe`: "pg_ctl",
+		`C:\Redis\Redis-Server.EXE`:                     "redis-server",
+		"/usr/sbin/mysqld":                              "mysqld",
+		"/opt/mysql-8.0/bin/mysqld-8.0":                 "mysqld-8.0",
+		"mongod":                                        "mongod",
+	}
+
+	for path, expected := range tests {
+		if name := executableName(path); name != expected {
+			t.Errorf("executableName(%q): expected %q, got %q", path, expected, name)
+		}
+	}
+}
+
+func TestBinaryVersionCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake database binary")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "redis-server")
+	writeScript := func(version string) {
+		script := "#!/bin/sh\necho \"Redis server v=" + version + " sha=00000000:0 bits=64\"\n"
+		if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+			t.Fatalf("Failed to write fake binary: %v", err)
+		}
+	}
+
+	cache := newBinaryVersionCache()
+	writeScript("7.2.4")
+	if version := cache.Version(binary); version != "7.2.4" {
+		t.Fatalf("Expected 7.2.4, got %q", version)
+	}
+
+	// The cached version is used until the binary changes
+	cache.entries[binary] = binaryVersionEntry{modTime: cache.entries[binary].modTime, size: cache.entries[binary].size, version: "cached"}
+	if version := cache.Version(binary); version != "cached" {
+		t.Errorf("Expected the cached version, got %q", version)
+	}
+
+	writeScript("7.4.0-rc1")
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(binary, later, later)
+	if version := cache.Version(binary); version != "7.4.0" {
+		t.Errorf("Expected the upgraded version 7.4.0, got %q", version)
+	}
+
+	// Unknown binaries are never executed
+	other := filepath.Join(dir, "svchost")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\necho 9.9.9\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake binary: %v", err)
+	}
+	if version := cache.Version(other); version != "" {
+		t.Errorf("Expected no version for an unknown binary, got %q", version)
+	}
+
+	if version := cache.Version(filepath.Join(dir, "missing")); version != "" {
+		t.Errorf("Expected no version for a missing binary, got %q", version)
+	}
+}
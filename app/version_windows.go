@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// binaryVersionResource reads the product version from a binary's version resource
+func binaryVersionResource(path string) (string, error) {
+	size, err := windows.GetFileVersionInfoSize(path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := make([]byte, size)
+	if err := windows.GetFileVersionInfo(path, 0, size, unsafe.Pointer(&buffer[0])); err != nil {
+		return "", err
+	}
+
+	var info *windows.VS_FIXEDFILEINFO
+	var length uint32
+	if err := windows.VerQueryValue(unsafe.Pointer(&buffer[0]), `\`, unsafe.Pointer(&info), &length); err != nil {
+		return "", err
+	}
+	if info == nil || length == 0 {
+		return "", fmt.Errorf("no version information in %s", path)
+	}
+
+	version := fmt.Sprintf("%d.%d.%d.%d",
+		info.ProductVersionMS>>16, info.ProductVersionMS&0xffff,
+		info.ProductVersionLS>>16, info.ProductVersionLS&0xffff)
+	return trimVersion(version), nil
+}
+
+// hideCommandWindow keeps a console binary from flashing a window when run from the GUI
+func hideCommandWindow(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: windows.CREATE_NO_WINDOW}
+}
@@ -0,0 +1,258 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWatchdogInitialBackoff is the delay before the first restart after a crash
+	defaultWatchdogInitialBackoff = 5 * time.Second
+	// defaultWatchdogMaxBackoff bounds the doubling delay between restarts
+	defaultWatchdogMaxBackoff = 5 * time.Minute
+	// defaultWatchdogMaxRestarts is how many restarts are attempted within the window
+	defaultWatchdogMaxRestarts = 3
+	// defaultWatchdogWindow is the period the restart limit applies to
+	defaultWatchdogWindow = 10 * time.Minute
+
+	// watchdogRequestGrace is how long after a stop made through ShutDB the service stopping
+	// is attributed to that stop rather than to a crash. The status watcher may notice the
+	// change only at its next poll.
+	watchdogRequestGrace = statusNotifiedPollInterval
+)
+
+// watchdogStopOperations are the audited operations after which a service is expected to be
+// stopped. Disabling is not one of them, as it only changes the startup type.
+var watchdogStopOperations = map[string]bool{
+	"stop":       true,
+	"restart":    true,
+	"force-stop": true,
+}
+
+// WatchdogPolicy keeps a service running by restarting it when it stops without ShutDB having
+// stopped it. Restarts are delayed with exponential backoff and limited per time window.
+type WatchdogPolicy struct {
+	KeepAlive             bool `json:"keep_alive"`
+	InitialBackoffSeconds int  `json:"initial_backoff_seconds,omitempty"` // Delay before the first restart, defaults to 5 seconds
+	MaxBackoffSeconds     int  `json:"max_backoff_seconds,omitempty"`     // Upper bound of the doubling delay, defaults to 300 seconds
+	MaxRestarts           int  `json:"max_restarts,omitempty"`            // Restarts attempted within the window, defaults to 3
+	WindowSeconds         int  `json:"window_seconds,omitempty"`          // Defaults to 600 seconds
+}
+
+// secondsOr returns a number of seconds as a duration, or fallback if it is not set
+func secondsOr(seconds int, fallback time.Duration) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return fallback
+}
+
+// maxRestarts returns how many restarts are attempted within the window
+func (p WatchdogPolicy) maxRestarts() int {
+	if p.MaxRestarts > 0 {
+		return p.MaxRestarts
+	}
+	return defaultWatchdogMaxRestarts
+}
+
+// window returns the period the restart limit applies to
+func (p WatchdogPolicy) window() time.Duration {
+	return secondsOr(p.WindowSeconds, defaultWatchdogWindow)
+}
+
+// backoff returns the delay before a restart, given how many restarts were already
+// attempted within the window. The delay doubles with every attempt up to the maximum.
+func (p WatchdogPolicy) backoff(previous int) time.Duration {
+	delay := secondsOr(p.InitialBackoffSeconds, defaultWatchdogInitialBackoff)
+	maxDelay := secondsOr(p.MaxBackoffSeconds, defaultWatchdogMaxBackoff)
+	for i := 0; i < previous && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// validateWatchdogPolicies checks that no policy has a negative setting
+func validateWatchdogPolicies(policies map[string]WatchdogPolicy) error {
+	for name, policy := range policies {
+		if policy.InitialBackoffSeconds < 0 || policy.MaxBackoffSeconds < 0 || policy.MaxRestarts < 0 || policy.WindowSeconds < 0 {
+			return fmt.Errorf("watchdog policy for service %q cannot have negative settings", name)
+		}
+	}
+	return nil
+}
+
+// crashWatchdog restarts keep-alive services that crash
+type crashWatchdog struct {
+	manager    *ServiceManager
+	after      func(time.Duration) <-chan time.Time // Replaced in tests to skip the backoff
+	mu         sync.Mutex
+	notify     func(title, message string) // Shows crashes and restarts to the user, e.g. in the tray
+	restarts   map[string][]time.Time      // Restart attempts per service, oldest first
+	recovering map[string]bool             // Services with a restart pending
+	running    sync.WaitGroup
+}
+
+// newCrashWatchdog creates a watchdog for the services of a manager
+func newCrashWatchdog(sm *ServiceManager) *crashWatchdog {
+	return &crashWatchdog{
+		manager:    sm,
+		after:      time.After,
+		restarts:   make(map[string][]time.Time),
+		recovering: make(map[string]bool),
+	}
+}
+
+// setNotifier sets how crashes and restarts are shown to the user
+func (w *crashWatchdog) setNotifier(notify func(title, message string)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.notify = notify
+}
+
+// observe checks a status change for a crash of a keep-alive service and schedules its restart
+func (w *crashWatchdog) observe(event ServiceStatusEvent) {
+	if !event.OldStatus.hasProcess() || event.NewStatus != StatusStopped {
+		return
+	}
+
+	sm := w.manager
+	if sm.configManager == nil {
+		return
+	}
+	policy, enabled := sm.configManager.GetWatchdogPolicy(event.Name)
+	stoppedAt := time.Now()
+	if !enabled || w.stopIntended(event.Name, stoppedAt) {
+		return
+	}
+
+	w.mu.Lock()
+	if w.recovering[event.Name] {
+		w.mu.Unlock()
+		return
+	}
+	w.recovering[event.Name] = true
+	w.mu.Unlock()
+
+	sm.control(InitiatorWatchdog).audit(AuditEntry{
+		Operation:       "crash",
+		Service:         event.Name,
+		PriorStatus:     event.OldStatus,
+		ResultingStatus: event.NewStatus,
+	}, time.Now(), errors.New("service stopped without being stopped through ShutDB"))
+	w.show("Service crashed", fmt.Sprintf("%s stopped unexpectedly and will be restarted", event.Name))
+
+	w.running.Add(1)
+	go w.recover(event.Name, policy, stoppedAt)
+}
+
+// stopIntended reports whether a service that was seen stopping at stoppedAt was stopped on
+// purpose. Operations of this process are tracked in memory; those of other processes, such as
+// the shutdb command line, are only known from the audit log they share.
+func (w *crashWatchdog) stopIntended(name string, stoppedAt time.Time) bool {
+	sm := w.manager
+	if sm.operations.stopRequested(name, time.Since(stoppedAt)+watchdogRequestGrace) {
+		return true
+	}
+	if sm.auditLog == nil {
+		return false
+	}
+
+	history, err := sm.auditLog.GetHistory(AuditFilter{Service: name})
+	if err != nil {
+		log.Printf("Watchdog failed to read the audit log: %v", err)
+		return false
+	}
+	since := stoppedAt.Add(-watchdogRequestGrace)
+	for _, entry := range history {
+		// Entries are stamped with the start of the operation
+		finished := entry.Timestamp.Add(time.Duration(entry.DurationMs) * time.Millisecond)
+		if finished.Before(since) {
+			continue
+		}
+		if entry.Success && watchdogStopOperations[entry.Operation] {
+			return true
+		}
+	}
+	return false
+}
+
+// recover restarts a crashed service, retrying with backoff until it starts or the restart
+// limit of its policy is reached
+func (w *crashWatchdog) recover(name string, policy WatchdogPolicy, stoppedAt time.Time) {
+	defer w.running.Done()
+	defer func() {
+		w.mu.Lock()
+		delete(w.recovering, name)
+		w.mu.Unlock()
+	}()
+
+	sm := w.manager
+	ctx := sm.baseContext()
+	for {
+		previous := w.recentRestarts(name, policy.window())
+		if previous >= policy.maxRestarts() {
+			message := fmt.Sprintf("%s was restarted %d times within %s, giving up", name, previous, policy.window())
+			sm.control(InitiatorWatchdog).audit(AuditEntry{Operation: "give-up", Service: name}, time.Now(), errors.New(message))
+			w.show("Service keeps crashing", message)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.after(policy.backoff(previous)):
+		}
+
+		// Keep-alive may have been turned off, or the service started through ShutDB meanwhile.
+		// Another process stopping the service is only audited once its stop has finished.
+		if _, enabled := sm.configManager.GetWatchdogPolicy(name); !enabled || w.stopIntended(name, stoppedAt) {
+			return
+		}
+		if status, err := sm.adapter.GetServiceStatus(name); err == nil && status != StatusStopped {
+			return
+		}
+
+		w.mu.Lock()
+		w.restarts[name] = append(w.restarts[name], time.Now())
+		w.mu.Unlock()
+
+		err := sm.control(InitiatorWatchdog).StartService(name)
+		if err == nil {
+			w.show("Service restarted", fmt.Sprintf("%s was restarted after it crashed", name))
+			return
+		}
+		log.Printf("Watchdog failed to restart %s: %v", name, err)
+		w.show("Service restart failed", fmt.Sprintf("Failed to restart %s: %v", name, err))
+	}
+}
+
+// recentRestarts prunes restart attempts older than the window and returns how many remain
+func (w *crashWatchdog) recentRestarts(name string, window time.Duration) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cutoff := time.Now().Add(-window)
+	restarts := w.restarts[name]
+	for len(restarts) > 0 && restarts[0].Before(cutoff) {
+		restarts = restarts[1:]
+	}
+	w.restarts[name] = restarts
+	return len(restarts)
+}
+
+// show passes a message to the notifier, if one is set
+func (w *crashWatchdog) show(title, message string) {
+	w.mu.Lock()
+	notify := w.notify
+	w.mu.Unlock()
+
+	if notify != nil {
+		notify(title, message)
+	}
+}
@@ -0,0 +1,221 @@
+package app
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// createWatchdogManager returns a manager watching a running Elasticsearch service whose
+// restart delays are recorded instead of waited for
+func createWatchdogManager(t *testing.T, policy WatchdogPolicy) (*ServiceManager, *fakeServiceAdapter, *[]time.Duration, *[]string) {
+	adapter := newFakeServiceAdapter(OSService{Name: "elasticsearch-service-x64", Status: StatusRunning})
+	sm := createTestServiceManager(t, adapter)
+	if err := sm.configManager.SetWatchdogPolicy("elasticsearch-service-x64", policy); err != nil {
+		t.Fatalf("SetWatchdogPolicy() failed: %v", err)
+	}
+	sm.statusWatcher.SetServices([]Service{{Name: "elasticsearch-service-x64", Status: StatusRunning}})
+
+	var mu sync.Mutex
+	delays, notifications := &[]time.Duration{}, &[]string{}
+	sm.watchdog.after = func(delay time.Duration) <-chan time.Time {
+		mu.Lock()
+		*delays = append(*delays, delay)
+		mu.Unlock()
+		ready := make(chan time.Time, 1)
+		ready <- time.Now()
+		return ready
+	}
+	sm.watchdog.setNotifier(func(title, message string) {
+		mu.Lock()
+		*notifications = append(*notifications, title)
+		mu.Unlock()
+	})
+	return sm, adapter, delays, notifications
+}
+
+// crash stops a service behind ShutDB's back and waits for the watchdog to handle it
+func crash(sm *ServiceManager, adapter *fakeServiceAdapter, name string) {
+	adapter.setStatus(name, StatusStopped)
+	sm.statusWatcher.Poll()
+	sm.watchdog.running.Wait()
+}
+
+func TestWatchdogPolicyBackoff(t *testing.T) {
+	policy := WatchdogPolicy{KeepAlive: true, InitialBackoffSeconds: 10, MaxBackoffSeconds: 60}
+
+	expected := []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second, 60 * time.Second, 60 * time.Second}
+	for previous, want := range expected {
+		if got := policy.backoff(previous); got != want {
+			t.Errorf("backoff(%d) = %s, expected %s", previous, got, want)
+		}
+	}
+
+	if got := (WatchdogPolicy{}).backoff(0); got != defaultWatchdogInitialBackoff {
+		t.Errorf("Expected the default initial backoff, got %s", got)
+	}
+}
+
+func TestWatchdogRestartsCrashedService(t *testing.T) {
+	sm, adapter, delays, notifications := createWatchdogManager(t, WatchdogPolicy{KeepAlive: true})
+
+	crash(sm, adapter, "elasticsearch-service-x64")
+
+	if status, _ := adapter.GetServiceStatus("elasticsearch-service-x64"); status != StatusRunning {
+		t.Fatalf("Expected the crashed service to be restarted, got %s", status)
+	}
+	if len(*delays) != 1 || (*delays)[0] != defaultWatchdogInitialBackoff {
+		t.Errorf("Expected one restart after the initial backoff, got %v", *delays)
+	}
+	if strings.Join(*notifications, ",") != "Service crashed,Service restarted" {
+		t.Errorf("Unexpected notifications %v", *notifications)
+	}
+
+	history, err := sm.GetHistory(AuditFilter{Initiator: InitiatorWatchdog})
+	if err != nil {
+		t.Fatalf("GetHistory() failed: %v", err)
+	}
+	var operations []string
+	for _, entry := range history {
+		operations = append(operations, entry.Operation)
+	}
+	// Most recent first
+	if strings.Join(operations, ",") != "start,crash" {
+		t.Errorf("Expected the crash and the restart to be audited, got %v", operations)
+	}
+
+	// A second crash within the window waits twice as long
+	crash(sm, adapter, "elasticsearch-service-x64")
+	if len(*delays) != 2 || (*delays)[1] != 2*defaultWatchdogInitialBackoff {
+		t.Errorf("Expected the backoff to double, got %v", *delays)
+	}
+}
+
+func TestWatchdogIgnoresRequestedStop(t *testing.T) {
+	sm, adapter, delays, _ := createWatchdogManager(t, WatchdogPolicy{KeepAlive: true})
+
+	if err := sm.WaitForOperation(sm.StopService("elasticsearch-service-x64")); err != nil {
+		t.Fatalf("StopService() failed: %v", err)
+	}
+	sm.watchdog.running.Wait()
+
+	if status, _ := adapter.GetServiceStatus("elasticsearch-service-x64"); status != StatusStopped {
+		t.Errorf("Expected the service to stay stopped, got %s", status)
+	}
+	if len(*delays) != 0 {
+		t.Errorf("Expected no restart, got delays %v", *delays)
+	}
+}
+
+func TestWatchdogIgnoresStopAuditedByAnotherProcess(t *testing.T) {
+	sm, adapter, delays, notifications := createWatchdogManager(t, WatchdogPolicy{KeepAlive: true})
+
+	// The shutdb command line stops the service; only the shared audit log knows about it
+	if err := sm.auditLog.Append(AuditEntry{
+		Timestamp: time.Now(),
+		Operation: "stop",
+		Service:   "elasticsearch-service-x64",
+		Initiator: InitiatorCLI,
+		Success:   true,
+	}); err != nil {
+		t.Fatalf("Append() failed: %v", err)
+	}
+	crash(sm, adapter, "elasticsearch-service-x64")
+
+	if status, _ := adapter.GetServiceStatus("elasticsearch-service-x64"); status != StatusStopped {
+		t.Errorf("Expected the service to stay stopped, got %s", status)
+	}
+	if len(*delays) != 0 || len(*notifications) != 0 {
+		t.Errorf("Expected no restart, got delays %v and notifications %v", *delays, *notifications)
+	}
+}
+
+func TestWatchdogIgnoresStopAuditedDuringBackoff(t *testing.T) {
+	sm, adapter, _, _ := createWatchdogManager(t, WatchdogPolicy{KeepAlive: true})
+
+	// The command line records its stop only after the watchdog has seen the service stop
+	after := sm.watchdog.after
+	sm.watchdog.after = func(delay time.Duration) <-chan time.Time {
+		sm.auditLog.Append(AuditEntry{
+			Timestamp: time.Now(),
+			Operation: "stop",
+			Service:   "elasticsearch-service-x64",
+			Initiator: InitiatorCLI,
+			Success:   true,
+		})
+		return after(delay)
+	}
+	crash(sm, adapter, "elasticsearch-service-x64")
+
+	if status, _ := adapter.GetServiceStatus("elasticsearch-service-x64"); status != StatusStopped {
+		t.Errorf("Expected the service to stay stopped, got %s", status)
+	}
+	if calls := adapter.callLog(); strings.Contains(strings.Join(calls, ","), "start") {
+		t.Errorf("Expected no restart, got %v", calls)
+	}
+}
+
+func TestWatchdogRestartsServiceCrashingAfterDisable(t *testing.T) {
+	sm, adapter, delays, notifications := createWatchdogManager(t, WatchdogPolicy{KeepAlive: true})
+
+	// Disabling only changes the startup type; the service keeps running until it crashes
+	if err := sm.DisableService("elasticsearch-service-x64"); err != nil {
+		t.Fatalf("DisableService() failed: %v", err)
+	}
+	crash(sm, adapter, "elasticsearch-service-x64")
+
+	if len(*delays) != 1 {
+		t.Errorf("Expected the crash to be handled, got delays %v", *delays)
+	}
+	if len(*notifications) == 0 || (*notifications)[0] != "Service crashed" {
+		t.Errorf("Expected the crash to be shown, got %v", *notifications)
+	}
+	history, _ := sm.GetHistory(AuditFilter{Operation: "crash"})
+	if len(history) != 1 {
+		t.Errorf("Expected the crash to be audited, got %+v", history)
+	}
+}
+
+func TestWatchdogGivesUpAfterMaxRestarts(t *testing.T) {
+	sm, adapter, delays, notifications := createWatchdogManager(t, WatchdogPolicy{KeepAlive: true, MaxRestarts: 2})
+	adapter.failOn["start elasticsearch-service-x64"] = &ServiceError{Code: ErrSystemError, Message: "Out of memory"}
+
+	crash(sm, adapter, "elasticsearch-service-x64")
+
+	if calls := strings.Count(strings.Join(adapter.callLog(), ","), "start elasticsearch-service-x64"); calls != 2 {
+		t.Errorf("Expected 2 restart attempts, got %d", calls)
+	}
+	if len(*delays) != 2 || (*delays)[1] != 2*defaultWatchdogInitialBackoff {
+		t.Errorf("Expected two attempts with doubling backoff, got %v", *delays)
+	}
+	if last := (*notifications)[len(*notifications)-1]; last != "Service keeps crashing" {
+		t.Errorf("Expected the watchdog to report giving up, got %v", *notifications)
+	}
+
+	history, _ := sm.GetHistory(AuditFilter{Operation: "give-up"})
+	if len(history) != 1 || history[0].Initiator != InitiatorWatchdog {
+		t.Errorf("Expected giving up to be audited, got %+v", history)
+	}
+}
+
+func TestWatchdogIgnoresServicesWithoutKeepAlive(t *testing.T) {
+	sm, adapter, delays, notifications := createWatchdogManager(t, WatchdogPolicy{KeepAlive: false})
+
+	crash(sm, adapter, "elasticsearch-service-x64")
+
+	if len(*delays) != 0 || len(*notifications) != 0 {
+		t.Errorf("Expected no restart, got delays %v and notifications %v", *delays, *notifications)
+	}
+}
+
+func TestWatchdogPolicyValidation(t *testing.T) {
+	configManager, _ := createTestConfigManager(t)
+
+	if err := configManager.SetWatchdogPolicy("cassandra", WatchdogPolicy{KeepAlive: true, MaxRestarts: -1}); err == nil {
+		t.Error("Expected a negative restart limit to be rejected")
+	}
+	if _, enabled := configManager.GetWatchdogPolicy("cassandra"); enabled {
+		t.Error("A rejected policy must not be stored")
+	}
+}
@@ -0,0 +1,25 @@
+// Command shutdb controls the database services detected by ShutDB from the command line.
+//
+// It shares the configuration and service detection of the desktop app but does not
+// take the single instance lock, so it can be used while the GUI is running.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"service-db-dashboard/app"
+)
+
+func main() {
+	configManager, err := app.NewConfigManager()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "shutdb: failed to load configuration: %v\n", err)
+		os.Exit(app.ExitFailure)
+	}
+
+	serviceManager := app.NewServiceManager(configManager)
+
+	cli := app.NewCLI(serviceManager, os.Stdout, os.Stderr)
+	os.Exit(cli.Run(os.Args[1:]))
+}
@@ -1,14 +1,25 @@
-import { useState, useEffect, useMemo, useCallback } from "react";
+import { useState, useEffect, useMemo, useCallback, useRef } from "react";
 import {
   GetServices,
   StartService,
   StopService,
   RestartService,
+  PauseService,
+  ContinueService,
+  SetStartupType,
+  GetDependentsToStop,
+  WaitForOperation,
+  CancelOperation,
+  ForceStopService,
 } from "./wailsjs/go/app/ServiceManager";
+import { EventsOn } from "./wailsjs/runtime/runtime";
 import {
   Service,
   ServiceStatus,
+  ServiceStatusEvent,
+  StartupType,
   ErrorState,
+  ErrorCode,
 } from "./types/service";
 import { parseServiceError } from "./utils/errorHandler";
 import { useDebounce } from "./hooks/useDebounce";
@@ -51,6 +62,9 @@ function App() {
   
   // Service refresh optimization
   const [lastRefresh, setLastRefresh] = useState<number>(0);
+
+  // IDs of the running start/stop/restart operations by service name
+  const pendingOperations = useRef<Map<string, string>>(new Map());
   
 
 
@@ -102,6 +116,42 @@ function App() {
     }));
   };
 
+  // Apply status changes pushed by the backend, including changes made outside the app
+  useEffect(() => {
+    const unsubscribe = EventsOn("service:status", (event: ServiceStatusEvent) => {
+      updateServiceStatus(event.Name, event.NewStatus);
+    });
+    return unsubscribe;
+  }, []);
+
+  // Run a backend operation and wait for its outcome; it can be canceled meanwhile
+  const runOperation = async (
+    serviceName: string,
+    launch: (serviceName: string) => Promise<string>
+  ) => {
+    const operationId = await launch(serviceName);
+    pendingOperations.current.set(serviceName, operationId);
+    try {
+      await WaitForOperation(operationId);
+    } finally {
+      pendingOperations.current.delete(serviceName);
+    }
+  };
+
+  // Cancel the running operation of a service
+  const handleCancel = async (serviceName: string) => {
+    const operationId = pendingOperations.current.get(serviceName);
+    if (!operationId) {
+      return;
+    }
+    try {
+      await CancelOperation(operationId);
+    } catch (err) {
+      // The operation finished before it could be canceled
+      console.error(`Failed to cancel operation on ${serviceName}:`, err);
+    }
+  };
+
   // Handle start service
   const handleStart = async (serviceName: string) => {
     // Check if service is individually disabled
@@ -114,7 +164,7 @@ function App() {
       updateServiceStatus(serviceName, "starting");
 
       // Call backend
-      await StartService(serviceName);
+      await runOperation(serviceName, StartService);
 
       // Refresh service list after operation completes (forced refresh)
       await loadServices(true);
@@ -131,6 +181,22 @@ function App() {
     }
   };
 
+  // Ask before an operation that also stops running services depending on this one
+  const confirmDependents = async (serviceName: string, action: string) => {
+    try {
+      const dependents = await GetDependentsToStop(serviceName);
+      if (dependents.length === 0) {
+        return true;
+      }
+      return window.confirm(
+        `${action} ${serviceName} also stops the services that depend on it:\n\n${dependents.join("\n")}\n\nContinue?`
+      );
+    } catch {
+      // The operation itself reports the error
+      return true;
+    }
+  };
+
   // Handle stop service
   const handleStop = async (serviceName: string) => {
     // Check if service is individually disabled
@@ -138,18 +204,40 @@ function App() {
       return;
     }
 
+    if (!(await confirmDependents(serviceName, "Stopping"))) {
+      return;
+    }
+
     try {
       // Optimistic UI update
       updateServiceStatus(serviceName, "stopping");
 
       // Call backend
-      await StopService(serviceName);
+      await runOperation(serviceName, StopService);
 
       // Refresh service list after operation completes (forced refresh)
       await loadServices(true);
     } catch (err) {
       // Parse error and show user-friendly message
-      const errorState = parseServiceError(err, serviceName);
+      let errorState = parseServiceError(err, serviceName);
+
+      // A service that hangs while stopping can have its processes killed
+      if (
+        errorState.code === ErrorCode.ErrOperationTimeout &&
+        window.confirm(
+          `${serviceName} did not stop in time.\n\nForce stop it? Its processes are killed, which can lose unsaved data.`
+        )
+      ) {
+        try {
+          updateServiceStatus(serviceName, "stopping");
+          await ForceStopService(serviceName, true);
+          await loadServices(true);
+          return;
+        } catch (forceErr) {
+          errorState = parseServiceError(forceErr, serviceName);
+        }
+      }
+
       setState((prev) => ({
         ...prev,
         error: errorState,
@@ -167,12 +255,17 @@ function App() {
       return;
     }
 
+    // Dependents are started again after the restart
+    if (!(await confirmDependents(serviceName, "Restarting"))) {
+      return;
+    }
+
     try {
       // Optimistic UI update
       updateServiceStatus(serviceName, "restarting");
 
       // Call backend
-      await RestartService(serviceName);
+      await runOperation(serviceName, RestartService);
 
       // Refresh service list after operation completes (forced refresh)
       await loadServices(true);
@@ -189,6 +282,54 @@ function App() {
     }
   };
 
+  // Handle pause and continue, offered only for services that accept them
+  const handlePauseOrContinue = async (
+    serviceName: string,
+    transition: ServiceStatus,
+    operation: (name: string) => Promise<string>
+  ) => {
+    if (disabledServices.has(serviceName)) {
+      return;
+    }
+
+    try {
+      updateServiceStatus(serviceName, transition);
+      await runOperation(serviceName, operation);
+      await loadServices(true);
+    } catch (err) {
+      const errorState = parseServiceError(err, serviceName);
+      setState((prev) => ({
+        ...prev,
+        error: errorState,
+      }));
+      await loadServices(true);
+    }
+  };
+
+  const handlePause = (serviceName: string) =>
+    handlePauseOrContinue(serviceName, "pausing", PauseService);
+
+  const handleContinue = (serviceName: string) =>
+    handlePauseOrContinue(serviceName, "continuing", ContinueService);
+
+  // Handle startup type changes, e.g. Automatic (Delayed Start)
+  const handleSetStartupType = async (
+    serviceName: string,
+    startupType: StartupType
+  ) => {
+    try {
+      await SetStartupType(serviceName, startupType);
+      await loadServices(true);
+    } catch (err) {
+      const errorState = parseServiceError(err, serviceName);
+      setState((prev) => ({
+        ...prev,
+        error: errorState,
+      }));
+      await loadServices(true);
+    }
+  };
+
   // Handle stop all services
   const handleStopAll = async () => {
     setIsProcessing(true);
@@ -198,7 +339,7 @@ function App() {
       );
       for (const service of runningServices) {
         try {
-          await StopService(service.Name);
+          await runOperation(service.Name, StopService);
         } catch (err) {
           console.error(`Failed to stop ${service.Name}:`, err);
         }
@@ -231,7 +372,7 @@ function App() {
       );
       for (const service of stoppedServices) {
         try {
-          await StartService(service.Name);
+          await runOperation(service.Name, StartService);
         } catch (err) {
           console.error(`Failed to start ${service.Name}:`, err);
         }
@@ -331,6 +472,10 @@ function App() {
                 onStart={handleStart}
                 onStop={handleStop}
                 onRestart={handleRestart}
+                onCancel={handleCancel}
+                onPause={handlePause}
+                onContinue={handleContinue}
+                onSetStartupType={handleSetStartupType}
                 isDisabled={isTableDisabled}
                 disabledServices={disabledServices}
                 onToggleServiceDisabled={handleToggleServiceDisabled}
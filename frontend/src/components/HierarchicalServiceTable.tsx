@@ -1,5 +1,5 @@
 import React, { useState, useMemo } from "react";
-import { Service, ServiceType } from "../types/service";
+import { Service, ServiceType, StartupType } from "../types/service";
 import { ServiceTableRow } from "./ServiceTableRow";
 import { getServiceTypeInfo } from "../types/serviceTypeFilter";
 import { FluentIcons } from "./FluentIcons";
@@ -34,6 +34,10 @@ export interface HierarchicalServiceTableProps {
   onStart: (serviceName: string) => Promise<void>;
   onStop: (serviceName: string) => Promise<void>;
   onRestart: (serviceName: string) => Promise<void>;
+  onCancel?: (serviceName: string) => Promise<void>;
+  onPause?: (serviceName: string) => Promise<void>;
+  onContinue?: (serviceName: string) => Promise<void>;
+  onSetStartupType?: (serviceName: string, startupType: StartupType) => Promise<void>;
   isDisabled?: boolean;
   disabledServices?: Set<string>;
   onToggleServiceDisabled?: (serviceName: string) => void;
@@ -53,6 +57,10 @@ export const HierarchicalServiceTable: React.FC<HierarchicalServiceTableProps> =
   onStart,
   onStop,
   onRestart,
+  onCancel,
+  onPause,
+  onContinue,
+  onSetStartupType,
   isDisabled = false,
   disabledServices = new Set(),
   onToggleServiceDisabled,
@@ -234,6 +242,13 @@ export const HierarchicalServiceTable: React.FC<HierarchicalServiceTableProps> =
                         onStart={() => onStart(service.Name)}
                         onStop={() => onStop(service.Name)}
                         onRestart={() => onRestart(service.Name)}
+                        onCancel={onCancel && (() => onCancel(service.Name))}
+                        onPause={onPause && (() => onPause(service.Name))}
+                        onContinue={onContinue && (() => onContinue(service.Name))}
+                        onSetStartupType={
+                          onSetStartupType &&
+                          ((startupType) => onSetStartupType(service.Name, startupType))
+                        }
                         isDisabled={isDisabled}
                         isServiceDisabled={disabledServices.has(service.Name)}
                         onToggleServiceDisabled={onToggleServiceDisabled ? () => onToggleServiceDisabled(service.Name) : undefined}
@@ -1,5 +1,6 @@
 import { FC, useState, useEffect, useCallback } from 'react';
-import { GetServiceState, SetServiceState } from '../wailsjs/go/app/ConfigManager';
+import { GetServiceState } from '../wailsjs/go/app/ConfigManager';
+import { SetServiceControlState } from '../wailsjs/go/app/ServiceManager';
 import { Toast } from './Toast';
 import styles from './ServiceControl.module.css';
 
@@ -46,7 +47,7 @@ export const ServiceControl: FC<ServiceControlProps> = ({ className }) => {
 
     try {
       const newState = !serviceEnabled;
-      await SetServiceState(newState);
+      await SetServiceControlState(newState);
       setServiceEnabled(newState);
       
       // Show success toast
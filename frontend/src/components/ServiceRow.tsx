@@ -1,5 +1,5 @@
 import { useState } from 'react';
-import { Service, ServiceStatus } from '../types/service';
+import { Service, ServiceStatus, getSettableStartupTypes } from '../types/service';
 import { FluentIcons } from './FluentIcons';
 import styles from './ServiceRow.module.css';
 
@@ -31,13 +31,17 @@ export function ServiceRow({ service, onStart, onStop, onRestart, onDisable, onE
   const isTransitioning =
     service.Status === 'starting' ||
     service.Status === 'stopping' ||
-    service.Status === 'restarting';
+    service.Status === 'restarting' ||
+    service.Status === 'pausing' ||
+    service.Status === 'continuing';
 
   const isRunning = service.Status === 'running';
   const isStopped = service.Status === 'stopped';
 
   // Check if service is disabled based on StartupType
   const isDisabled = service.StartupType === 'disabled';
+  // Containers cannot be disabled, so they get no toggle
+  const canDisable = getSettableStartupTypes(service).includes('disabled');
 
   // Start service handler
   const handleStart = async () => {
@@ -95,6 +99,8 @@ export function ServiceRow({ service, onStart, onStop, onRestart, onDisable, onE
       case 'starting':
       case 'stopping':
       case 'restarting':
+      case 'pausing':
+      case 'continuing':
         return styles.statusTransitioning;
       default:
         return '';
@@ -111,6 +117,8 @@ export function ServiceRow({ service, onStart, onStop, onRestart, onDisable, onE
     switch (service.StartupType) {
       case 'automatic':
         return { text: 'Auto', className: styles.startupAuto };
+      case 'automatic_delayed':
+        return { text: 'Auto (Delayed)', className: styles.startupAuto };
       case 'manual':
         return { text: 'Manual', className: styles.startupManual };
       case 'disabled':
@@ -177,7 +185,7 @@ export function ServiceRow({ service, onStart, onStop, onRestart, onDisable, onE
         </button>
 
         {/* Toggle Enable/Disable Button */}
-        {(onDisable && onEnable) && (
+        {(onDisable && onEnable && canDisable) && (
           <button
             type="button"
             className={`${styles.button} ${styles.toggleButton} ${
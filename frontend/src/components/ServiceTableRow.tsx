@@ -1,5 +1,10 @@
 import React, { useState, useRef } from "react";
-import { Service, ErrorState } from "../types/service";
+import {
+  Service,
+  ErrorState,
+  StartupType,
+  getSettableStartupTypes,
+} from "../types/service";
 import { StatusBadge } from "./StatusBadge";
 import { FluentIcons } from "./FluentIcons";
 import { parseServiceError } from "../utils/errorHandler";
@@ -11,7 +16,11 @@ interface ServiceTableRowProps {
   onStart: () => Promise<void>;
   onStop: () => Promise<void>;
   onRestart: () => Promise<void>;
+  onCancel?: () => Promise<void>;
+  onPause?: () => Promise<void>;
+  onContinue?: () => Promise<void>;
   onToggleStartup?: () => Promise<void>;
+  onSetStartupType?: (startupType: StartupType) => Promise<void>;
   onError?: (error: ErrorState) => void;
   isDisabled?: boolean;
   isServiceDisabled?: boolean;
@@ -42,14 +51,18 @@ const ServiceTableRowComponent = ({
   onStart,
   onStop,
   onRestart,
+  onCancel,
+  onPause,
+  onContinue,
   onToggleStartup,
+  onSetStartupType,
   onError,
   isDisabled = false,
   isServiceDisabled = false,
   onToggleServiceDisabled,
 }: ServiceTableRowProps) => {
   const [operationState, setOperationState] = useState<
-    "idle" | "starting" | "stopping" | "restarting"
+    "idle" | "starting" | "stopping" | "restarting" | "pausing" | "continuing"
   >("idle");
   const [rowError, setRowError] = useState<string | null>(null);
   const [announceMessage, setAnnounceMessage] = useState<string>("");
@@ -101,6 +114,12 @@ const ServiceTableRowComponent = ({
     switch (startupType.toLowerCase()) {
       case "automatic":
         return "Automatic";
+      case "automatic_delayed":
+        return "Automatic (Delayed Start)";
+      case "boot":
+        return "Boot";
+      case "system":
+        return "System";
       case "manual":
         return "Manual";
       case "disabled":
@@ -222,6 +241,47 @@ const ServiceTableRowComponent = ({
     }
   };
 
+  // Pauses a running service or continues a paused one
+  const handlePauseOrContinue = async () => {
+    if (isPauseDisabled || !onPause || !onContinue) return;
+
+    const isContinue = service.Status === "paused";
+    lastFocusedButtonRef.current = document.activeElement as HTMLButtonElement;
+
+    setOperationState(isContinue ? "continuing" : "pausing");
+    setRowError(null);
+    setAnnounceMessage(
+      `${isContinue ? "Continuing" : "Pausing"} ${service.DisplayName}...`
+    );
+
+    try {
+      await (isContinue ? onContinue() : onPause());
+      setAnnounceMessage(
+        `${service.DisplayName} ${isContinue ? "continued" : "paused"} successfully`
+      );
+    } catch (error) {
+      const errorState = parseServiceError(error, service.Name);
+      setRowError(errorState.message);
+      setAnnounceMessage(
+        `Failed to ${isContinue ? "continue" : "pause"} ${service.DisplayName}: ${errorState.message}`
+      );
+      if (onError) {
+        onError(errorState);
+      }
+    } finally {
+      setOperationState("idle");
+      setTimeout(() => {
+        if (
+          lastFocusedButtonRef.current &&
+          document.contains(lastFocusedButtonRef.current)
+        ) {
+          lastFocusedButtonRef.current.focus();
+        }
+        setAnnounceMessage("");
+      }, 100);
+    }
+  };
+
   const handleToggleStartup = async () => {
     if (!onToggleStartup || operationState !== "idle") return;
 
@@ -246,6 +306,36 @@ const ServiceTableRowComponent = ({
     }
   };
 
+  // Only offer the startup types the service's backend can set
+  const settableStartupTypes = getSettableStartupTypes(service);
+
+  const handleSetStartupType = async (startupType: StartupType) => {
+    if (!onSetStartupType || startupType === service.StartupType) return;
+
+    setRowError(null);
+    setAnnounceMessage(
+      `Setting startup type of ${service.DisplayName} to ${getStartupTypeText(startupType)}...`
+    );
+
+    try {
+      await onSetStartupType(startupType);
+      setAnnounceMessage(
+        `Startup type of ${service.DisplayName} set to ${getStartupTypeText(startupType)}`
+      );
+    } catch (error) {
+      const errorState = parseServiceError(error, service.Name);
+      setRowError(errorState.message);
+      setAnnounceMessage(
+        `Failed to change startup type for ${service.DisplayName}: ${errorState.message}`
+      );
+      if (onError) {
+        onError(errorState);
+      }
+    } finally {
+      setTimeout(() => setAnnounceMessage(""), 3000);
+    }
+  };
+
   // Check if actions should be disabled
   const isOperationInProgress = operationState !== "idle";
   const isStartDisabled =
@@ -253,6 +343,7 @@ const ServiceTableRowComponent = ({
     isServiceDisabled ||
     service.Status === "running" ||
     service.Status === "starting" ||
+    service.Status === "paused" ||
     service.StartupType === "disabled" ||
     isOperationInProgress;
   const isStopDisabled =
@@ -268,10 +359,19 @@ const ServiceTableRowComponent = ({
     service.Status !== "running" ||
     service.StartupType === "disabled" ||
     isOperationInProgress;
+  // Pause and continue are only offered for services that accept them
+  const canPause = Boolean(service.CanPause && onPause && onContinue);
+  const isPauseDisabled =
+    isDisabled ||
+    isServiceDisabled ||
+    (service.Status !== "running" && service.Status !== "paused") ||
+    isOperationInProgress;
   const isTransitioning =
     service.Status === "starting" ||
     service.Status === "stopping" ||
     service.Status === "restarting" ||
+    service.Status === "pausing" ||
+    service.Status === "continuing" ||
     isOperationInProgress;
 
   // Keyboard navigation handler for action buttons
@@ -334,6 +434,10 @@ const ServiceTableRowComponent = ({
   const serviceId = `service-${service.Name.replace(/[^a-zA-Z0-9]/g, "-")}`;
   const statusId = `${serviceId}-status`;
   const actionsId = `${serviceId}-actions`;
+  // Listeners on IPv4 and IPv6 share a port, show it once
+  const tcpPorts = Array.from(
+    new Set((service.Ports ?? []).filter((port) => port.Protocol === "tcp").map((port) => port.Port))
+  );
 
   return (
     <>
@@ -358,7 +462,7 @@ const ServiceTableRowComponent = ({
         {/* Name Column */}
         <td className={styles.nameCell} role="cell" aria-describedby={statusId}>
           <div className={styles.nameContainer}>
-            <div className={styles.serviceIcon} aria-hidden="true">
+            <div className={styles.serviceIcon} aria-hidden="true" title={service.Match?.Description}>
               <FluentIcon name={getServiceIcon(service.Type)} />
             </div>
             <div className={styles.nameContent}>
@@ -367,6 +471,9 @@ const ServiceTableRowComponent = ({
               </div>
               <div className={styles.serviceName} aria-label="Service name">
                 {service.Name}
+                {service.Version && ` · v${service.Version}`}
+                {service.Instance?.Name && ` · ${service.Instance.Name}`}
+                {tcpPorts.length > 0 && ` · :${tcpPorts.join(", :")}`}
               </div>
             </div>
           </div>
@@ -387,14 +494,33 @@ const ServiceTableRowComponent = ({
 
         {/* Startup Type Column */}
         <td className={styles.startupTypeCell} role="cell">
-          <span
-            className={styles.startupTypeText}
-            aria-label={`Startup type: ${getStartupTypeText(
-              service.StartupType
-            )}`}
-          >
-            {getStartupTypeText(service.StartupType)}
-          </span>
+          {/* Boot and system startup belong to drivers and cannot be changed here */}
+          {onSetStartupType && settableStartupTypes.includes(service.StartupType) ? (
+            <select
+              className={styles.startupTypeSelect}
+              value={service.StartupType}
+              onChange={(e) =>
+                handleSetStartupType(e.target.value as StartupType)
+              }
+              disabled={isDisabled || isServiceDisabled || isOperationInProgress}
+              aria-label={`Startup type of ${service.DisplayName}`}
+            >
+              {settableStartupTypes.map((startupType) => (
+                <option key={startupType} value={startupType}>
+                  {getStartupTypeText(startupType)}
+                </option>
+              ))}
+            </select>
+          ) : (
+            <span
+              className={styles.startupTypeText}
+              aria-label={`Startup type: ${getStartupTypeText(
+                service.StartupType
+              )}`}
+            >
+              {getStartupTypeText(service.StartupType)}
+            </span>
+          )}
         </td>
 
         {/* Actions Column */}
@@ -597,6 +723,72 @@ const ServiceTableRowComponent = ({
               )}
             </button>
 
+            {/* Pause/Continue Button for services that accept pause requests */}
+            {canPause && (
+              <button
+                type="button"
+                className={`${styles.actionButton} ${styles.pauseButton} ${
+                  operationState === "pausing" || operationState === "continuing"
+                    ? styles.loading
+                    : ""
+                }`}
+                onClick={handlePauseOrContinue}
+                onKeyDown={(e) =>
+                  handleKeyDown(e, handlePauseOrContinue, isPauseDisabled)
+                }
+                disabled={isPauseDisabled}
+                title={
+                  service.Status === "paused" ? "Continue service" : "Pause service"
+                }
+                aria-label={`${
+                  service.Status === "paused" ? "Continue" : "Pause"
+                } ${service.DisplayName}${isPauseDisabled ? " (disabled)" : ""}`}
+                aria-describedby={serviceId}
+                tabIndex={isPauseDisabled ? -1 : 0}
+              >
+                <svg
+                  className={styles.buttonIcon}
+                  viewBox="0 0 24 24"
+                  fill="none"
+                  stroke="currentColor"
+                  strokeWidth="2"
+                >
+                  {service.Status === "paused" ? (
+                    <polygon points="5,3 19,12 5,21" />
+                  ) : (
+                    <>
+                      <rect x="6" y="5" width="4" height="14" />
+                      <rect x="14" y="5" width="4" height="14" />
+                    </>
+                  )}
+                </svg>
+              </button>
+            )}
+
+            {/* Cancel Button while an operation is running */}
+            {onCancel && isOperationInProgress && (
+              <button
+                type="button"
+                className={`${styles.actionButton} ${styles.cancelButton}`}
+                onClick={onCancel}
+                onKeyDown={(e) => handleKeyDown(e, onCancel, false)}
+                title="Cancel operation"
+                aria-label={`Cancel the running operation on ${service.DisplayName}`}
+                aria-describedby={serviceId}
+              >
+                <svg
+                  className={styles.buttonIcon}
+                  viewBox="0 0 24 24"
+                  fill="none"
+                  stroke="currentColor"
+                  strokeWidth="2"
+                >
+                  <path d="M18 6L6 18" />
+                  <path d="M6 6l12 12" />
+                </svg>
+              </button>
+            )}
+
             {/* Toggle Startup Button (if provided) */}
             {onToggleStartup && (
               <button
@@ -751,11 +943,17 @@ export const ServiceTableRow = React.memo(
       prevProps.service.DisplayName === nextProps.service.DisplayName &&
       prevProps.service.Type === nextProps.service.Type &&
       prevProps.service.StartupType === nextProps.service.StartupType &&
+      prevProps.service.CanPause === nextProps.service.CanPause &&
+      prevProps.service.StartupTypes?.join() === nextProps.service.StartupTypes?.join() &&
       prevProps.rowIndex === nextProps.rowIndex &&
       prevProps.onStart === nextProps.onStart &&
       prevProps.onStop === nextProps.onStop &&
       prevProps.onRestart === nextProps.onRestart &&
+      prevProps.onCancel === nextProps.onCancel &&
+      prevProps.onPause === nextProps.onPause &&
+      prevProps.onContinue === nextProps.onContinue &&
       prevProps.onToggleStartup === nextProps.onToggleStartup &&
+      prevProps.onSetStartupType === nextProps.onSetStartupType &&
       prevProps.onError === nextProps.onError
     );
   }
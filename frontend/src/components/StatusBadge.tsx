@@ -37,7 +37,9 @@ const StatusBadgeComponent: React.FC<StatusBadgeProps> = ({
     isTransitioning ||
     status === "starting" ||
     status === "stopping" ||
-    status === "restarting";
+    status === "restarting" ||
+    status === "pausing" ||
+    status === "continuing";
 
   // Get status variant for styling
   const getStatusVariant = (status: ExtendedServiceStatus): string => {
@@ -47,10 +49,13 @@ const StatusBadgeComponent: React.FC<StatusBadgeProps> = ({
       case "stopped":
         return "destructive";
       case "disabled":
+      case "paused":
         return "muted";
       case "starting":
       case "stopping":
       case "restarting":
+      case "pausing":
+      case "continuing":
         return "info";
       default:
         return "muted";
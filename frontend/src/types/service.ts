@@ -6,18 +6,36 @@ export type ServiceStatus =
   | "stopped"
   | "starting"
   | "stopping"
-  | "restarting";
+  | "restarting"
+  | "paused"
+  | "pausing"
+  | "continuing";
 
 /**
  * StartupType represents the service startup configuration
  */
 export type StartupType =
   | "automatic"
+  | "automatic_delayed"
   | "manual"
-  | "disabled";
+  | "disabled"
+  | "boot"
+  | "system";
 
 /**
- * ServiceType represents the type of database service
+ * SettableStartupTypes are the startup types SetStartupType accepts.
+ * Boot and system startup only apply to drivers and are reported, never set.
+ */
+export const SettableStartupTypes: StartupType[] = [
+  "automatic",
+  "automatic_delayed",
+  "manual",
+  "disabled",
+];
+
+/**
+ * ServiceType represents the type of database service.
+ * Detection rules may add custom types, e.g. "clickhouse".
  */
 export type ServiceType =
   | "postgresql"
@@ -36,7 +54,8 @@ export type ServiceType =
   | "memcached"
   | "sqlite"
   | "db2"
-  | "firebird";
+  | "firebird"
+  | (string & {});
 
 /**
  * ServiceCategory represents the category grouping for services in the UI
@@ -56,6 +75,70 @@ export type LogOnType =
   | "Network Service"
   | "Local Service";
 
+/**
+ * MatchExplanation describes why a service was classified as its type
+ */
+export interface MatchExplanation {
+  Type: ServiceType;
+  Source: 'builtin' | 'rule';
+  Rule?: string;
+  Field: 'name' | 'display_name' | 'executable' | 'image';
+  Value: string;
+  Pattern: string;
+  Kind: 'exact' | 'prefix' | 'token' | 'substring' | 'regex';
+  Priority: number;
+  Alternatives?: ServiceType[];
+  Description: string;
+}
+
+/**
+ * ServiceInstance identifies the product installation a service belongs to,
+ * e.g. the SQL Server instance SQLEXPRESS or the PostgreSQL 16 installation
+ */
+export interface ServiceInstance {
+  Product: string;
+  MajorVersion?: string;
+  Name?: string;
+  Architecture?: 'x64' | 'x86';
+  Component?: string;
+  ID?: string;
+}
+
+/**
+ * ListeningEndpoint is a local address a service's process listens on
+ */
+export interface ListeningEndpoint {
+  Protocol: 'tcp' | 'udp';
+  Address: string;
+  Port: number;
+  PID: number;
+}
+
+/**
+ * PortConflict is another process holding the port a service needs
+ */
+export interface PortConflict {
+  Protocol: 'tcp' | 'udp';
+  Address: string;
+  Port: number;
+  PID: number;
+  Process: string;
+}
+
+/**
+ * DependencyGraph holds the dependencies between detected services and their neighbours.
+ * An edge From -> To means From requires To.
+ */
+export interface DependencyGraph {
+  Nodes: {
+    Name: string;
+    DisplayName?: string;
+    Status: ServiceStatus;
+    Detected: boolean;
+  }[];
+  Edges: { From: string; To: string }[];
+}
+
 /**
  * Service represents a database service with its current state
  * Matches the backend Go Service struct with UI extensions
@@ -67,11 +150,34 @@ export interface Service {
   Type: ServiceType;
   StartupType: StartupType;
   Category: ServiceCategory;
+  Backend: string;
+  Version?: string;
+  Match?: MatchExplanation;
+  Instance?: ServiceInstance;
+  RelatedServices?: string[];
+  PID?: number;
+  Ports?: ListeningEndpoint[];
+  ExpectedPort?: number;
+  PublishedPort?: number;
+  // Whether the service accepts pause and continue requests
+  CanPause?: boolean;
+  // Startup types the service's backend can set, e.g. containers cannot be disabled
+  StartupTypes?: StartupType[];
   // Extended properties for table display
   logOnAs?: LogOnType;
   icon?: string;
 }
 
+/**
+ * ServiceStatusEvent is pushed by the backend on the "service:status" event
+ * whenever a service changes status
+ */
+export interface ServiceStatusEvent {
+  Name: string;
+  OldStatus: ServiceStatus;
+  NewStatus: ServiceStatus;
+}
+
 /**
  * ErrorCode represents specific error types for service operations
  */
@@ -81,6 +187,8 @@ export enum ErrorCode {
   ErrOperationTimeout = 2,
   ErrInvalidState = 3,
   ErrSystemError = 4,
+  ErrNotReady = 5,
+  ErrOperationCanceled = 6,
 }
 
 /**
@@ -167,6 +275,14 @@ export const getCategoryIcon = (category: ServiceCategory): string => {
   }
 };
 
+/**
+ * Gets the startup types that can be set on a service
+ * @param service The service to change
+ * @returns The startup types its backend supports, or all settable types if it did not report them
+ */
+export const getSettableStartupTypes = (service: Service): StartupType[] =>
+  service.StartupTypes ?? SettableStartupTypes;
+
 /**
  * Extends a basic service with UI-specific properties
  * @param service The basic service object from the backend
@@ -182,3 +298,106 @@ export const extendServiceForUI = (service: Service): Service => {
     icon
   };
 };
+
+/**
+ * A named stack of services controlled together
+ */
+export interface ServiceGroup {
+  name: string;
+  services?: string[];
+  tiers?: string[][];
+}
+
+/**
+ * Outcome of a group operation for a single member
+ */
+export interface GroupServiceResult {
+  Service: string;
+  Success: boolean;
+  Skipped: boolean;
+  Error?: string;
+  Code?: number;
+}
+
+/**
+ * Outcome of StartGroup, StopGroup or RestartGroup
+ */
+export interface GroupOperationResult {
+  Group: string;
+  Operation: string;
+  Success: boolean;
+  Results: GroupServiceResult[];
+}
+
+/**
+ * Where a control operation was requested
+ */
+export type Initiator = 'gui' | 'tray' | 'hotkey' | 'cli' | 'api' | 'watchdog' | 'idle' | 'proxy' | 'scheduler';
+
+/**
+ * A control operation recorded in the audit log
+ */
+export interface AuditEntry {
+  Timestamp: string;
+  Operation: string;
+  Service?: string;
+  Initiator: Initiator;
+  PriorStatus?: ServiceStatus;
+  ResultingStatus?: ServiceStatus;
+  DurationMs: number;
+  Success: boolean;
+  ErrorCode?: number;
+  Error?: string;
+}
+
+/**
+ * Filter for GetHistory; empty fields match everything
+ */
+export interface AuditFilter {
+  Service?: string;
+  Operation?: string;
+  Initiator?: Initiator;
+  Since?: string;
+  Until?: string;
+  Limit?: number;
+}
+
+/**
+ * Operation a schedule rule performs on its targets
+ */
+export type ScheduleAction = 'start' | 'stop' | 'restart' | 'enable' | 'disable';
+
+/**
+ * A timed operation on a service, or on every service of a type or category
+ */
+export interface ScheduleRule {
+  name: string;
+  cron: string;
+  action: ScheduleAction;
+  service?: string;
+  type?: ServiceType;
+  category?: ServiceCategory;
+  catch_up?: 'skip' | 'run_once';
+  catch_up_window_minutes?: number;
+  disabled?: boolean;
+}
+
+/**
+ * Outcome of the most recent due run of a schedule rule
+ */
+export interface ScheduleRun {
+  ScheduledAt: string;
+  Missed: boolean;
+  Skipped: boolean;
+  Success: boolean;
+  Results: GroupServiceResult[];
+}
+
+/**
+ * A schedule rule with its next and last run, as returned by ListSchedules
+ */
+export interface ScheduleInfo {
+  Rule: ScheduleRule;
+  NextRun?: string;
+  LastRun?: ScheduleRun;
+}
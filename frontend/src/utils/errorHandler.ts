@@ -39,6 +39,28 @@ export function parseServiceError(error: any, serviceName?: string): ErrorState
       };
     }
     
+    // Check for services that started but never accepted connections
+    if (errorMessage.includes('did not accept connections')) {
+      return {
+        message: serviceName
+          ? `Service '${serviceName}' is running but is not accepting connections yet.`
+          : 'The service is running but is not accepting connections yet.',
+        code: ErrorCode.ErrNotReady,
+        serviceName
+      };
+    }
+
+    // Check for operations canceled by the user
+    if (errorMessage.includes('was canceled')) {
+      return {
+        message: serviceName
+          ? `The operation on service '${serviceName}' was canceled.`
+          : 'The operation was canceled.',
+        code: ErrorCode.ErrOperationCanceled,
+        serviceName
+      };
+    }
+
     // Check for timeout errors
     if (errorMessage.includes('timeout') || 
         errorMessage.includes('timed out')) {
@@ -98,6 +120,12 @@ export function getErrorGuidance(errorState: ErrorState): string | null {
     
     case ErrorCode.ErrInvalidState:
       return 'Refresh the service list to see the current status.';
+
+    case ErrorCode.ErrNotReady:
+      return 'The database may still be recovering. Check its log, or verify the readiness address in the configuration.';
+
+    case ErrorCode.ErrOperationCanceled:
+      return 'The service may still finish changing state. Refresh the service list to see the current status.';
     
     default:
       return null;
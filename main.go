@@ -49,6 +49,8 @@ func main() {
 
 	trayManager := app.NewTrayManager(configManager, serviceManager, windowManager)
 
+	apiServer := app.NewAPIServer(serviceManager, configManager)
+
 	err = wails.Run(&options.App{
 		Title:             "ShutDB",
 		Width:             600,
@@ -105,6 +107,10 @@ func main() {
 				log.Printf("Warning: Failed to initialize hotkey manager: %v", err)
 			}
 
+			if err := apiServer.OnStartup(ctx); err != nil {
+				log.Printf("Warning: Failed to start local API: %v", err)
+			}
+
 			if configManager.GetStartMinimized() {
 				if err := trayManager.MinimizeToTray(); err != nil {
 					log.Printf("Warning: Failed to minimize to tray on startup: %v", err)
@@ -120,6 +126,9 @@ func main() {
 			log.Printf("Cleaning up system tray...")
 			trayManager.OnShutdown(ctx)
 
+			log.Printf("Stopping local API...")
+			apiServer.OnShutdown(ctx)
+
 			log.Printf("Saving configuration state...")
 			if err := configManager.OnShutdown(); err != nil {
 				log.Printf("Warning: Failed to save configuration during shutdown: %v", err)
@@ -141,6 +150,7 @@ func main() {
 			windowManager,
 			hotkeyManager,
 			trayManager,
+			apiServer,
 		},
 	})
 